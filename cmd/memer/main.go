@@ -78,13 +78,23 @@ func main() {
 	}
 
 	// Register commands
-	bot.RegisterCommands(b)
+	if err := bot.RegisterCommands(b); err != nil {
+		log.Fatal().Err(err).Msg("Failed to register commands")
+	}
+
+	// Start plugins, the bot runs without the ones that fail
+	if err := b.LoadPlugins(); err != nil {
+		log.Error().Err(err).Msg("Failed to load some plugins")
+	}
 
 	if err := b.Start(); err != nil {
 		log.Fatal().Err(err).Msg("Failed to start bot")
 	}
 
-	log.Info().Int("commands", b.Commands.Count()).Msg("Bot started successfully")
+	log.Info().
+		Int("commands", b.Commands.Count()).
+		Int("triggers", len(b.Commands.AliasTable())).
+		Msg("Bot started successfully")
 
 	// Wait for shutdown
 	b.Wait()
@@ -0,0 +1,203 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package bot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/database"
+)
+
+const (
+	analyticsBatchSize     = 200              // Invocations written per insert
+	analyticsFlushInterval = 10 * time.Second // Longest time an invocation stays buffered
+	analyticsMaxBuffered   = 20000            // Invocations kept while the database is down
+	analyticsPruneInterval = time.Hour        // How often old invocations are deleted
+)
+
+// usageStore is the part of the database the analytics writer needs
+type usageStore interface {
+	InsertCommandUsage(ctx context.Context, usage []database.CommandUsage) error
+	DeleteCommandUsageBefore(ctx context.Context, before time.Time) error
+}
+
+// analyticsWriter buffers command invocations and writes them in batches
+type analyticsWriter struct {
+	store     usageStore
+	logger    zerolog.Logger
+	salt      []byte
+	retention time.Duration
+
+	mu      sync.Mutex
+	buf     []database.CommandUsage
+	dropped int
+
+	flush chan struct{} // Signalled when a batch is full
+	done  chan struct{} // Closed once the last batch is written on shutdown
+}
+
+func newAnalyticsWriter(store usageStore, logger zerolog.Logger, salt string, retentionDays int) *analyticsWriter {
+	return &analyticsWriter{
+		store:     store,
+		logger:    logger,
+		salt:      []byte(salt),
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+		flush:     make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+}
+
+// hashUser pseudonymizes a user ID, so usage can be counted per user
+// without storing who ran what
+func (w *analyticsWriter) hashUser(userID string) string {
+	mac := hmac.New(sha256.New, w.salt)
+	mac.Write([]byte(userID))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// record buffers an invocation
+func (w *analyticsWriter) record(u database.CommandUsage) {
+	w.mu.Lock()
+	if len(w.buf) >= analyticsMaxBuffered {
+		w.dropped++
+		w.mu.Unlock()
+		return
+	}
+	w.buf = append(w.buf, u)
+	full := len(w.buf) >= analyticsBatchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run writes batches until ctx is cancelled, then writes what's left
+func (w *analyticsWriter) run(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(analyticsFlushInterval)
+	defer ticker.Stop()
+	prune := time.NewTicker(analyticsPruneInterval)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.write(ctx)
+		case <-w.flush:
+			w.write(ctx)
+		case <-prune.C:
+			if err := w.store.DeleteCommandUsageBefore(ctx, time.Now().Add(-w.retention)); err != nil {
+				w.logger.Error().Err(err).Msg("Failed to prune command usage")
+			}
+		case <-ctx.Done():
+			final, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			w.write(final)
+			cancel()
+			return
+		}
+	}
+}
+
+// write inserts the buffered invocations, a batch at a time. Batches that
+// fail are put back to be retried.
+func (w *analyticsWriter) write(ctx context.Context) {
+	w.mu.Lock()
+	pending := w.buf
+	w.buf = nil
+	dropped := w.dropped
+	w.dropped = 0
+	w.mu.Unlock()
+
+	if dropped > 0 {
+		w.logger.Warn().Int("dropped", dropped).Msg("Analytics buffer full, dropped invocations")
+	}
+
+	for len(pending) > 0 {
+		batch := pending[:min(len(pending), analyticsBatchSize)]
+		if err := w.store.InsertCommandUsage(ctx, batch); err != nil {
+			w.logger.Error().Err(err).Int("invocations", len(pending)).Msg("Failed to write command usage")
+			w.requeue(pending)
+			return
+		}
+		pending = pending[len(batch):]
+	}
+}
+
+// requeue puts invocations that couldn't be written back in front of the
+// buffer, as far as it has room
+func (w *analyticsWriter) requeue(usage []database.CommandUsage) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	room := analyticsMaxBuffered - len(w.buf)
+	if room <= 0 {
+		w.dropped += len(usage)
+		return
+	}
+	if len(usage) > room {
+		w.dropped += len(usage) - room
+		usage = usage[:room]
+	}
+	w.buf = append(append([]database.CommandUsage{}, usage...), w.buf...)
+}
+
+// recordUsage records every command that passed the checks, with how long
+// it took and whether it failed
+func recordUsage(w *analyticsWriter) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *commands.CommandContext, cmd commands.Command) (resp *commands.CommandResponse, err error) {
+			start := time.Now()
+			outcome := database.OutcomePanic
+
+			defer func() {
+				w.record(database.CommandUsage{
+					Command:   cmd.Props().Triggers[0],
+					GuildID:   ctx.Message.GuildID,
+					UserHash:  w.hashUser(ctx.Message.Author.ID),
+					Duration:  time.Since(start).Milliseconds(),
+					Outcome:   outcome,
+					CreatedAt: start,
+				})
+			}()
+
+			resp, err = next(ctx, cmd)
+			switch {
+			case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Context.Err(), context.DeadlineExceeded):
+				outcome = database.OutcomeTimeout
+			case err != nil:
+				outcome = database.OutcomeError
+			default:
+				outcome = database.OutcomeOK
+			}
+			return resp, err
+		}
+	}
+}
@@ -17,6 +17,7 @@
 package bot
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"regexp"
@@ -30,6 +31,7 @@ import (
 	"github.com/dankmemer/bot/internal/commands"
 	"github.com/dankmemer/bot/internal/database"
 	"github.com/dankmemer/bot/internal/external"
+	"github.com/dankmemer/bot/internal/plugin"
 	"github.com/dankmemer/bot/internal/utils"
 	"github.com/dankmemer/bot/internal/voice"
 )
@@ -46,13 +48,37 @@ type Bot struct {
 	RedditClient *external.RedditClient
 	VoiceManager *voice.Manager
 
+	// Dependencies handed to commands
+	Services *commands.Services
+
+	// Routes button and select menu clicks to the commands that sent them
+	Router *ComponentRouter
+
+	// Replies to recent command messages, edited when the message is
+	replies *replyTracker
+
+	// Executables providing commands, see LoadPlugins
+	plugins []*plugin.Supervisor
+
+	// Runs commands within the configured concurrency limits
+	executor *executor
+
+	// Records command usage, nil if analytics are disabled
+	analytics *analyticsWriter
+
 	// Runtime state
-	MentionRegex  *regexp.Regexp
-	RedditIndexes map[string]map[string]int // guildID -> command -> index
-	redditMu      sync.RWMutex
+	MentionRegex *regexp.Regexp
+	slashOnce    sync.Once
+
+	// Command pipeline, built from middleware on Start
+	middleware []Middleware
+	admission  CheckFunc // Checks run before a command is queued
+	pipeline   HandlerFunc
 
 	// Shutdown handling
 	shutdownChan chan struct{}
+	ctx          context.Context // Parent of every command's context, cancelled on shutdown
+	cancel       context.CancelFunc
 }
 
 func New(cfg *utils.Config) (*Bot, error) {
@@ -72,14 +98,19 @@ func New(cfg *utils.Config) (*Bot, error) {
 		return nil, err
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	bot := &Bot{
-		Session:       session,
-		Config:        cfg,
-		DB:            db,
-		Commands:      commands.NewRegistry(),
-		Logger:        logger,
-		RedditIndexes: make(map[string]map[string]int),
-		shutdownChan:  make(chan struct{}),
+		Session:      session,
+		Config:       cfg,
+		DB:           db,
+		Commands:     commands.NewRegistry(),
+		Router:       NewComponentRouter(),
+		replies:      newReplyTracker(),
+		Logger:       logger,
+		shutdownChan: make(chan struct{}),
+		ctx:          ctx,
+		cancel:       cancel,
 	}
 
 	// Initialize external clients
@@ -87,6 +118,22 @@ func New(cfg *utils.Config) (*Bot, error) {
 	bot.RedditClient = external.NewRedditClient(cfg.APIs.RedditURL)
 	bot.VoiceManager = voice.NewManager(session)
 
+	bot.executor = newExecutor(cfg.Executor, bot.executeCommand)
+
+	if cfg.Analytics.Enabled {
+		bot.analytics = newAnalyticsWriter(db, logger, cfg.Analytics.Salt, cfg.Analytics.RetentionDays)
+	}
+
+	bot.Services = &commands.Services{
+		DB:       db,
+		Config:   cfg,
+		Registry: bot.Commands,
+		ImageGen: bot.ImageGen,
+		Reddit:   bot.RedditClient,
+		Voice:    bot.VoiceManager,
+		Logger:   logger,
+	}
+
 	return bot, nil
 }
 
@@ -97,11 +144,17 @@ func (b *Bot) Start() error {
 	b.Session.Identify.Intents = discordgo.IntentsGuildMessages |
 		discordgo.IntentsGuilds |
 		discordgo.IntentsGuildVoiceStates |
+		discordgo.IntentsDirectMessages |
 		discordgo.IntentsMessageContent
 
+	b.admission = b.buildAdmission()
+	b.pipeline = b.buildPipeline()
+
 	// Register event handlers
 	b.Session.AddHandler(b.handleReady)
 	b.Session.AddHandler(b.handleMessageCreate)
+	b.Session.AddHandler(b.handleMessageUpdate)
+	b.Session.AddHandler(b.handleInteractionCreate)
 	b.Session.AddHandler(b.handleGuildCreate)
 	b.Session.AddHandler(b.handleGuildDelete)
 
@@ -112,6 +165,11 @@ func (b *Bot) Start() error {
 
 	b.Logger.Info().Msg("Bot connected to Discord")
 
+	go b.expireComponents()
+	if b.analytics != nil {
+		go b.analytics.run(b.ctx)
+	}
+
 	return nil
 }
 
@@ -133,6 +191,28 @@ func (b *Bot) Shutdown() error {
 
 	close(b.shutdownChan)
 
+	// Drop waiting commands, answering the slash commands among them that
+	// are already deferred
+	for _, j := range b.executor.stop() {
+		if j.ctx.Interaction != nil {
+			b.sendResponse(j.ctx, &commands.CommandResponse{Content: j.ctx.T("errors.shutdown")})
+		}
+	}
+
+	// Cancel running commands and let them return before the session and the
+	// database they use close
+	b.cancel()
+	if !b.executor.wait(shutdownTimeout) {
+		b.Logger.Warn().Msg("Commands still running after the shutdown timeout")
+	}
+
+	b.stopPlugins()
+
+	// Write the last analytics before the database closes
+	if b.analytics != nil {
+		<-b.analytics.done
+	}
+
 	if err := b.Session.Close(); err != nil {
 		b.Logger.Error().Err(err).Msg("Error closing Discord session")
 	}
@@ -155,6 +235,11 @@ func (b *Bot) handleReady(s *discordgo.Session, r *discordgo.Ready) {
 	// Compile mention regex
 	b.MentionRegex = regexp.MustCompile(`^<@!?` + r.User.ID + `>\s*`)
 
+	// Register slash commands once, Ready also fires on reconnects
+	b.slashOnce.Do(func() {
+		go b.registerApplicationCommands(s, r.User.ID)
+	})
+
 	// Set status
 	s.UpdateGameStatus(0, b.Config.DefaultPrefix+" help | "+b.Config.Version)
 
@@ -166,7 +251,7 @@ func (b *Bot) handleGuildCreate(s *discordgo.Session, g *discordgo.GuildCreate)
 	b.Logger.Debug().Str("guild", g.ID).Str("name", g.Name).Msg("Joined guild")
 
 	// Create guild config if not exists
-	_, err := b.DB.GetOrCreateGuild(g.ID, b.Config.DefaultPrefix)
+	_, err := b.DB.GetOrCreateGuild(b.ctx, g.ID, b.Config.DefaultPrefix)
 	if err != nil {
 		b.Logger.Error().Err(err).Str("guild", g.ID).Msg("Failed to create guild config")
 	}
@@ -199,39 +284,7 @@ func (b *Bot) updateStats() {
 		Shards:   1,
 	}
 
-	if err := b.DB.UpdateStats(stats); err != nil {
+	if err := b.DB.UpdateStats(b.ctx, stats); err != nil {
 		b.Logger.Error().Err(err).Msg("Failed to update stats")
 	}
 }
-
-// GetRedditIndex returns the current index for a Reddit command in a guild
-func (b *Bot) GetRedditIndex(guildID, command string) int {
-	b.redditMu.RLock()
-	defer b.redditMu.RUnlock()
-
-	if guildIndexes, ok := b.RedditIndexes[guildID]; ok {
-		if idx, ok := guildIndexes[command]; ok {
-			return idx
-		}
-	}
-	return 0
-}
-
-// IncrementRedditIndex increments and returns the new index for a Reddit command
-func (b *Bot) IncrementRedditIndex(guildID, command string, maxIndex int) int {
-	b.redditMu.Lock()
-	defer b.redditMu.Unlock()
-
-	if _, ok := b.RedditIndexes[guildID]; !ok {
-		b.RedditIndexes[guildID] = make(map[string]int)
-	}
-
-	idx := b.RedditIndexes[guildID][command]
-	idx++
-	if idx >= maxIndex {
-		idx = 0
-	}
-	b.RedditIndexes[guildID][command] = idx
-
-	return idx
-}
@@ -0,0 +1,396 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package bot
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/database"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// cooldownWriteTimeout bounds setting a cooldown after the command ran
+const cooldownWriteTimeout = 5 * time.Second
+
+// blockStore is the part of the database BlockedCheck needs
+type blockStore interface {
+	IsUserOrGuildBlocked(ctx context.Context, userID, guildID string) (bool, error)
+}
+
+// cooldownStore is the part of the database the cooldown middleware needs
+type cooldownStore interface {
+	IsOnCooldown(ctx context.Context, command, userID string) (int64, error)
+	ClaimCooldown(ctx context.Context, command, userID string, durationMs int64) (int64, error)
+	SetCooldown(ctx context.Context, command, userID string, durationMs int64) error
+	ClearCooldown(ctx context.Context, command, userID string) error
+}
+
+// ruleStore is the part of the database RulesCheck needs
+type ruleStore interface {
+	GetCommandRules(ctx context.Context, guildID string) ([]database.CommandRule, error)
+}
+
+// BlockedCheck silently ignores blocked users and guilds
+func BlockedCheck(db blockStore) CheckFunc {
+	return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, bool) {
+		blocked, _ := db.IsUserOrGuildBlocked(ctx.Context, ctx.Message.Author.ID, ctx.Message.GuildID)
+		return nil, !blocked
+	}
+}
+
+// PremiumCheck only lets premium guilds use a premium instance of the bot.
+// DMs aren't tied to a guild and are allowed.
+func PremiumCheck(cfg *utils.Config) CheckFunc {
+	return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, bool) {
+		if cfg.Premium && ctx.Message.GuildID != "" && !isPremiumGuild(cfg, ctx.Message.GuildID, ctx.GuildConfig) {
+			return &commands.CommandResponse{
+				Content: ctx.T("premium.required"),
+			}, false
+		}
+		return nil, true
+	}
+}
+
+// isPremiumGuild reports whether a guild is premium, through guilds.premium
+// or the config's premium guilds
+func isPremiumGuild(cfg *utils.Config, guildID string, guild *database.GuildConfig) bool {
+	return utils.Contains(cfg.PremiumGuilds, guildID) || (guild != nil && guild.Premium)
+}
+
+// OwnerOnlyCheck silently ignores developer-only commands for everyone else
+func OwnerOnlyCheck(cfg *utils.Config) CheckFunc {
+	return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, bool) {
+		return nil, !cmd.Props().OwnerOnly || utils.Contains(cfg.Devs, ctx.Message.Author.ID)
+	}
+}
+
+// GuildOnlyCheck refuses commands that aren't DMAllowed in DMs
+func GuildOnlyCheck() CheckFunc {
+	return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, bool) {
+		if resp := commands.CheckGuildOnly(ctx, cmd.Props()); resp != nil {
+			return resp, false
+		}
+		return nil, true
+	}
+}
+
+// DisabledCheck silently ignores commands the guild disabled, by name or
+// through their category
+func DisabledCheck() CheckFunc {
+	return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, bool) {
+		return nil, !commands.IsDisabled(ctx.GuildConfig, cmd.Props())
+	}
+}
+
+// RulesCheck silently ignores commands the guild's command rules deny in the
+// channel or for the user. Threads follow the rules of their parent channel.
+// Members with Manage Server aren't restricted, so they can always fix the
+// rules.
+func RulesCheck(db ruleStore, logger zerolog.Logger) CheckFunc {
+	return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, bool) {
+		if ctx.Message.GuildID == "" {
+			return nil, true
+		}
+
+		rules, err := db.GetCommandRules(ctx.Context, ctx.Message.GuildID)
+		if err != nil {
+			logger.Error().Err(err).Str("guild", ctx.Message.GuildID).Msg("Failed to get command rules")
+			return nil, true
+		}
+		if len(rules) == 0 {
+			return nil, true
+		}
+
+		subject := commands.RuleSubject{
+			GuildID:   ctx.Message.GuildID,
+			ChannelID: ctx.Message.ChannelID,
+			UserID:    ctx.Message.Author.ID,
+		}
+		if channel, err := ctx.Session.State.Channel(ctx.Message.ChannelID); err == nil && channel.IsThread() {
+			subject.ChannelID = channel.ParentID
+		}
+		if ctx.Message.Member != nil {
+			subject.Roles = ctx.Message.Member.Roles
+		}
+
+		if allowed, _ := commands.EvaluateRules(rules, cmd.Props(), subject); allowed {
+			return nil, true
+		}
+		return nil, commands.HasUserPermissions(ctx, discordgo.PermissionManageServer)
+	}
+}
+
+// DonatorCheck requires the invoking user to have the command's DonatorLevel
+func DonatorCheck() CheckFunc {
+	return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, bool) {
+		if resp := commands.CheckDonator(ctx, cmd.Props()); resp != nil {
+			return resp, false
+		}
+		return nil, true
+	}
+}
+
+// CooldownCheck stops users who are still on cooldown for a command
+func CooldownCheck(db cooldownStore) CheckFunc {
+	return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, bool) {
+		if !ownCooldown(ctx, cmd) {
+			return nil, true
+		}
+		props := cmd.Props()
+
+		remainingCD, _ := db.IsOnCooldown(ctx.Context, props.Triggers[0], ctx.Message.Author.ID)
+		if remainingCD <= 0 {
+			return nil, true
+		}
+
+		return commands.CooldownResponse(props, ctx.Command, ctx.Locale(), remainingCD), false
+	}
+}
+
+// ClaimCooldown starts the command's cooldown right before it runs.
+// CooldownCheck only looks at it when the command is queued, so this stops
+// copies queued behind the first one from running too.
+func ClaimCooldown(db cooldownStore, logger zerolog.Logger) CheckFunc {
+	return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, bool) {
+		if !ownCooldown(ctx, cmd) {
+			return nil, true
+		}
+		props := cmd.Props()
+
+		remaining, err := db.ClaimCooldown(ctx.Context, props.Triggers[0], ctx.Message.Author.ID, cooldownFor(ctx, props))
+		if err != nil {
+			logger.Error().Err(err).Str("command", props.Triggers[0]).Msg("Failed to claim cooldown")
+			return nil, true
+		}
+		if remaining > 0 {
+			return commands.CooldownResponse(props, ctx.Command, ctx.Locale(), remaining), false
+		}
+		return nil, true
+	}
+}
+
+// SetCooldown restarts the command's cooldown after it ran without error or
+// timed out, and gives it back if the command failed
+func SetCooldown(db cooldownStore, logger zerolog.Logger) HookFunc {
+	return func(ctx *commands.CommandContext, cmd commands.Command, resp *commands.CommandResponse, err error) {
+		if !ownCooldown(ctx, cmd) {
+			return
+		}
+
+		// The command's context is cancelled if it timed out, which must not
+		// skip its cooldown
+		setCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx.Context), cooldownWriteTimeout)
+		defer cancel()
+
+		props := cmd.Props()
+
+		// Timeouts count, so slow commands can't be spammed
+		timedOut := errors.Is(ctx.Context.Err(), context.DeadlineExceeded)
+		if err != nil && !timedOut {
+			if err := db.ClearCooldown(setCtx, props.Triggers[0], ctx.Message.Author.ID); err != nil {
+				logger.Error().Err(err).Str("command", props.Triggers[0]).Msg("Failed to clear cooldown")
+			}
+			return
+		}
+
+		if err := db.SetCooldown(setCtx, props.Triggers[0], ctx.Message.Author.ID, cooldownFor(ctx, props)); err != nil {
+			logger.Error().Err(err).Str("command", props.Triggers[0]).Msg("Failed to set cooldown")
+		}
+	}
+}
+
+// ownCooldown reports whether a command's cooldown applies to this use. A
+// group leaves it to the subcommand it dispatches to, which has its own.
+func ownCooldown(ctx *commands.CommandContext, cmd commands.Command) bool {
+	g, ok := cmd.(*commands.Group)
+	return !ok || g.Subcommand(ctx.Args) == nil
+}
+
+// cooldownFor returns the command's cooldown in ms, shortened by the user's
+// donator tier
+func cooldownFor(ctx *commands.CommandContext, props commands.CommandProps) int64 {
+	cooldown := props.Cooldown
+	if cooldown == 0 {
+		cooldown = 3000
+	}
+	return commands.ScaleCooldown(ctx.Services.Config, cooldown, ctx.DonatorLevel())
+}
+
+// UserPermissionsCheck requires the invoking user to have the command's
+// UserPermissions
+func UserPermissionsCheck() CheckFunc {
+	return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, bool) {
+		if resp := commands.CheckUserPermissions(ctx, cmd.Props()); resp != nil {
+			return resp, false
+		}
+		return nil, true
+	}
+}
+
+// BotPermissionsCheck requires the bot to have the command's Permissions in
+// the channel. DM channels have no permission overwrites, so they always pass.
+func BotPermissionsCheck() CheckFunc {
+	return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, bool) {
+		if resp := commands.CheckBotPermissions(ctx, cmd.Props()); resp != nil {
+			return resp, false
+		}
+		return nil, true
+	}
+}
+
+// NSFWChannelCheck only allows NSFW commands in NSFW channels
+func NSFWChannelCheck() CheckFunc {
+	return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, bool) {
+		if resp := commands.CheckNSFW(ctx, cmd.Props()); resp != nil {
+			return resp, false
+		}
+		return nil, true
+	}
+}
+
+// ParseArgs parses arguments against the command's schema, replying with
+// the parse error instead of running the command
+func ParseArgs() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, error) {
+			if err := commands.ParseArgs(ctx, cmd.Props()); err != nil {
+				return &commands.CommandResponse{Content: err.Error()}, nil
+			}
+			return next(ctx, cmd)
+		}
+	}
+}
+
+// Defer sends the command's placeholder or starts typing before it runs
+func Defer(logger zerolog.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, error) {
+			props := cmd.Props()
+			if props.Placeholder != "" {
+				if err := ctx.Defer(props.Placeholder); err != nil {
+					logger.Error().Err(err).Str("command", props.Triggers[0]).Msg("Failed to send placeholder")
+				}
+			} else if props.Typing {
+				ctx.Typing()
+			}
+			return next(ctx, cmd)
+		}
+	}
+}
+
+// LogCommand logs every invocation, its duration and any error
+func LogCommand(logger zerolog.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, error) {
+			props := cmd.Props()
+
+			logger.Debug().
+				Str("command", props.Triggers[0]).
+				Str("user", ctx.Message.Author.ID).
+				Str("guild", ctx.Message.GuildID).
+				Strs("args", ctx.Args).
+				Msg("Executing command")
+
+			start := time.Now()
+			resp, err := next(ctx, cmd)
+			duration := time.Since(start)
+
+			if err != nil {
+				logger.Error().
+					Err(err).
+					Str("command", props.Triggers[0]).
+					Strs("args", ctx.Args).
+					Dur("duration", duration).
+					Msg("Command error")
+				return resp, err
+			}
+
+			logger.Debug().
+				Str("command", props.Triggers[0]).
+				Dur("duration", duration).
+				Msg("Command completed")
+			return resp, nil
+		}
+	}
+}
+
+// Timeout cancels the command's context after its Timeout and replies with
+// a timeout message if the command hasn't finished by then
+func Timeout() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, error) {
+			timeout := cmd.Props().Timeout
+			if timeout <= 0 {
+				timeout = commands.DefaultTimeout
+			}
+
+			cmdCtx, cancel := context.WithTimeout(ctx.Context, time.Duration(timeout)*time.Millisecond)
+			defer cancel()
+			ctx.Context = cmdCtx
+
+			type result struct {
+				resp  *commands.CommandResponse
+				err   error
+				panic interface{}
+			}
+			done := make(chan result, 1)
+
+			// The command may outlive the timeout, keep its executor slot
+			// until it actually returns
+			release := holdSlot(cmdCtx)
+			go func() {
+				defer release()
+				defer func() {
+					if r := recover(); r != nil {
+						done <- result{panic: r}
+					}
+				}()
+				resp, err := next(ctx, cmd)
+				done <- result{resp: resp, err: err}
+			}()
+
+			select {
+			case res := <-done:
+				if res.panic != nil {
+					// Let the caller's recover handle it
+					panic(res.panic)
+				}
+				if res.err != nil && errors.Is(res.err, context.DeadlineExceeded) {
+					return timedOutResponse(ctx), nil
+				}
+				return res.resp, res.err
+
+			case <-cmdCtx.Done():
+				if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+					return timedOutResponse(ctx), nil
+				}
+				// The bot is shutting down
+				return nil, nil
+			}
+		}
+	}
+}
+
+func timedOutResponse(ctx *commands.CommandContext) *commands.CommandResponse {
+	return &commands.CommandResponse{Content: ctx.T("errors.timeout")}
+}
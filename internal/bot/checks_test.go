@@ -0,0 +1,318 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package bot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// fakeCooldowns records the cooldowns set, failing on cancelled contexts like
+// the database does
+type fakeCooldowns struct {
+	mu    sync.Mutex
+	set   map[string]int64
+	until map[string]time.Time
+}
+
+func newFakeCooldowns() *fakeCooldowns {
+	return &fakeCooldowns{set: map[string]int64{}, until: map[string]time.Time{}}
+}
+
+func (f *fakeCooldowns) IsOnCooldown(ctx context.Context, command, userID string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return max(time.Until(f.until[command]).Milliseconds(), 0), nil
+}
+
+func (f *fakeCooldowns) ClaimCooldown(ctx context.Context, command, userID string, durationMs int64) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if remaining := time.Until(f.until[command]).Milliseconds(); remaining > 0 {
+		return remaining, nil
+	}
+	f.until[command] = time.Now().Add(time.Duration(durationMs) * time.Millisecond)
+	return 0, nil
+}
+
+func (f *fakeCooldowns) SetCooldown(ctx context.Context, command, userID string, durationMs int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.set[command] = durationMs
+	f.until[command] = time.Now().Add(time.Duration(durationMs) * time.Millisecond)
+	return nil
+}
+
+func (f *fakeCooldowns) ClearCooldown(ctx context.Context, command, userID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.set, command)
+	delete(f.until, command)
+	return nil
+}
+
+func (f *fakeCooldowns) get(command string) (int64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d, ok := f.set[command]
+	return d, ok
+}
+
+func TestSetCooldown(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     func(ctx *commands.CommandContext) (*commands.CommandResponse, error)
+		want    bool
+		donator int
+	}{
+		{"success", func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+			return nil, nil
+		}, true, 0},
+		{"error", func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+			return nil, errors.New("imgen is down")
+		}, false, 0},
+		{"timeout", func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+			<-ctx.Context.Done()
+			return nil, ctx.Context.Err()
+		}, true, 0},
+		{"timeout as donator", func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+			<-ctx.Context.Done()
+			return nil, errors.New("request failed")
+		}, true, 2},
+	}
+
+	for _, tt := range tests {
+		h := commandtest.New()
+		if tt.donator > 0 {
+			h.Store.SetDonator(context.Background(), commandtest.AuthorID, tt.donator, time.Time{})
+		}
+		store := newFakeCooldowns()
+		cmd := &commands.BaseCommand{
+			Properties: commands.CommandProps{Triggers: []string{"slow"}, Timeout: 10, Cooldown: 10000},
+			Handler:    tt.run,
+		}
+
+		handler := Chain(runCommand, Timeout(), After(SetCooldown(store, zerolog.Nop())))
+		if _, err := handler(h.Context("slow"), cmd); err != nil && tt.want {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if !tt.want {
+			if _, ok := store.get("slow"); ok {
+				t.Errorf("%s: cooldown set", tt.name)
+			}
+			continue
+		}
+
+		want := int64(10000)
+		if tier := h.Config.DonatorTier(tt.donator); tier != nil {
+			want = int64(float64(want) * tier.CooldownMultiplier)
+		}
+		waitFor(t, tt.name+" cooldown", func() bool { _, ok := store.get("slow"); return ok })
+		if got, _ := store.get("slow"); got != want {
+			t.Errorf("%s: cooldown %d, want %d", tt.name, got, want)
+		}
+	}
+}
+
+func TestClaimCooldownRunsQueuedCopiesOnce(t *testing.T) {
+	h := commandtest.New()
+	store := newFakeCooldowns()
+
+	var mu sync.Mutex
+	runs := 0
+	cmd := &commands.BaseCommand{
+		Properties: commands.CommandProps{Triggers: []string{"daily"}, Cooldown: 10000},
+		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			runs++
+			return nil, nil
+		},
+	}
+	handler := Chain(runCommand, Before(ClaimCooldown(store, zerolog.Nop())), After(SetCooldown(store, zerolog.Nop())))
+
+	// Every copy passed admission before any of them ran
+	var wg sync.WaitGroup
+	for range 5 {
+		ctx := h.Context("daily")
+		if resp, ok := CooldownCheck(store)(ctx, cmd); !ok {
+			t.Fatalf("admission refused: %v", resp)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler(ctx, cmd)
+		}()
+	}
+	wg.Wait()
+
+	if runs != 1 {
+		t.Errorf("ran %d times, want once", runs)
+	}
+}
+
+func TestFailedCommandGivesCooldownBack(t *testing.T) {
+	h := commandtest.New()
+	store := newFakeCooldowns()
+	cmd := &commands.BaseCommand{
+		Properties: commands.CommandProps{Triggers: []string{"meme"}, Cooldown: 10000},
+		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+			return nil, errors.New("reddit is down")
+		},
+	}
+	handler := Chain(runCommand, Before(ClaimCooldown(store, zerolog.Nop())), After(SetCooldown(store, zerolog.Nop())))
+
+	handler(h.Context("meme"), cmd)
+	if remaining, _ := store.IsOnCooldown(context.Background(), "meme", commandtest.AuthorID); remaining > 0 {
+		t.Errorf("on cooldown for %dms after the command failed", remaining)
+	}
+}
+
+func TestGroupCooldownSkippedForSubcommands(t *testing.T) {
+	h := commandtest.New()
+	store := newFakeCooldowns()
+	group := &commands.Group{
+		Properties: commands.CommandProps{Triggers: []string{"config"}},
+		Subcommands: []commands.Command{
+			&commands.BaseCommand{Properties: commands.CommandProps{Triggers: []string{"show"}}, Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+				return nil, nil
+			}},
+		},
+	}
+	handler := Chain(runCommand, Before(ClaimCooldown(store, zerolog.Nop())), After(SetCooldown(store, zerolog.Nop())))
+
+	handler(h.Context("config show"), group)
+	if _, ok := store.get("config"); ok {
+		t.Error("group cooldown set for a subcommand")
+	}
+
+	handler(h.Context("config"), group)
+	if _, ok := store.get("config"); !ok {
+		t.Error("group cooldown not set for its help")
+	}
+}
+
+func TestChecksInDMs(t *testing.T) {
+	cfg := &utils.Config{DefaultPrefix: "pls", Premium: true}
+	guildOnly := &commands.BaseCommand{Properties: commands.CommandProps{
+		Triggers: []string{"rob"},
+	}}
+	anywhere := &commands.BaseCommand{Properties: commands.CommandProps{
+		Triggers:    []string{"ping"},
+		DMAllowed:   true,
+		Permissions: []int64{discordgo.PermissionEmbedLinks},
+	}}
+	manager := &commands.BaseCommand{Properties: commands.CommandProps{
+		Triggers:        []string{"prefix"},
+		DMAllowed:       true,
+		UserPermissions: discordgo.PermissionManageGuild,
+	}}
+
+	tests := []struct {
+		name  string
+		check CheckFunc
+		cmd   commands.Command
+		dm    bool
+		want  bool
+	}{
+		{"guild only in a guild", GuildOnlyCheck(), guildOnly, false, true},
+		{"guild only in a DM", GuildOnlyCheck(), guildOnly, true, false},
+		{"DM allowed in a DM", GuildOnlyCheck(), anywhere, true, true},
+		{"premium in a guild", PremiumCheck(cfg), anywhere, false, false},
+		{"premium in a DM", PremiumCheck(cfg), anywhere, true, true},
+		{"bot permissions in a DM", BotPermissionsCheck(), anywhere, true, true},
+		{"user permissions in a DM", UserPermissionsCheck(), manager, true, false},
+	}
+
+	for _, tt := range tests {
+		h := commandtest.New()
+		h.DM = tt.dm
+		resp, ok := tt.check(h.Context(tt.cmd.Props().Triggers[0]), tt.cmd)
+		if ok != tt.want {
+			t.Errorf("%s: allowed = %v, want %v", tt.name, ok, tt.want)
+		}
+		if !ok && resp == nil {
+			t.Errorf("%s: refused without a reply", tt.name)
+		}
+	}
+}
+
+func TestDMConfig(t *testing.T) {
+	b := &Bot{Config: &utils.Config{DefaultPrefix: "pls"}}
+	cfg := b.dmConfig()
+
+	if cfg.Prefix != "pls" || len(cfg.Prefixes) != 1 || cfg.Prefixes[0] != "pls" {
+		t.Errorf("prefixes = %q %v, want only the default prefix", cfg.Prefix, cfg.Prefixes)
+	}
+	if !cfg.Suggestions {
+		t.Error("suggestions off in DMs")
+	}
+
+	// Changes to one DM's config don't leak into the defaults
+	cfg.Prefixes[0] = "changed"
+	if b.dmConfig().Prefixes[0] != "pls" || defaultGuildConfig.Prefixes[0] != "pls" {
+		t.Error("DM config shares its prefixes")
+	}
+}
+
+func TestClaimCooldownScalesForDonators(t *testing.T) {
+	for level, want := range map[int]int64{0: 10000, 2: 5000} {
+		h := commandtest.New()
+		if level > 0 {
+			h.Store.SetDonator(context.Background(), commandtest.AuthorID, level, time.Time{})
+		}
+		store := newFakeCooldowns()
+		cmd := &commands.BaseCommand{Properties: commands.CommandProps{Triggers: []string{"meme"}, Cooldown: 10000}}
+
+		if resp, ok := ClaimCooldown(store, zerolog.Nop())(h.Context("meme"), cmd); !ok {
+			t.Fatalf("level %d: refused: %v", level, resp)
+		}
+		remaining, _ := store.IsOnCooldown(context.Background(), "meme", commandtest.AuthorID)
+		if remaining > want || remaining < want-1000 {
+			t.Errorf("level %d: on cooldown for %dms, want %dms", level, remaining, want)
+		}
+	}
+}
+
+func TestPremiumGuildFromDatabase(t *testing.T) {
+	cfg := &utils.Config{Premium: true}
+	cmd := &commands.BaseCommand{Properties: commands.CommandProps{Triggers: []string{"meme"}}}
+
+	h := commandtest.New()
+	if _, ok := PremiumCheck(cfg)(h.Context("meme"), cmd); ok {
+		t.Error("allowed in a guild that isn't premium")
+	}
+
+	h.Store.UpdateGuildPremium(context.Background(), commandtest.GuildID, true)
+	if _, ok := PremiumCheck(cfg)(h.Context("meme"), cmd); !ok {
+		t.Error("refused in a guild premium through the database")
+	}
+}
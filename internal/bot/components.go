@@ -0,0 +1,344 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package bot
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/i18n"
+)
+
+// ComponentRouter maps the custom IDs of buttons and select menus back to the
+// response that sent them. Custom IDs are sent to Discord as "<state>:<action>".
+type ComponentRouter struct {
+	mu     sync.Mutex
+	states map[string]*componentState
+}
+
+// componentState is a response whose components are still in use
+type componentState struct {
+	run sync.Mutex // Serializes handlers of the same message
+
+	id     string
+	origin *commands.CommandContext
+
+	mu         sync.Mutex // Guards the fields below
+	components *commands.Components
+	rows       []discordgo.MessageComponent // Rows as sent, with prefixed custom IDs
+	expires    time.Time
+
+	channelID string
+	messageID string
+}
+
+// NewComponentRouter creates an empty router
+func NewComponentRouter() *ComponentRouter {
+	return &ComponentRouter{states: make(map[string]*componentState)}
+}
+
+// Count returns the number of messages with live components
+func (r *ComponentRouter) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.states)
+}
+
+// track starts routing the components of a response. It returns the rows to
+// send, which are the response's rows as is if there is no handler.
+func (r *ComponentRouter) track(ctx *commands.CommandContext, resp *commands.CommandResponse) (*componentState, []discordgo.MessageComponent) {
+	if resp == nil || resp.Components == nil {
+		return nil, nil
+	}
+	if resp.Components.Handler == nil {
+		return nil, resp.Components.Rows
+	}
+
+	state := &componentState{
+		id:     strconv.FormatUint(rand.Uint64(), 36),
+		origin: ctx,
+	}
+	rows := state.update(resp.Components)
+
+	r.mu.Lock()
+	r.states[state.id] = state
+	r.mu.Unlock()
+
+	return state, rows
+}
+
+// get returns the state a custom ID belongs to and the action it stands for
+func (r *ComponentRouter) get(customID string) (*componentState, string) {
+	id, action, ok := strings.Cut(customID, ":")
+	if !ok {
+		return nil, ""
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.states[id], action
+}
+
+func (r *ComponentRouter) forget(state *componentState) {
+	r.mu.Lock()
+	delete(r.states, state.id)
+	r.mu.Unlock()
+}
+
+// forgetMessage stops routing the components of a message, which is about to
+// be replaced
+func (r *ComponentRouter) forgetMessage(messageID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, state := range r.states {
+		state.mu.Lock()
+		sentWith := state.messageID
+		state.mu.Unlock()
+		if sentWith == messageID {
+			delete(r.states, id)
+		}
+	}
+}
+
+// expired removes and returns the states that have timed out
+func (r *ComponentRouter) expired(now time.Time) []*componentState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired []*componentState
+	for id, state := range r.states {
+		if state.expiredAt(now) {
+			expired = append(expired, state)
+			delete(r.states, id)
+		}
+	}
+	return expired
+}
+
+// update replaces the components of a state and restarts its timeout. It
+// returns the rows to send.
+func (s *componentState) update(c *commands.Components) []discordgo.MessageComponent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = commands.DefaultComponentTimeout
+	}
+
+	s.components = c
+	s.expires = time.Now().Add(time.Duration(timeout) * time.Millisecond)
+	s.rows = commands.MapComponents(c.Rows, func(comp discordgo.MessageComponent) discordgo.MessageComponent {
+		switch comp := comp.(type) {
+		case discordgo.Button:
+			if comp.CustomID != "" {
+				comp.CustomID = s.id + ":" + comp.CustomID
+			}
+			return comp
+		case discordgo.SelectMenu:
+			comp.CustomID = s.id + ":" + comp.CustomID
+			return comp
+		}
+		return comp
+	})
+	return s.rows
+}
+
+func (s *componentState) expiredAt(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return now.After(s.expires)
+}
+
+// allowed reports whether a user may use the state's components
+func (s *componentState) allowed(userID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.components.Public {
+		return true
+	}
+	users := s.components.Users
+	if len(users) == 0 {
+		users = []string{s.origin.Message.Author.ID}
+	}
+	for _, id := range users {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// sent records the message the components were sent with
+func (s *componentState) sent(msg *discordgo.Message) {
+	s.mu.Lock()
+	s.channelID = msg.ChannelID
+	s.messageID = msg.ID
+	s.mu.Unlock()
+}
+
+// handleComponent runs the handler of the response a component belongs to
+func (b *Bot) handleComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	user := i.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+	if user == nil {
+		return
+	}
+
+	data := i.MessageComponentData()
+	state, action := b.Router.get(data.CustomID)
+	if state == nil || state.expiredAt(time.Now()) {
+		b.respondEphemeral(s, i.Interaction, i18n.T(b.guildLocale(i.GuildID), "components.expired"))
+		return
+	}
+	if !state.allowed(user.ID) {
+		b.respondEphemeral(s, i.Interaction, state.origin.T("components.not_yours"))
+		return
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+	if err != nil {
+		b.Logger.Error().Err(err).Msg("Failed to acknowledge component interaction")
+		return
+	}
+
+	go b.runComponent(s, i.Interaction, state, user, action, data.Values)
+}
+
+func (b *Bot) runComponent(s *discordgo.Session, i *discordgo.Interaction, state *componentState, user *discordgo.User, action string, values []string) {
+	state.run.Lock()
+	defer state.run.Unlock()
+
+	state.mu.Lock()
+	handler := state.components.Handler
+	state.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(b.ctx, commands.DefaultTimeout*time.Millisecond)
+	defer cancel()
+
+	resp, err := b.callComponentHandler(handler, &commands.ComponentContext{
+		Context:     ctx,
+		Session:     s,
+		Interaction: i,
+		User:        user,
+		Action:      action,
+		Values:      values,
+		Origin:      state.origin,
+	})
+	if err != nil {
+		b.Logger.Error().Err(err).Str("command", state.origin.Command).Str("action", action).Msg("Component handler failed")
+		_, err = s.FollowupMessageCreate(i, false, &discordgo.WebhookParams{
+			Content: state.origin.T("errors.generic", err.Error()),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		if err != nil {
+			b.Logger.Error().Err(err).Msg("Failed to send component error")
+		}
+		return
+	}
+	if resp == nil {
+		return
+	}
+
+	var rows []discordgo.MessageComponent
+	if resp.Components != nil && resp.Components.Handler != nil {
+		rows = state.update(resp.Components)
+	} else {
+		b.Router.forget(state)
+		if resp.Components != nil {
+			rows = resp.Components.Rows
+		}
+	}
+
+	if _, err := s.InteractionResponseEdit(i, webhookEdit(resp, rows)); err != nil {
+		b.Logger.Error().Err(err).Msg("Failed to update component message")
+	}
+}
+
+// callComponentHandler runs a handler, turning panics into errors
+func (b *Bot) callComponentHandler(handler commands.ComponentHandler, ctx *commands.ComponentContext) (resp *commands.CommandResponse, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx)
+}
+
+func (b *Bot) respondEphemeral(s *discordgo.Session, i *discordgo.Interaction, content string) {
+	err := s.InteractionRespond(i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		b.Logger.Error().Err(err).Msg("Failed to respond to interaction")
+	}
+}
+
+// expireComponents disables or removes the components of timed out responses
+// until the bot shuts down
+func (b *Bot) expireComponents() {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, state := range b.Router.expired(now) {
+				state.mu.Lock()
+				channelID, messageID, rows := state.channelID, state.messageID, state.rows
+				remove := state.components.Remove
+				state.mu.Unlock()
+
+				if messageID == "" {
+					continue
+				}
+				rows = commands.DisableComponents(rows)
+				if remove {
+					rows = []discordgo.MessageComponent{}
+				}
+				_, err := b.Session.ChannelMessageEditComplex(&discordgo.MessageEdit{
+					ID:         messageID,
+					Channel:    channelID,
+					Components: &rows,
+				})
+				if err != nil {
+					b.Logger.Debug().Err(err).Str("message", messageID).Msg("Failed to disable expired components")
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+)
+
+func buttonRow(buttons ...discordgo.Button) []discordgo.MessageComponent {
+	row := discordgo.ActionsRow{}
+	for _, button := range buttons {
+		row.Components = append(row.Components, button)
+	}
+	return []discordgo.MessageComponent{row}
+}
+
+func customIDs(rows []discordgo.MessageComponent) []string {
+	var ids []string
+	commands.MapComponents(rows, func(comp discordgo.MessageComponent) discordgo.MessageComponent {
+		if button, ok := comp.(discordgo.Button); ok {
+			ids = append(ids, button.CustomID)
+		}
+		return comp
+	})
+	return ids
+}
+
+func noopHandler(ctx *commands.ComponentContext) (*commands.CommandResponse, error) {
+	return nil, nil
+}
+
+func TestComponentRouterRoutesCustomIDs(t *testing.T) {
+	router := NewComponentRouter()
+	resp := &commands.CommandResponse{Components: &commands.Components{
+		Rows: buttonRow(
+			discordgo.Button{Label: "Next", CustomID: "next"},
+			discordgo.Button{Label: "Docs", Style: discordgo.LinkButton, URL: "https://example.com"},
+		),
+		Handler: noopHandler,
+	}}
+
+	state, rows := router.track(testContext("g"), resp)
+	if state == nil || router.Count() != 1 {
+		t.Fatal("components with a handler weren't tracked")
+	}
+	ids := customIDs(rows)
+	if ids[0] != state.id+":next" || ids[1] != "" {
+		t.Fatalf("got custom IDs %q, want the action prefixed and links untouched", ids)
+	}
+	if got, action := router.get(ids[0]); got != state || action != "next" {
+		t.Errorf("got %v %q, want the state and its action", got, action)
+	}
+	if got, _ := router.get("unknown:next"); got != nil {
+		t.Error("unknown state was routed")
+	}
+
+	// Components without a handler are sent as is
+	plain := &commands.CommandResponse{Components: &commands.Components{Rows: buttonRow(discordgo.Button{CustomID: "x"})}}
+	if state, rows := router.track(testContext("g"), plain); state != nil || customIDs(rows)[0] != "x" {
+		t.Error("components without a handler were routed")
+	}
+	if router.Count() != 1 {
+		t.Errorf("got %d tracked, want 1", router.Count())
+	}
+}
+
+func TestComponentStateAllowed(t *testing.T) {
+	tests := []struct {
+		name       string
+		components commands.Components
+		user       string
+		want       bool
+	}{
+		{"author", commands.Components{}, "1", true},
+		{"someone else", commands.Components{}, "2", false},
+		{"listed user", commands.Components{Users: []string{"2"}}, "2", true},
+		{"author not listed", commands.Components{Users: []string{"2"}}, "1", false},
+		{"public", commands.Components{Public: true}, "2", true},
+	}
+
+	for _, tt := range tests {
+		tt.components.Handler = noopHandler
+		state, _ := NewComponentRouter().track(testContext("g"), &commands.CommandResponse{Components: &tt.components})
+		if got := state.allowed(tt.user); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestComponentRouterExpiry(t *testing.T) {
+	router := NewComponentRouter()
+	short, _ := router.track(testContext("g"), &commands.CommandResponse{Components: &commands.Components{Handler: noopHandler, Timeout: 1000}})
+	long, _ := router.track(testContext("g"), &commands.CommandResponse{Components: &commands.Components{Handler: noopHandler, Timeout: 60000}})
+
+	expired := router.expired(time.Now().Add(2 * time.Second))
+	if len(expired) != 1 || expired[0] != short {
+		t.Fatalf("got %d expired, want only the short one", len(expired))
+	}
+	if got, _ := router.get(short.id + ":a"); got != nil {
+		t.Error("expired state still routed")
+	}
+	if got, _ := router.get(long.id + ":a"); got != long {
+		t.Error("live state dropped")
+	}
+
+	long.sent(&discordgo.Message{ID: "m", ChannelID: "c"})
+	router.forgetMessage("m")
+	if router.Count() != 0 {
+		t.Error("state kept after its message was replaced")
+	}
+}
+
+func TestRunComponent(t *testing.T) {
+	h := commandtest.New()
+	b := &Bot{Router: NewComponentRouter(), Logger: zerolog.Nop(), ctx: context.Background()}
+	interaction := &discordgo.Interaction{ID: "i", AppID: "app", Token: "token"}
+	clicker := &discordgo.User{ID: "2"}
+
+	var got *commands.ComponentContext
+	pages := &commands.Components{Rows: buttonRow(discordgo.Button{CustomID: "next"})}
+	pages.Handler = func(ctx *commands.ComponentContext) (*commands.CommandResponse, error) {
+		got = ctx
+		switch ctx.Action {
+		case "next":
+			return &commands.CommandResponse{Content: "page 2", Components: pages}, nil
+		case "fail":
+			return nil, errors.New("boom")
+		}
+		return &commands.CommandResponse{Content: "done"}, nil
+	}
+	origin := h.Context("pages")
+	state, _ := b.Router.track(origin, &commands.CommandResponse{Components: pages})
+
+	b.runComponent(h.Session, interaction, state, clicker, "next", []string{"v"})
+	if got == nil || got.Action != "next" || got.User != clicker || got.Origin != origin || got.Values[0] != "v" {
+		t.Fatalf("handler got %+v", got)
+	}
+	reqs := h.Transport.Requests()
+	if len(reqs) != 1 || !strings.HasSuffix(reqs[0].Path, "/messages/@original") {
+		t.Fatalf("got %d requests, want the original edited", len(reqs))
+	}
+	if body := string(reqs[0].Body); !strings.Contains(body, "page 2") || !strings.Contains(body, state.id+":next") {
+		t.Errorf("got edit %s, want the new page with routed components", body)
+	}
+	if b.Router.Count() != 1 {
+		t.Error("state dropped while the response still has a handler")
+	}
+
+	h.Transport.Reset()
+	b.runComponent(h.Session, interaction, state, clicker, "fail", nil)
+	failed := false
+	for _, req := range h.Transport.Requests() {
+		failed = failed || strings.Contains(string(req.Body), "boom")
+	}
+	if !failed {
+		t.Error("handler error wasn't reported")
+	}
+
+	b.runComponent(h.Session, interaction, state, clicker, "close", nil)
+	if b.Router.Count() != 0 {
+		t.Error("state kept after a response without a handler")
+	}
+}
@@ -0,0 +1,156 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package bot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// editWindow is how long after sending a command it can be fixed by
+	// editing the message
+	editWindow = 5 * time.Minute
+
+	// maxTrackedReplies bounds the memory used to remember replies
+	maxTrackedReplies = 5000
+)
+
+// trackedReply is the bot's reply to a command message
+type trackedReply struct {
+	invocationID string
+	content      string             // Content of the command message when it ran
+	reply        *discordgo.Message // Nil while the command runs, or if it sent no reply
+	at           time.Time
+}
+
+// ranCommand is a message that ran as a command
+type ranCommand struct {
+	id string
+	at time.Time
+}
+
+// replyTracker remembers the replies to recent command messages, so that
+// editing a command edits its reply. Entries expire after editWindow and the
+// oldest are dropped beyond maxTrackedReplies. Which messages ran as commands
+// is kept for the whole editWindow, so a command whose reply was dropped
+// doesn't run again when edited.
+type replyTracker struct {
+	mu       sync.Mutex
+	replies  map[string]*trackedReply
+	order    []*trackedReply // Oldest first
+	ran      map[string]bool
+	ranOrder []ranCommand // Oldest first
+}
+
+func newReplyTracker() *replyTracker {
+	return &replyTracker{replies: make(map[string]*trackedReply), ran: make(map[string]bool)}
+}
+
+// add records the reply to a command message, replacing any previous one
+func (t *replyTracker) add(invocation *discordgo.Message, reply *discordgo.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry := &trackedReply{
+		invocationID: invocation.ID,
+		content:      invocation.Content,
+		reply:        reply,
+		at:           time.Now(),
+	}
+	t.replies[invocation.ID] = entry
+	t.order = append(t.order, entry)
+	if !t.ran[invocation.ID] {
+		t.ran[invocation.ID] = true
+		t.ranOrder = append(t.ranOrder, ranCommand{id: invocation.ID, at: entry.at})
+	}
+	t.prune(entry.at)
+}
+
+// get returns the tracked reply to a command message, if it's recent
+func (t *replyTracker) get(invocationID string) *trackedReply {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry := t.replies[invocationID]
+	if entry == nil || time.Since(entry.at) > editWindow {
+		return nil
+	}
+	return entry
+}
+
+// prune drops expired entries and the oldest ones beyond the limit. Entries
+// replaced by add stay in order until they're pruned.
+func (t *replyTracker) prune(now time.Time) {
+	drop := 0
+	for drop < len(t.order) {
+		entry := t.order[drop]
+		if len(t.order)-drop <= maxTrackedReplies && now.Sub(entry.at) <= editWindow {
+			break
+		}
+		if t.replies[entry.invocationID] == entry {
+			delete(t.replies, entry.invocationID)
+		}
+		drop++
+	}
+	t.order = t.order[drop:]
+
+	drop = 0
+	for drop < len(t.ranOrder) && now.Sub(t.ranOrder[drop].at) > editWindow {
+		delete(t.ran, t.ranOrder[drop].id)
+		drop++
+	}
+	t.ranOrder = t.ranOrder[drop:]
+}
+
+// ranRecently reports whether a message ran as a command within editWindow
+func (t *replyTracker) ranRecently(invocationID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.ran[invocationID]
+}
+
+// rerun decides whether an edited message runs again, returning the reply to
+// edit. Commands that ran without replying, like clean, or whose reply is no
+// longer tracked aren't run again, so an edit doesn't repeat their side
+// effects. Messages that weren't a command, e.g. typos, can still be fixed
+// while recent.
+func (t *replyTracker) rerun(m *discordgo.Message) (previous *discordgo.Message, ok bool) {
+	tracked := t.get(m.ID)
+	if tracked == nil {
+		return nil, !t.ranRecently(m.ID) && time.Since(m.Timestamp) <= editWindow
+	}
+	if tracked.reply == nil || tracked.content == m.Content {
+		return nil, false
+	}
+	return tracked.reply, true
+}
+
+// handleMessageUpdate re-runs a recent command when its message is edited,
+// editing the previous reply instead of sending a new one
+func (b *Bot) handleMessageUpdate(s *discordgo.Session, m *discordgo.MessageUpdate) {
+	// Embeds being unfurled also send updates, only handle real edits
+	if m.Author == nil || m.EditedTimestamp == nil {
+		return
+	}
+
+	if previous, ok := b.replies.rerun(m.Message); ok {
+		b.handleMessage(s, m.Message, true, previous)
+	}
+}
@@ -0,0 +1,73 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package bot
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestReplyTrackerRerun(t *testing.T) {
+	tracker := newReplyTracker()
+	reply := &discordgo.Message{ID: "reply"}
+
+	replied := &discordgo.Message{ID: "1", Content: "pls tweet helo", Timestamp: time.Now()}
+	tracker.add(replied, reply)
+	silent := &discordgo.Message{ID: "2", Content: "pls clean 50", Timestamp: time.Now()}
+	tracker.add(silent, nil)
+
+	tests := []struct {
+		name     string
+		msg      *discordgo.Message
+		previous *discordgo.Message
+		ok       bool
+	}{
+		{"edited command", &discordgo.Message{ID: "1", Content: "pls tweet hello"}, reply, true},
+		{"unchanged content", &discordgo.Message{ID: "1", Content: "pls tweet helo"}, nil, false},
+		{"command without reply", &discordgo.Message{ID: "2", Content: "pls clean 40"}, nil, false},
+		{"recent typo", &discordgo.Message{ID: "3", Content: "pls meem", Timestamp: time.Now()}, nil, true},
+		{"old message", &discordgo.Message{ID: "4", Content: "pls meem", Timestamp: time.Now().Add(-editWindow - time.Minute)}, nil, false},
+	}
+
+	for _, tt := range tests {
+		previous, ok := tracker.rerun(tt.msg)
+		if previous != tt.previous || ok != tt.ok {
+			t.Errorf("%s: got %v %v, want %v %v", tt.name, previous, ok, tt.previous, tt.ok)
+		}
+	}
+}
+
+func TestReplyTrackerKeepsEvictedCommands(t *testing.T) {
+	tracker := newReplyTracker()
+	first := &discordgo.Message{ID: "first", Content: "pls daily", Timestamp: time.Now()}
+	tracker.add(first, &discordgo.Message{ID: "reply"})
+
+	// Push the first reply out of the bounded reply map
+	for i := range maxTrackedReplies {
+		tracker.add(&discordgo.Message{ID: fmt.Sprint(i)}, nil)
+	}
+	if tracker.get("first") != nil {
+		t.Fatal("first reply still tracked")
+	}
+
+	if _, ok := tracker.rerun(&discordgo.Message{ID: "first", Content: "pls dailyy", Timestamp: first.Timestamp}); ok {
+		t.Error("evicted command ran again")
+	}
+}
@@ -0,0 +1,300 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package bot
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+const (
+	// busyReplyInterval is how often a channel is told the bot is busy, so
+	// a raid isn't answered with a raid of replies
+	busyReplyInterval = 10 * time.Second
+
+	// admissionTimeout bounds the checks run before a command is queued
+	admissionTimeout = 5 * time.Second
+
+	// shutdownTimeout is how long shutdown waits for running commands
+	shutdownTimeout = 10 * time.Second
+)
+
+// job is a command waiting for, or holding, a worker
+type job struct {
+	ctx   *commands.CommandContext
+	cmd   commands.Command
+	guild string // Fairness key, the guild or the DM author
+	key   string // Concurrency key, empty if the command has no limit
+	limit int
+}
+
+// slotKey is the context key of the slot a command runs in
+type slotKey struct{}
+
+// slot is the worker and limits held by a running job. It is released once
+// the command and any work it left running have returned.
+type slot struct {
+	refs    atomic.Int32
+	release func()
+}
+
+func (s *slot) done() {
+	if s.refs.Add(-1) == 0 {
+		s.release()
+	}
+}
+
+// holdSlot keeps the slot of the command running in ctx until the returned
+// func is called. Timeout uses it for commands it stops waiting for, so a hung
+// backend keeps counting against the limits.
+func holdSlot(ctx context.Context) (release func()) {
+	s, ok := ctx.Value(slotKey{}).(*slot)
+	if !ok {
+		return func() {}
+	}
+	s.refs.Add(1)
+	return s.done
+}
+
+// executor runs commands on a bounded number of goroutines. Commands over a
+// limit wait in a queue per guild, and the guilds take turns when a worker
+// frees up so a busy guild can't starve the others.
+type executor struct {
+	cfg utils.ExecutorConfig
+	run func(ctx *commands.CommandContext, cmd commands.Command)
+
+	mu       sync.Mutex
+	running  int
+	perGuild map[string]int    // Running commands by guild
+	perKey   map[string]int    // Running commands by concurrency key
+	queues   map[string][]*job // Waiting commands by guild
+	turns    []string          // Guilds with waiting commands, next turn first
+	queued   int
+	busySent map[string]time.Time // Last busy reply by channel
+	closed   bool
+	active   sync.WaitGroup // Running jobs, including work they left running
+}
+
+func newExecutor(cfg utils.ExecutorConfig, run func(ctx *commands.CommandContext, cmd commands.Command)) *executor {
+	return &executor{
+		cfg:      cfg,
+		run:      run,
+		perGuild: make(map[string]int),
+		perKey:   make(map[string]int),
+		queues:   make(map[string][]*job),
+		busySent: make(map[string]time.Time),
+	}
+}
+
+// submit runs a command now or queues it. It returns false when the queue is
+// full or the executor is stopped.
+func (e *executor) submit(ctx *commands.CommandContext, cmd commands.Command) bool {
+	j := &job{ctx: ctx, cmd: cmd, guild: ctx.Message.GuildID}
+	if j.guild == "" {
+		j.guild = "dm:" + ctx.Message.Author.ID
+	}
+	j.key, j.limit = e.limitOf(cmd.Props())
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return false
+	}
+
+	// Waiting commands are only held back by their command's limit, which
+	// doesn't stop this one
+	if e.canRun(j) {
+		e.start(j)
+		return true
+	}
+
+	if e.queued >= e.cfg.QueueSize || len(e.queues[j.guild]) >= e.cfg.PerGuildQueue {
+		return false
+	}
+	if len(e.queues[j.guild]) == 0 {
+		e.turns = append(e.turns, j.guild)
+	}
+	e.queues[j.guild] = append(e.queues[j.guild], j)
+	e.queued++
+	return true
+}
+
+// limitOf returns the concurrency key of a command and how many of it may run
+// at once, the config overriding the command's own limit
+func (e *executor) limitOf(props commands.CommandProps) (string, int) {
+	key := props.ConcurrencyKey
+	if key == "" {
+		key = props.Triggers[0]
+	}
+
+	limit := props.Concurrency
+	if n, ok := e.cfg.Limits[key]; ok {
+		limit = n
+	}
+	if limit <= 0 {
+		return "", 0
+	}
+	return key, limit
+}
+
+// canRun reports whether a job fits within every limit. Must hold mu.
+func (e *executor) canRun(j *job) bool {
+	if e.running >= e.cfg.Workers || e.perGuild[j.guild] >= e.cfg.PerGuild {
+		return false
+	}
+	return j.key == "" || e.perKey[j.key] < j.limit
+}
+
+// start runs a job on a new goroutine. Must hold mu.
+func (e *executor) start(j *job) {
+	e.active.Add(1)
+	e.running++
+	e.perGuild[j.guild]++
+	if j.key != "" {
+		e.perKey[j.key]++
+	}
+
+	s := &slot{release: func() { e.finish(j) }}
+	s.refs.Store(1)
+	j.ctx.Context = context.WithValue(j.ctx.Context, slotKey{}, s)
+
+	go func() {
+		defer s.done()
+		e.run(j.ctx, j.cmd)
+	}()
+}
+
+// finish releases a job's slot and starts waiting jobs in its place
+func (e *executor) finish(j *job) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.running--
+	if e.perGuild[j.guild]--; e.perGuild[j.guild] == 0 {
+		delete(e.perGuild, j.guild)
+	}
+	if j.key != "" {
+		if e.perKey[j.key]--; e.perKey[j.key] == 0 {
+			delete(e.perKey, j.key)
+		}
+	}
+
+	if !e.closed {
+		e.schedule()
+	}
+	e.active.Done()
+}
+
+// schedule starts waiting jobs while workers are free, one per guild per
+// turn. A job held back by its command's limit lets the guild's later jobs
+// go ahead. Must hold mu.
+func (e *executor) schedule() {
+	for idle := 0; idle < len(e.turns) && e.running < e.cfg.Workers; {
+		guild := e.turns[0]
+		e.turns = e.turns[1:]
+
+		queue := e.queues[guild]
+		started := false
+		for i, j := range queue {
+			if e.canRun(j) {
+				queue = append(queue[:i:i], queue[i+1:]...)
+				e.queued--
+				e.start(j)
+				started = true
+				break
+			}
+		}
+
+		if len(queue) == 0 {
+			delete(e.queues, guild)
+		} else {
+			e.queues[guild] = queue
+			e.turns = append(e.turns, guild)
+		}
+
+		// Stop after a full round without starting anything
+		if started {
+			idle = 0
+		} else {
+			idle++
+		}
+	}
+}
+
+// shouldReplyBusy reports whether a channel may be told the bot is busy,
+// at most once per busyReplyInterval
+func (e *executor) shouldReplyBusy(channelID string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := e.busySent[channelID]; ok && now.Sub(last) < busyReplyInterval {
+		return false
+	}
+
+	// Forget channels that can be told again
+	if len(e.busySent) > 1000 {
+		for channel, at := range e.busySent {
+			if now.Sub(at) >= busyReplyInterval {
+				delete(e.busySent, channel)
+			}
+		}
+	}
+
+	e.busySent[channelID] = now
+	return true
+}
+
+// stop refuses new jobs and returns the waiting ones, which will never run.
+// Running jobs finish on their own once their context is cancelled.
+func (e *executor) stop() []*job {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.closed = true
+	var dropped []*job
+	for _, guild := range e.turns {
+		dropped = append(dropped, e.queues[guild]...)
+	}
+	e.queues = make(map[string][]*job)
+	e.turns = nil
+	e.queued = 0
+	return dropped
+}
+
+// wait waits up to timeout for the running jobs to return, reporting whether
+// they all did
+func (e *executor) wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		e.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
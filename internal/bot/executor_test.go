@@ -0,0 +1,246 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package bot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// gate is a command that blocks until released, recording how many of it run
+type gate struct {
+	props   commands.CommandProps
+	release chan struct{}
+
+	mu      sync.Mutex
+	running int
+	peak    int
+	order   []string
+}
+
+func newGate(trigger string, props commands.CommandProps) *gate {
+	props.Triggers = []string{trigger}
+	return &gate{props: props, release: make(chan struct{})}
+}
+
+func (g *gate) Props() commands.CommandProps { return g.props }
+
+func (g *gate) Run(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+	g.mu.Lock()
+	g.running++
+	g.peak = max(g.peak, g.running)
+	g.order = append(g.order, ctx.Message.GuildID)
+	g.mu.Unlock()
+
+	<-g.release
+
+	g.mu.Lock()
+	g.running--
+	g.mu.Unlock()
+	return nil, nil
+}
+
+func (g *gate) stats() (running, peak int, order []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.running, g.peak, append([]string(nil), g.order...)
+}
+
+func testContext(guildID string) *commands.CommandContext {
+	return &commands.CommandContext{
+		Context: context.Background(),
+		Message: &discordgo.MessageCreate{Message: &discordgo.Message{
+			GuildID: guildID,
+			Author:  &discordgo.User{ID: "1"},
+		}},
+	}
+}
+
+func newTestExecutor(cfg utils.ExecutorConfig, pipeline HandlerFunc) *executor {
+	return newExecutor(cfg, func(ctx *commands.CommandContext, cmd commands.Command) {
+		pipeline(ctx, cmd)
+	})
+}
+
+// usage returns the executor's counters
+func (e *executor) usage() (running, queued int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.running, e.queued
+}
+
+// waitFor polls cond until it holds or a second passes
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestExecutorLimits(t *testing.T) {
+	cmd := newGate("slow", commands.CommandProps{})
+	e := newTestExecutor(utils.ExecutorConfig{Workers: 2, QueueSize: 3, PerGuild: 2, PerGuildQueue: 2}, runCommand)
+
+	for _, guild := range []string{"a", "a", "a", "a"} {
+		if !e.submit(testContext(guild), cmd) {
+			t.Fatal("command rejected below the limits")
+		}
+	}
+	if e.submit(testContext("a"), cmd) {
+		t.Fatal("command accepted over the per-guild queue")
+	}
+	if !e.submit(testContext("b"), cmd) {
+		t.Fatal("another guild was rejected")
+	}
+	if e.submit(testContext("c"), cmd) {
+		t.Fatal("command accepted over the queue size")
+	}
+
+	waitFor(t, "2 running", func() bool { running, _, _ := cmd.stats(); return running == 2 })
+	if running, queued := e.usage(); running != 2 || queued != 3 {
+		t.Fatalf("running %d queued %d, want 2 and 3", running, queued)
+	}
+
+	// Free one worker at a time: the guilds take turns in the order they
+	// started waiting
+	for i, want := range []string{"a", "b"} {
+		cmd.release <- struct{}{}
+		waitFor(t, "the next command", func() bool { _, _, order := cmd.stats(); return len(order) == 3+i })
+		if _, _, order := cmd.stats(); order[2+i] != want {
+			t.Fatalf("order %v, want %s next", order, want)
+		}
+	}
+
+	close(cmd.release)
+	waitFor(t, "the queue to drain", func() bool { running, queued := e.usage(); return running == 0 && queued == 0 })
+	if _, peak, _ := cmd.stats(); peak != 2 {
+		t.Fatalf("peak %d, want 2", peak)
+	}
+}
+
+func TestExecutorCommandLimit(t *testing.T) {
+	image := newGate("image", commands.CommandProps{Concurrency: 1, ConcurrencyKey: "imgen"})
+	other := newGate("other", commands.CommandProps{})
+	e := newTestExecutor(utils.ExecutorConfig{Workers: 4, QueueSize: 10, PerGuild: 4, PerGuildQueue: 10}, runCommand)
+
+	e.submit(testContext("a"), image)
+	e.submit(testContext("a"), image)
+	e.submit(testContext("a"), other)
+
+	// The second image command waits, the other command goes ahead of it
+	waitFor(t, "the other command", func() bool { running, _, _ := other.stats(); return running == 1 })
+	if running, _, _ := image.stats(); running != 1 {
+		t.Fatalf("%d image commands running, want 1", running)
+	}
+
+	close(image.release)
+	close(other.release)
+	waitFor(t, "the queue to drain", func() bool { running, queued := e.usage(); return running == 0 && queued == 0 })
+	if _, peak, order := image.stats(); peak != 1 || len(order) != 2 {
+		t.Fatalf("peak %d runs %d, want 1 and 2", peak, len(order))
+	}
+}
+
+func TestExecutorConfigLimit(t *testing.T) {
+	e := newTestExecutor(utils.ExecutorConfig{Limits: map[string]int{"imgen": 3, "free": 0}}, runCommand)
+
+	if key, limit := e.limitOf(commands.CommandProps{Triggers: []string{"a"}, ConcurrencyKey: "imgen", Concurrency: 8}); key != "imgen" || limit != 3 {
+		t.Errorf("got %q %d, want the config's limit", key, limit)
+	}
+	if key, _ := e.limitOf(commands.CommandProps{Triggers: []string{"free"}, Concurrency: 2}); key != "" {
+		t.Errorf("got %q, want no limit when the config sets 0", key)
+	}
+	if key, limit := e.limitOf(commands.CommandProps{Triggers: []string{"b"}, Concurrency: 2}); key != "b" || limit != 2 {
+		t.Errorf("got %q %d, want the trigger and the command's limit", key, limit)
+	}
+}
+
+func TestExecutorKeepsSlotAfterTimeout(t *testing.T) {
+	cmd := newGate("hung", commands.CommandProps{Timeout: 10, Concurrency: 1})
+	e := newTestExecutor(utils.ExecutorConfig{Workers: 4, QueueSize: 10, PerGuild: 4, PerGuildQueue: 10}, Chain(runCommand, Timeout()))
+
+	e.submit(testContext("a"), cmd)
+	e.submit(testContext("a"), cmd)
+
+	// The first run timed out but is still running, so the second must wait
+	time.Sleep(50 * time.Millisecond)
+	if running, queued := e.usage(); running != 1 || queued != 1 {
+		t.Fatalf("running %d queued %d, want 1 and 1", running, queued)
+	}
+
+	close(cmd.release)
+	waitFor(t, "the queue to drain", func() bool { running, queued := e.usage(); return running == 0 && queued == 0 })
+	if _, peak, _ := cmd.stats(); peak != 1 {
+		t.Fatalf("peak %d, want 1", peak)
+	}
+}
+
+func TestExecutorStop(t *testing.T) {
+	cmd := newGate("slow", commands.CommandProps{})
+	e := newTestExecutor(utils.ExecutorConfig{Workers: 1, QueueSize: 10, PerGuild: 1, PerGuildQueue: 10}, runCommand)
+
+	e.submit(testContext("a"), cmd)
+	e.submit(testContext("a"), cmd)
+	e.stop()
+	if e.submit(testContext("a"), cmd) {
+		t.Fatal("stopped executor accepted a command")
+	}
+
+	close(cmd.release)
+	waitFor(t, "the running command", func() bool { running, _ := e.usage(); return running == 0 })
+	if _, _, order := cmd.stats(); len(order) != 1 {
+		t.Fatalf("%d commands ran, want the waiting one dropped", len(order))
+	}
+}
+
+func TestExecutorStopReturnsWaitingJobs(t *testing.T) {
+	g := newGate("slow", commands.CommandProps{})
+	e := newTestExecutor(utils.ExecutorConfig{Workers: 1, PerGuild: 1, QueueSize: 10, PerGuildQueue: 10}, runCommand)
+
+	for _, guild := range []string{"a", "b", "a"} {
+		if !e.submit(testContext(guild), g) {
+			t.Fatalf("%s: submit refused", guild)
+		}
+	}
+	waitFor(t, "first command", func() bool { running, _, _ := g.stats(); return running == 1 })
+
+	if dropped := e.stop(); len(dropped) != 2 {
+		t.Errorf("dropped %d jobs, want 2", len(dropped))
+	}
+	if e.submit(testContext("c"), g) {
+		t.Error("submit accepted after stop")
+	}
+
+	if e.wait(10 * time.Millisecond) {
+		t.Error("wait returned while a command was running")
+	}
+	close(g.release)
+	if !e.wait(time.Second) {
+		t.Error("wait timed out after the command returned")
+	}
+}
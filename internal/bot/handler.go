@@ -17,55 +17,63 @@
 package bot
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/bwmarrin/discordgo"
 
 	"github.com/dankmemer/bot/internal/commands"
 	"github.com/dankmemer/bot/internal/database"
+	"github.com/dankmemer/bot/internal/i18n"
 	"github.com/dankmemer/bot/internal/utils"
 )
 
 func (b *Bot) handleMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
+	b.handleMessage(s, m.Message, false, nil)
+}
+
+// handleMessage runs the command in a message. edited is set when the message
+// was edited, and previous is the bot's reply to an earlier version of it,
+// which is edited instead of sending a new reply.
+func (b *Bot) handleMessage(s *discordgo.Session, m *discordgo.Message, edited bool, previous *discordgo.Message) {
 	// Ignore bots
 	if m.Author.Bot {
 		return
 	}
 
-	// Ignore DMs
+	// Get guild config. DMs have no guild and use the defaults.
+	var guildConfig *database.GuildConfig
 	if m.GuildID == "" {
-		return
-	}
-
-	// Check if blocked
-	blocked, _ := b.DB.IsUserOrGuildBlocked(m.Author.ID, m.GuildID)
-	if blocked {
-		return
-	}
-
-	// Premium check
-	if b.Config.Premium && !utils.Contains(b.Config.PremiumGuilds, m.GuildID) {
-		if strings.HasPrefix(strings.ToLower(m.Content), b.Config.DefaultPrefix) {
-			s.ChannelMessageSend(m.ChannelID,
-				"This server is not a premium activated server. Want it activated? https://patreon.com/dank")
+		guildConfig = b.dmConfig()
+	} else {
+		var err error
+		guildConfig, err = b.DB.GetOrCreateGuild(b.ctx, m.GuildID, b.Config.DefaultPrefix)
+		if err != nil {
+			b.Logger.Error().Err(err).Str("guild", m.GuildID).Msg("Failed to get guild config")
+			guildConfig = &defaultGuildConfig
 		}
-		return
 	}
 
-	// Get guild config
-	guildConfig, err := b.DB.GetOrCreateGuild(m.GuildID, b.Config.DefaultPrefix)
-	if err != nil {
-		b.Logger.Error().Err(err).Str("guild", m.GuildID).Msg("Failed to get guild config")
-		guildConfig = &defaultGuildConfig
+	// The author's personal prefix works in every guild
+	prefixes := guildConfig.Prefixes
+	if personal, err := b.DB.GetUserPrefix(b.ctx, m.Author.ID); err != nil {
+		b.Logger.Error().Err(err).Str("user", m.Author.ID).Msg("Failed to get personal prefix")
+	} else if personal != "" {
+		prefixes = append(slices.Clip(prefixes), personal)
 	}
 
 	// Parse prefix and content
-	prefix, content, ok := b.parsePrefix(m.Content, guildConfig.Prefix)
+	prefix, content, ok := b.parsePrefix(m.Content, prefixes)
+	if !ok && m.GuildID == "" {
+		// Prefixes are optional in DMs
+		content, ok = strings.TrimSpace(m.Content), true
+	}
 	if !ok {
 		// Check for greeting with mention
-		if b.MentionRegex != nil && b.MentionRegex.MatchString(m.Content) {
+		if b.MentionRegex != nil && b.MentionRegex.MatchString(m.Content) && !edited {
 			if strings.Contains(strings.ToLower(m.Content), "hello") {
 				s.ChannelMessageSend(m.ChannelID,
 					fmt.Sprintf("Hello, %s. My prefix is `%s`. Example: `%s meme`",
@@ -75,8 +83,9 @@ func (b *Bot) handleMessageCreate(s *discordgo.Session, m *discordgo.MessageCrea
 		return
 	}
 
-	// Parse command and arguments
-	parts := strings.Fields(content)
+	// Expand guild aliases, then parse command and arguments
+	content = commands.ExpandAlias(guildConfig.Aliases, content)
+	parts, flags := commands.Tokenize(content)
 	if len(parts) == 0 {
 		return
 	}
@@ -86,102 +95,172 @@ func (b *Bot) handleMessageCreate(s *discordgo.Session, m *discordgo.MessageCrea
 
 	// Find command
 	cmd := b.Commands.Find(cmdName)
+	if cmd == nil && m.GuildID != "" {
+		cmd = b.findCustomCommand(m.GuildID, cmdName)
+	}
 	if cmd == nil {
+		// Unprefixed DMs are often just chat, so only suggest for prefixed ones
+		if guildConfig.Suggestions && prefix != "" {
+			b.suggestCommands(s, m, previous, guildConfig, prefix, cmdName)
+		}
 		return
 	}
 
-	props := cmd.Props()
+	// Take out the flags the command declares, other --words stay arguments
+	if declared := commands.DeclaredFlags(cmd, args); len(declared) > 0 {
+		parts, flags = commands.Tokenize(content, declared...)
+		args = parts[1:]
+	}
 
-	// Check owner-only
-	if props.OwnerOnly && !utils.Contains(b.Config.Devs, m.Author.ID) {
-		return
+	// Create context
+	ctx := &commands.CommandContext{
+		Session:     s,
+		Message:     &discordgo.MessageCreate{Message: m},
+		Content:     content,
+		Args:        args,
+		CleanArgs:   b.resolveCleanArgs(s, m, args),
+		Flags:       flags,
+		GuildConfig: guildConfig,
+		Services:    b.Services,
+	}
+	if previous != nil {
+		ctx.Responder = &messageResponder{session: s, channelID: m.ChannelID, placeholder: previous}
+	} else {
+		// Edits while the command runs don't run it again
+		b.replies.add(m, nil)
+	}
+
+	b.dispatch(ctx, cmd)
+}
+
+// dispatch checks whether a command may run and hands it to the executor,
+// which runs it through the middleware pipeline. It is shared by prefixed
+// messages and slash commands.
+func (b *Bot) dispatch(ctx *commands.CommandContext, cmd commands.Command) {
+	if ctx.Command == "" {
+		ctx.Command = cmd.Props().Triggers[0]
+	}
+	if ctx.Context == nil {
+		ctx.Context = b.ctx
+	}
+	if ctx.Responder == nil {
+		ctx.Responder = newResponder(ctx)
 	}
 
-	// Check if command is disabled
-	if utils.Contains(guildConfig.DisabledCommands, props.Triggers[0]) {
+	if resp, ok := b.admit(ctx, cmd); !ok {
+		b.sendResponse(ctx, resp)
 		return
 	}
 
-	// Check if NSFW category is disabled
-	if props.IsNSFW && utils.Contains(guildConfig.DisabledCommands, "nsfw") {
+	if b.executor.submit(ctx, cmd) {
 		return
 	}
 
-	// Check cooldown
-	cooldown := props.Cooldown
-	if cooldown == 0 {
-		cooldown = 3000 // Default 3 seconds
+	b.Logger.Debug().
+		Str("command", ctx.Command).
+		Str("guild", ctx.Message.GuildID).
+		Msg("Executor queue full, dropping command")
+
+	// Deferred slash commands always need an answer
+	if ctx.Interaction != nil || b.executor.shouldReplyBusy(ctx.Message.ChannelID) {
+		b.sendResponse(ctx, &commands.CommandResponse{Content: ctx.T("errors.busy")})
 	}
+}
 
-	remainingCD, _ := b.DB.IsOnCooldown(props.Triggers[0], m.Author.ID)
-	if remainingCD > 0 {
-		msg := props.CooldownMessage
-		if msg == "" {
-			msg = "stop spamming my commands dude, you have to wait {cooldown}"
-		}
-		msg = strings.Replace(msg, "{cooldown}", utils.FormatDuration(remainingCD), 1)
-		s.ChannelMessageSend(m.ChannelID, msg)
-		return
+// admit runs the admission checks, bounded by admissionTimeout
+func (b *Bot) admit(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, bool) {
+	parent := ctx.Context
+	checkCtx, cancel := context.WithTimeout(parent, admissionTimeout)
+	defer cancel()
+
+	ctx.Context = checkCtx
+	defer func() { ctx.Context = parent }()
+
+	return b.admission(ctx, cmd)
+}
+
+// findCustomCommand returns a guild's custom command, or nil if there is none.
+// It runs through the pipeline like a built-in command.
+func (b *Bot) findCustomCommand(guildID, name string) commands.Command {
+	custom, err := b.DB.GetCustomCommand(b.ctx, guildID, name)
+	if err != nil {
+		b.Logger.Error().Err(err).Str("guild", guildID).Str("command", name).Msg("Failed to get custom command")
+		return nil
+	}
+	if custom == nil {
+		return nil
+	}
+
+	cmd, err := commands.NewCustomCommand(custom)
+	if err != nil {
+		b.Logger.Warn().Err(err).Str("guild", guildID).Str("command", name).Msg("Invalid custom command template")
+		return nil
 	}
+	return cmd
+}
 
-	// Check permissions
-	if !b.checkPermissions(s, m, props.Permissions) {
+// suggestCommands replies with the closest triggers to an unknown command,
+// leaving out commands the guild can't use
+func (b *Bot) suggestCommands(s *discordgo.Session, m *discordgo.Message, previous *discordgo.Message, guildConfig *database.GuildConfig, prefix, cmdName string) {
+	if b.Config.Premium && m.GuildID != "" && !isPremiumGuild(b.Config, m.GuildID, guildConfig) {
+		return
+	}
+	if blocked, _ := b.DB.IsUserOrGuildBlocked(b.ctx, m.Author.ID, m.GuildID); blocked {
 		return
 	}
 
-	// Check NSFW channel
-	if props.IsNSFW {
-		channel, err := s.State.Channel(m.ChannelID)
-		if err != nil {
-			channel, err = s.Channel(m.ChannelID)
-		}
-		if err != nil || !channel.NSFW {
-			embed := &discordgo.MessageEmbed{
-				Title:       "NSFW not allowed here",
-				Description: "Use NSFW commands in a NSFW marked channel",
-				Color:       utils.RandomColor(),
-			}
-			s.ChannelMessageSendEmbed(m.ChannelID, embed)
-			return
-		}
+	suggestions := b.Commands.Suggest(cmdName, 3, func(cmd commands.Command) bool {
+		props := cmd.Props()
+		return props.OwnerOnly ||
+			(m.GuildID == "" && !props.DMAllowed) ||
+			commands.IsDisabled(guildConfig, props)
+	})
+	if len(suggestions) == 0 {
+		return
 	}
 
-	// Create context
-	ctx := &commands.CommandContext{
-		Session:     s,
-		Message:     m,
-		Args:        args,
-		CleanArgs:   b.resolveCleanArgs(s, m.Message, args),
-		GuildConfig: guildConfig,
-		Bot:         b,
+	for i, name := range suggestions {
+		suggestions[i] = fmt.Sprintf("`%s %s`", prefix, name)
 	}
 
-	// Execute command in goroutine
-	go b.executeCommand(ctx, cmd, prefix)
-}
+	content := i18n.T(guildConfig.Locale, "suggestions.message", strings.Join(suggestions, ", "))
 
-func (b *Bot) parsePrefix(content, guildPrefix string) (prefix, rest string, ok bool) {
-	lower := strings.ToLower(content)
+	var sent *discordgo.Message
+	var err error
+	if previous != nil {
+		sent, err = editPlaceholder(s, previous, &discordgo.MessageSend{Content: content})
+	} else {
+		sent, err = s.ChannelMessageSend(m.ChannelID, content)
+	}
+	if err == nil {
+		b.replies.add(m, sent)
+	}
+}
 
+// parsePrefix strips the bot's mention or the longest matching prefix from
+// content, ignoring case
+func (b *Bot) parsePrefix(content string, prefixes []string) (prefix, rest string, ok bool) {
 	// Check mention prefix
 	if b.MentionRegex != nil && b.MentionRegex.MatchString(content) {
 		prefix = b.MentionRegex.FindString(content)
-		rest = strings.TrimPrefix(content, prefix)
-		rest = strings.TrimSpace(rest)
+		rest = strings.TrimSpace(strings.TrimPrefix(content, prefix))
 		return prefix, rest, true
 	}
 
-	// Check guild prefix
-	if strings.HasPrefix(lower, strings.ToLower(guildPrefix)) {
-		prefix = content[:len(guildPrefix)]
-		rest = strings.TrimSpace(content[len(guildPrefix):])
-		return prefix, rest, true
+	// Check guild and personal prefixes, the longest match wins so "pls" beats "p"
+	for _, p := range prefixes {
+		if len(p) > len(prefix) && len(content) >= len(p) && strings.EqualFold(content[:len(p)], p) {
+			prefix = content[:len(p)]
+		}
+	}
+	if prefix == "" {
+		return "", "", false
 	}
 
-	return "", "", false
+	return prefix, strings.TrimSpace(content[len(prefix):]), true
 }
 
-func (b *Bot) executeCommand(ctx *commands.CommandContext, cmd commands.Command, prefix string) {
+func (b *Bot) executeCommand(ctx *commands.CommandContext, cmd commands.Command) {
 	defer func() {
 		if r := recover(); r != nil {
 			b.Logger.Error().
@@ -191,62 +270,43 @@ func (b *Bot) executeCommand(ctx *commands.CommandContext, cmd commands.Command,
 				Str("guild", ctx.Message.GuildID).
 				Msg("Command panicked")
 
-			ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-				"Something went wrong while executing that command. Please try again later.")
+			b.sendResponse(ctx, &commands.CommandResponse{Content: ctx.T("errors.panic")})
 		}
 	}()
 
-	props := cmd.Props()
-
-	// Log command execution
-	b.Logger.Debug().
-		Str("command", props.Triggers[0]).
-		Str("user", ctx.Message.Author.ID).
-		Str("guild", ctx.Message.GuildID).
-		Strs("args", ctx.Args).
-		Msg("Executing command")
-
-	start := time.Now()
+	resp, err := b.pipeline(ctx, cmd)
+	if err != nil {
+		b.sendResponse(ctx, &commands.CommandResponse{Content: ctx.T("errors.generic", err.Error())})
+		return
+	}
 
-	// Execute the command
-	resp, err := cmd.Run(ctx)
+	b.sendResponse(ctx, resp)
+}
 
-	duration := time.Since(start)
+func (b *Bot) sendResponse(ctx *commands.CommandContext, resp *commands.CommandResponse) {
+	placeholder := finishResponder(ctx)
 
-	if err != nil {
-		b.Logger.Error().
-			Err(err).
-			Str("command", props.Triggers[0]).
-			Strs("args", ctx.Args).
-			Dur("duration", duration).
-			Msg("Command error")
-
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			fmt.Sprintf("Something went wrong: `%s`", err.Error()))
+	if ctx.Interaction != nil {
+		b.sendInteractionResponse(ctx, resp)
 		return
 	}
 
-	// Set cooldown after successful execution
-	cooldown := props.Cooldown
-	if cooldown == 0 {
-		cooldown = 3000
+	if placeholder != nil {
+		// The placeholder may be the reply to an earlier version of the message
+		b.Router.forgetMessage(placeholder.ID)
 	}
-	b.DB.SetCooldown(props.Triggers[0], ctx.Message.Author.ID, cooldown)
 
 	if resp == nil {
+		if placeholder != nil {
+			if err := ctx.Session.ChannelMessageDelete(placeholder.ChannelID, placeholder.ID); err != nil {
+				b.Logger.Error().Err(err).Msg("Failed to delete placeholder")
+			}
+		}
+		// Remember the command ran, so editing it doesn't run it again
+		b.replies.add(ctx.Message.Message, nil)
 		return
 	}
 
-	// Send response
-	b.sendResponse(ctx, resp)
-
-	b.Logger.Debug().
-		Str("command", props.Triggers[0]).
-		Dur("duration", duration).
-		Msg("Command completed")
-}
-
-func (b *Bot) sendResponse(ctx *commands.CommandContext, resp *commands.CommandResponse) {
 	// Build message
 	msg := &discordgo.MessageSend{}
 
@@ -278,10 +338,56 @@ func (b *Bot) sendResponse(ctx *commands.CommandContext, resp *commands.CommandR
 		}
 	}
 
-	_, err := ctx.Session.ChannelMessageSendComplex(ctx.Message.ChannelID, msg)
+	state, rows := b.Router.track(ctx, resp)
+	msg.Components = rows
+
+	var sent *discordgo.Message
+	var err error
+	if placeholder != nil && !resp.FollowUp {
+		sent, err = editPlaceholder(ctx.Session, placeholder, msg)
+	} else {
+		sent, err = ctx.Session.ChannelMessageSendComplex(ctx.Message.ChannelID, msg)
+	}
 	if err != nil {
 		b.Logger.Error().Err(err).Msg("Failed to send response")
+		if state != nil {
+			b.Router.forget(state)
+		}
+		return
+	}
+	if state != nil {
+		state.sent(sent)
 	}
+	b.replies.add(ctx.Message.Message, sent)
+}
+
+// editPlaceholder replaces a placeholder with a message. Replies can't be
+// added by editing, so msg.Reference is ignored.
+func editPlaceholder(s *discordgo.Session, placeholder *discordgo.Message, msg *discordgo.MessageSend) (*discordgo.Message, error) {
+	embeds := msg.Embeds
+	if embeds == nil {
+		embeds = []*discordgo.MessageEmbed{}
+	}
+	components := msg.Components
+	if components == nil {
+		components = []discordgo.MessageComponent{}
+	}
+
+	// Replace the attachments of the message with the new files
+	attachments := make([]*discordgo.MessageAttachment, len(msg.Files))
+	for i, file := range msg.Files {
+		attachments[i] = &discordgo.MessageAttachment{ID: strconv.Itoa(i), Filename: file.Name}
+	}
+
+	return s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:          placeholder.ID,
+		Channel:     placeholder.ChannelID,
+		Content:     &msg.Content,
+		Embeds:      &embeds,
+		Components:  &components,
+		Files:       msg.Files,
+		Attachments: &attachments,
+	})
 }
 
 func (b *Bot) resolveCleanArgs(s *discordgo.Session, m *discordgo.Message, args []string) []string {
@@ -306,7 +412,30 @@ func (b *Bot) resolveCleanArgs(s *discordgo.Session, m *discordgo.Message, args
 	return clean
 }
 
+// guildLocale returns a guild's locale, for replies outside of a command
+func (b *Bot) guildLocale(guildID string) string {
+	cfg, err := b.DB.GetGuild(b.ctx, guildID)
+	if err != nil || cfg == nil {
+		return i18n.Default
+	}
+	return cfg.Locale
+}
+
+// dmConfig is the config used in DMs: the default prefix and no guild
+// settings
+func (b *Bot) dmConfig() *database.GuildConfig {
+	cfg := defaultGuildConfig
+	cfg.Prefix = b.Config.DefaultPrefix
+	cfg.Prefixes = []string{b.Config.DefaultPrefix}
+	cfg.Suggestions = true // No other bots to clash with in DMs
+	return &cfg
+}
+
 var defaultGuildConfig = database.GuildConfig{
-	Prefix:           "pls",
-	DisabledCommands: []string{},
+	Prefix:             "pls",
+	Prefixes:           []string{"pls"},
+	DisabledCommands:   []string{},
+	DisabledCategories: []string{},
+	Aliases:            map[string]string{},
+	Locale:             "en",
 }
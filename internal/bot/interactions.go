@@ -0,0 +1,204 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package bot
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// registerApplicationCommands overwrites the bot's global slash commands
+// with the contents of the command registry
+func (b *Bot) registerApplicationCommands(s *discordgo.Session, appID string) {
+	appCmds, skipped, overflow := b.Commands.ApplicationCommands()
+	if len(skipped) > 0 {
+		b.Logger.Warn().Strs("commands", skipped).Msg("Some commands can't be registered as slash commands")
+	}
+	if len(overflow) > 0 {
+		b.Logger.Warn().Strs("commands", overflow).Int("limit", commands.MaxApplicationCommands).
+			Msg("Too many slash commands, the rest were not registered")
+	}
+
+	created, err := s.ApplicationCommandBulkOverwrite(appID, "", appCmds)
+	if err != nil {
+		b.Logger.Error().Err(err).Msg("Failed to register slash commands")
+		return
+	}
+
+	b.Logger.Info().Int("commands", len(created)).Msg("Registered slash commands")
+}
+
+func (b *Bot) handleInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		b.handleApplicationCommand(s, i)
+	case discordgo.InteractionMessageComponent:
+		b.handleComponent(s, i)
+	}
+}
+
+func (b *Bot) handleApplicationCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Slash commands are registered as guild-only, but be defensive
+	if i.GuildID == "" || i.Member == nil || i.Member.User == nil {
+		return
+	}
+	author := i.Member.User
+
+	// Acknowledge right away, commands may take longer than Discord's 3 second window
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		b.Logger.Error().Err(err).Msg("Failed to acknowledge interaction")
+		return
+	}
+
+	data := i.ApplicationCommandData()
+	cmd := b.Commands.Find(data.Name)
+
+	var args []string
+	flags := map[string]string{}
+	if cmd != nil {
+		args, flags = commands.ApplicationArgs(cmd, data.Options)
+	}
+	content := strings.TrimSpace("/" + data.Name + " " + strings.Join(args, " "))
+
+	// Build a synthetic message so commands can keep using ctx.Message
+	m := &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ID:        i.ID,
+			ChannelID: i.ChannelID,
+			GuildID:   i.GuildID,
+			Author:    author,
+			Member:    i.Member,
+			Content:   content,
+			Mentions:  b.resolveMentions(s, i.GuildID, args),
+		},
+	}
+
+	ctx := &commands.CommandContext{
+		Session:     s,
+		Message:     m,
+		Content:     strings.TrimPrefix(content, "/"),
+		Args:        args,
+		CleanArgs:   b.resolveCleanArgs(s, m.Message, args),
+		Flags:       flags,
+		Services:    b.Services,
+		Interaction: i.Interaction,
+	}
+
+	// Get guild config
+	guildConfig, err := b.DB.GetOrCreateGuild(b.ctx, i.GuildID, b.Config.DefaultPrefix)
+	if err != nil {
+		b.Logger.Error().Err(err).Str("guild", i.GuildID).Msg("Failed to get guild config")
+		guildConfig = &defaultGuildConfig
+	}
+	ctx.GuildConfig = guildConfig
+
+	if cmd == nil {
+		b.sendResponse(ctx, &commands.CommandResponse{Content: ctx.T("errors.unknown_command")})
+		return
+	}
+
+	b.dispatch(ctx, cmd)
+}
+
+// sendInteractionResponse replaces the deferred "thinking" message of a slash
+// command with the command's response, or removes it if there is none
+func (b *Bot) sendInteractionResponse(ctx *commands.CommandContext, resp *commands.CommandResponse) {
+	if resp == nil {
+		if err := ctx.Session.InteractionResponseDelete(ctx.Interaction); err != nil {
+			b.Logger.Error().Err(err).Msg("Failed to delete interaction response")
+		}
+		return
+	}
+
+	state, rows := b.Router.track(ctx, resp)
+
+	msg, err := ctx.Session.InteractionResponseEdit(ctx.Interaction, webhookEdit(resp, rows))
+	if err != nil {
+		b.Logger.Error().Err(err).Msg("Failed to send interaction response")
+		if state != nil {
+			b.Router.forget(state)
+		}
+		return
+	}
+	if state != nil {
+		state.sent(msg)
+	}
+}
+
+// webhookEdit builds the edit that replaces an interaction's message with a
+// response. rows replace the message's components.
+func webhookEdit(resp *commands.CommandResponse, rows []discordgo.MessageComponent) *discordgo.WebhookEdit {
+	edit := &discordgo.WebhookEdit{
+		Content: &resp.Content,
+	}
+
+	if rows == nil {
+		rows = []discordgo.MessageComponent{}
+	}
+	edit.Components = &rows
+
+	embeds := []*discordgo.MessageEmbed{}
+	if resp.Embed != nil {
+		// Set default color if not set
+		if resp.Embed.Color == 0 {
+			resp.Embed.Color = utils.RandomColor()
+		}
+		embeds = append(embeds, resp.Embed)
+	}
+	edit.Embeds = &embeds
+
+	if resp.File != nil {
+		edit.Files = []*discordgo.File{resp.File}
+	}
+
+	if len(resp.Files) > 0 {
+		edit.Files = resp.Files
+	}
+
+	return edit
+}
+
+// resolveMentions looks up users mentioned in slash command arguments, which
+// Discord doesn't resolve for free-form string options
+func (b *Bot) resolveMentions(s *discordgo.Session, guildID string, args []string) []*discordgo.User {
+	var mentions []*discordgo.User
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "<@") || !strings.HasSuffix(arg, ">") {
+			continue
+		}
+
+		id := strings.TrimPrefix(arg, "<@")
+		id = strings.TrimPrefix(id, "!")
+		id = strings.TrimSuffix(id, ">")
+
+		if member, err := s.State.Member(guildID, id); err == nil && member.User != nil {
+			mentions = append(mentions, member.User)
+			continue
+		}
+		if user, err := s.User(id); err == nil {
+			mentions = append(mentions, user)
+		}
+	}
+	return mentions
+}
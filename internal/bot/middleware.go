@@ -0,0 +1,126 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package bot
+
+import (
+	"github.com/dankmemer/bot/internal/commands"
+)
+
+// HandlerFunc runs a command, or the rest of the middleware chain around it
+type HandlerFunc func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, error)
+
+// Middleware wraps a HandlerFunc. It may stop the chain by returning without
+// calling next, inspect or replace the result of next, or both.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// CheckFunc decides whether a command may run. If ok is false the chain
+// stops and resp (which may be nil) is sent instead.
+type CheckFunc func(ctx *commands.CommandContext, cmd commands.Command) (resp *commands.CommandResponse, ok bool)
+
+// HookFunc is called with the result of a command after it ran
+type HookFunc func(ctx *commands.CommandContext, cmd commands.Command, resp *commands.CommandResponse, err error)
+
+// Chain wraps h in the given middleware, the first one being the outermost
+func Chain(h HandlerFunc, middleware ...Middleware) HandlerFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h
+}
+
+// Before turns a check into a middleware that runs it before the command
+func Before(check CheckFunc) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, error) {
+			if resp, ok := check(ctx, cmd); !ok {
+				return resp, nil
+			}
+			return next(ctx, cmd)
+		}
+	}
+}
+
+// After turns a hook into a middleware that runs it after the command
+func After(hook HookFunc) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, error) {
+			resp, err := next(ctx, cmd)
+			hook(ctx, cmd, resp, err)
+			return resp, err
+		}
+	}
+}
+
+// Use adds middleware to the command pipeline. It runs after the built-in
+// checks have passed and around argument parsing and the command itself.
+// Must be called before Start.
+func (b *Bot) Use(middleware ...Middleware) {
+	b.middleware = append(b.middleware, middleware...)
+}
+
+// buildAdmission composes the built-in checks that reject a command before
+// it is queued, so commands that won't run don't take a place in the executor
+func (b *Bot) buildAdmission() CheckFunc {
+	checks := []CheckFunc{
+		BlockedCheck(b.DB),
+		PremiumCheck(b.Config),
+		OwnerOnlyCheck(b.Config),
+		GuildOnlyCheck(),
+		DisabledCheck(),
+		RulesCheck(b.DB, b.Logger),
+		DonatorCheck(),
+		CooldownCheck(b.DB),
+	}
+
+	return func(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, bool) {
+		for _, check := range checks {
+			if resp, ok := check(ctx, cmd); !ok {
+				return resp, false
+			}
+		}
+		return nil, true
+	}
+}
+
+// buildPipeline composes the remaining built-in checks, the middleware added
+// with Use and the command itself
+func (b *Bot) buildPipeline() HandlerFunc {
+	var chain []Middleware
+	chain = append(chain,
+		LogCommand(b.Logger),
+		Timeout(),
+		Before(UserPermissionsCheck()),
+		Before(BotPermissionsCheck()),
+		Before(NSFWChannelCheck()),
+	)
+	if b.analytics != nil {
+		chain = append(chain, recordUsage(b.analytics))
+	}
+	chain = append(chain, b.middleware...)
+	chain = append(chain,
+		ParseArgs(),
+		Before(ClaimCooldown(b.DB, b.Logger)),
+		Defer(b.Logger),
+		After(SetCooldown(b.DB, b.Logger)),
+	)
+
+	return Chain(runCommand, chain...)
+}
+
+func runCommand(ctx *commands.CommandContext, cmd commands.Command) (*commands.CommandResponse, error) {
+	return cmd.Run(ctx)
+}
@@ -0,0 +1,55 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package bot
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dankmemer/bot/internal/plugin"
+)
+
+// LoadPlugins starts the plugins in the config and registers their commands.
+// Plugins that fail to start are skipped and reported in the returned error.
+// Call it after RegisterCommands so plugins can't take native triggers.
+func (b *Bot) LoadPlugins() error {
+	var errs []error
+
+	for _, cfg := range b.Config.Plugins {
+		sup := plugin.NewSupervisor(cfg, b.Logger)
+		if err := sup.Start(b.ctx); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", sup.Name, err))
+			continue
+		}
+		b.plugins = append(b.plugins, sup)
+
+		for _, cmd := range plugin.NewCommands(sup) {
+			if err := b.Commands.Register(cmd); err != nil {
+				errs = append(errs, fmt.Errorf("plugin %s: %w", sup.Name, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// stopPlugins stops every running plugin
+func (b *Bot) stopPlugins() {
+	for _, sup := range b.plugins {
+		sup.Stop()
+	}
+}
@@ -0,0 +1,171 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package bot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/dankmemer/bot/internal/commands"
+)
+
+// typingInterval is how often the typing indicator is renewed, Discord shows
+// it for 10 seconds
+const typingInterval = 8 * time.Second
+
+// finisher is implemented by the bot's responders. finish stops any further
+// updates and returns the placeholder the response should replace.
+type finisher interface {
+	finish() *discordgo.Message
+}
+
+// newResponder returns the responder for a command invocation
+func newResponder(ctx *commands.CommandContext) commands.Responder {
+	if ctx.Interaction != nil {
+		return &interactionResponder{session: ctx.Session, interaction: ctx.Interaction}
+	}
+	return &messageResponder{session: ctx.Session, channelID: ctx.Message.ChannelID}
+}
+
+// finishResponder finishes a context's responder, returning its placeholder
+func finishResponder(ctx *commands.CommandContext) *discordgo.Message {
+	if f, ok := ctx.Responder.(finisher); ok {
+		return f.finish()
+	}
+	return nil
+}
+
+// messageResponder sends and edits a placeholder for prefixed commands
+type messageResponder struct {
+	session   *discordgo.Session
+	channelID string
+
+	mu          sync.Mutex
+	placeholder *discordgo.Message
+	stopTyping  chan struct{}
+	done        bool
+}
+
+func (r *messageResponder) Defer(placeholder string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.done || r.placeholder != nil {
+		return nil
+	}
+	msg, err := r.session.ChannelMessageSend(r.channelID, placeholder)
+	if err != nil {
+		return err
+	}
+	r.placeholder = msg
+	return nil
+}
+
+func (r *messageResponder) Typing() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.done || r.stopTyping != nil {
+		return
+	}
+	r.stopTyping = make(chan struct{})
+	go r.keepTyping(r.stopTyping)
+}
+
+// keepTyping renews the typing indicator until stop is closed
+func (r *messageResponder) keepTyping(stop chan struct{}) {
+	ticker := time.NewTicker(typingInterval)
+	defer ticker.Stop()
+
+	for {
+		_ = r.session.ChannelTyping(r.channelID)
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *messageResponder) Progress(content string) error {
+	// Hold the lock while editing so an update can't land after the response
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.done {
+		return nil
+	}
+	if r.placeholder == nil {
+		msg, err := r.session.ChannelMessageSend(r.channelID, content)
+		if err != nil {
+			return err
+		}
+		r.placeholder = msg
+		return nil
+	}
+	_, err := r.session.ChannelMessageEdit(r.placeholder.ChannelID, r.placeholder.ID, content)
+	return err
+}
+
+func (r *messageResponder) finish() *discordgo.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.done = true
+	if r.stopTyping != nil {
+		close(r.stopTyping)
+		r.stopTyping = nil
+	}
+	return r.placeholder
+}
+
+// interactionResponder updates the deferred response of a slash command
+type interactionResponder struct {
+	session     *discordgo.Session
+	interaction *discordgo.Interaction
+
+	mu   sync.Mutex
+	done bool
+}
+
+// Defer does nothing, slash commands are deferred when they arrive
+func (r *interactionResponder) Defer(placeholder string) error {
+	return nil
+}
+
+// Typing does nothing, Discord already shows that the bot is thinking
+func (r *interactionResponder) Typing() {}
+
+func (r *interactionResponder) Progress(content string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.done {
+		return nil
+	}
+	_, err := r.session.InteractionResponseEdit(r.interaction, &discordgo.WebhookEdit{Content: &content})
+	return err
+}
+
+func (r *interactionResponder) finish() *discordgo.Message {
+	r.mu.Lock()
+	r.done = true
+	r.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,51 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"sort"
+	"strings"
+)
+
+// MaxAliases is how many aliases a guild can have
+const MaxAliases = 50
+
+// ExpandAlias replaces a guild alias at the start of command content with the
+// command it stands for, e.g. "m --random" becomes "meme --random". Content
+// that doesn't start with an alias is returned as is.
+func ExpandAlias(aliases map[string]string, content string) string {
+	word, rest := CutWord(content)
+	target, ok := aliases[strings.ToLower(word)]
+	if !ok {
+		return content
+	}
+	return strings.TrimSpace(target + " " + rest)
+}
+
+// AliasesOf returns the guild aliases that run a command, given its canonical
+// path like "config prefix set", sorted by name
+func AliasesOf(r *Registry, aliases map[string]string, path string) []string {
+	var names []string
+	for alias, target := range aliases {
+		words, _ := Tokenize(target)
+		if _, resolved, _ := r.Resolve(words); resolved == path {
+			names = append(names, alias)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,253 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// MaxApplicationCommands is Discord's limit on global chat input commands
+const MaxApplicationCommands = 100
+
+// ApplicationArgsOption is the name of the free-form option that carries
+// the raw arguments of a slash command
+const ApplicationArgsOption = "args"
+
+// Slash command names must be lowercase and 1-32 characters
+var applicationNameRegex = regexp.MustCompile(`^[-_\p{Ll}\p{N}]{1,32}$`)
+
+// ApplicationCommand builds a Discord slash command from a command's props.
+// Returns nil if the command can't be exposed as a slash command.
+func ApplicationCommand(cmd Command) *discordgo.ApplicationCommand {
+	props := cmd.Props()
+	if props.OwnerOnly || len(props.Triggers) == 0 {
+		return nil
+	}
+
+	name := strings.ToLower(props.Triggers[0])
+	if !applicationNameRegex.MatchString(name) {
+		return nil
+	}
+
+	nsfw := props.IsNSFW
+	return &discordgo.ApplicationCommand{
+		Name:        name,
+		Description: applicationDescription(props.Description),
+		NSFW:        &nsfw,
+		Contexts:    &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild},
+		Options:     applicationCommandOptions(cmd, 0),
+	}
+}
+
+// applicationCommandOptions returns the options of a command, mapping groups
+// to Discord subcommands and subcommand groups. Discord only allows two
+// levels of nesting, so groups nested deeper take free-form arguments.
+func applicationCommandOptions(cmd Command, depth int) []*discordgo.ApplicationCommandOption {
+	group, ok := cmd.(*Group)
+	if !ok || depth >= 2 {
+		return applicationOptions(cmd.Props())
+	}
+
+	var options []*discordgo.ApplicationCommandOption
+	for _, sub := range group.Subcommands {
+		props := sub.Props()
+		name := strings.ToLower(props.Triggers[0])
+		if props.OwnerOnly || !applicationNameRegex.MatchString(name) {
+			continue
+		}
+
+		opt := &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        name,
+			Description: applicationDescription(props.Description),
+			Options:     applicationCommandOptions(sub, depth+1),
+		}
+		if _, isGroup := sub.(*Group); isGroup && depth == 0 {
+			opt.Type = discordgo.ApplicationCommandOptionSubCommandGroup
+		}
+		options = append(options, opt)
+	}
+	return options
+}
+
+// applicationOptions maps the argument schema to slash command options.
+// Commands without a schema get a single free-form option.
+func applicationOptions(props CommandProps) []*discordgo.ApplicationCommandOption {
+	if len(props.Args) == 0 {
+		usage := strings.TrimSpace(strings.ReplaceAll(Usage(props), "{command}", ""))
+		if usage == "" {
+			usage = "Arguments for this command"
+		}
+		return []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        ApplicationArgsOption,
+				Description: applicationDescription(usage),
+			},
+		}
+	}
+
+	options := make([]*discordgo.ApplicationCommandOption, 0, len(props.Args))
+	for _, arg := range props.Args {
+		description := arg.Description
+		if description == "" {
+			description = arg.Name
+		}
+
+		opt := &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        strings.ToLower(arg.Name),
+			Description: applicationDescription(description),
+			Required:    !arg.Optional,
+		}
+
+		switch arg.Type {
+		case ArgFlag:
+			opt.Type = discordgo.ApplicationCommandOptionBoolean
+			opt.Required = false
+		case ArgUser, ArgMember:
+			opt.Type = discordgo.ApplicationCommandOptionUser
+		case ArgChannel:
+			opt.Type = discordgo.ApplicationCommandOptionChannel
+		case ArgInteger:
+			opt.Type = discordgo.ApplicationCommandOptionInteger
+			if arg.Min != 0 || arg.Max != 0 {
+				minValue := float64(arg.Min)
+				opt.MinValue = &minValue
+				opt.MaxValue = float64(arg.Max)
+			}
+		case ArgChoice:
+			for _, choice := range arg.Choices {
+				opt.Choices = append(opt.Choices, &discordgo.ApplicationCommandOptionChoice{
+					Name:  choice,
+					Value: choice,
+				})
+			}
+		}
+
+		options = append(options, opt)
+	}
+	return options
+}
+
+// ApplicationArgs converts slash command options back into the arguments
+// and flags a prefixed invocation would have produced. Subcommands become
+// leading arguments, so groups dispatch them like prefixed invocations.
+func ApplicationArgs(cmd Command, options []*discordgo.ApplicationCommandInteractionDataOption) (args []string, flags map[string]string) {
+	if group, ok := cmd.(*Group); ok && len(options) == 1 {
+		opt := options[0]
+		if opt.Type == discordgo.ApplicationCommandOptionSubCommand || opt.Type == discordgo.ApplicationCommandOptionSubCommandGroup {
+			sub := group.Find(opt.Name)
+			if sub == nil {
+				return []string{opt.Name}, map[string]string{}
+			}
+			args, flags = ApplicationArgs(sub, opt.Options)
+			return append([]string{opt.Name}, args...), flags
+		}
+	}
+
+	props := cmd.Props()
+	byName := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		byName[opt.Name] = opt
+	}
+
+	if len(props.Args) == 0 {
+		if opt, ok := byName[ApplicationArgsOption]; ok {
+			return Tokenize(opt.StringValue())
+		}
+		return nil, map[string]string{}
+	}
+
+	flags = map[string]string{}
+	declared := FlagNames(props)
+	for _, arg := range props.Args {
+		opt, ok := byName[strings.ToLower(arg.Name)]
+		if !ok {
+			continue
+		}
+
+		switch opt.Type {
+		case discordgo.ApplicationCommandOptionBoolean:
+			if opt.BoolValue() {
+				flags[strings.ToLower(arg.Name)] = ""
+			}
+		case discordgo.ApplicationCommandOptionUser:
+			args = append(args, "<@"+fmt.Sprint(opt.Value)+">")
+		case discordgo.ApplicationCommandOptionChannel:
+			args = append(args, "<#"+fmt.Sprint(opt.Value)+">")
+		case discordgo.ApplicationCommandOptionInteger:
+			args = append(args, strconv.FormatInt(opt.IntValue(), 10))
+		default:
+			tokens, optFlags := Tokenize(opt.StringValue(), declared...)
+			args = append(args, tokens...)
+			for name, value := range optFlags {
+				flags[name] = value
+			}
+		}
+	}
+	return args, flags
+}
+
+// ApplicationCommands builds slash commands for every registered command,
+// sorted by name and capped at Discord's limit. Commands that can't be
+// exposed are returned by name in skipped, and those past the limit in
+// overflow.
+func (r *Registry) ApplicationCommands() (result []*discordgo.ApplicationCommand, skipped, overflow []string) {
+	for _, cmd := range r.GetAll() {
+		appCmd := ApplicationCommand(cmd)
+		if appCmd == nil {
+			if props := cmd.Props(); !props.OwnerOnly && len(props.Triggers) > 0 {
+				skipped = append(skipped, props.Triggers[0])
+			}
+			continue
+		}
+		result = append(result, appCmd)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	if len(result) > MaxApplicationCommands {
+		for _, appCmd := range result[MaxApplicationCommands:] {
+			overflow = append(overflow, appCmd.Name)
+		}
+		result = result[:MaxApplicationCommands]
+	}
+
+	return result, skipped, overflow
+}
+
+// Descriptions must be between 1 and 100 characters
+func applicationDescription(description string) string {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return "No description"
+	}
+	if len(description) > 100 {
+		return utils.TruncateString(description, 100)
+	}
+	return description
+}
@@ -0,0 +1,357 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ArgType is the type of a declared command argument
+type ArgType int
+
+const (
+	ArgUser     ArgType = iota // A mentioned user or user ID
+	ArgMember                  // A member of the current guild
+	ArgChannel                 // A mentioned channel or channel ID
+	ArgInteger                 // A whole number, optionally bounded by Min and Max
+	ArgDuration                // A duration like 30s, 5m or 1h
+	ArgChoice                  // One of Choices (case-insensitive)
+	ArgText                    // The rest of the message as typed, must be last but for flags
+	ArgWord                    // A single word
+	ArgFlag                    // A --flag option, read with HasFlag and Flag
+)
+
+// Arg declares a typed command argument. Messages may use {prefix} and {usage}.
+type Arg struct {
+	Name        string   // Name shown in usage and used to look up the value
+	Type        ArgType  // Type the argument is parsed as
+	Description string   // Short description for slash command options
+	Optional    bool     // Whether the argument may be omitted
+	Min, Max    int64    // Inclusive bounds for ArgInteger (ignored if both are 0)
+	Choices     []string // Allowed values for ArgChoice
+	Missing     string   // Custom message when a required argument is missing
+	Invalid     string   // Custom message when the argument can't be parsed
+}
+
+// ArgError is returned when arguments don't match a command's schema.
+// Its message is meant to be shown to the user.
+type ArgError struct {
+	Arg     Arg
+	Message string
+}
+
+func (e *ArgError) Error() string {
+	return e.Message
+}
+
+// Usage returns the usage pattern of a command, generated from its argument
+// schema when no explicit usage is set
+func Usage(props CommandProps) string {
+	if props.Usage != "" {
+		return props.Usage
+	}
+	if len(props.Args) == 0 {
+		return "{command}"
+	}
+	return "{command} " + FormatArgs(props.Args)
+}
+
+// FormatArgs renders an argument schema like "<user> [count]"
+func FormatArgs(args []Arg) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		name := arg.Name
+		switch arg.Type {
+		case ArgUser, ArgMember:
+			name = "@" + name
+		case ArgChannel:
+			name = "#" + name
+		case ArgChoice:
+			name = strings.Join(arg.Choices, "|")
+		case ArgText:
+			name += "..."
+		case ArgFlag:
+			parts = append(parts, "[--"+name+"]")
+			continue
+		}
+
+		if arg.Optional {
+			parts = append(parts, "["+name+"]")
+		} else {
+			parts = append(parts, "<"+name+">")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseArgs parses ctx.Args against the command's argument schema and stores
+// the values on the context. Returns an *ArgError on invalid input, including
+// tokens left over after the last argument.
+func ParseArgs(ctx *CommandContext, props CommandProps) error {
+	ctx.values = make(map[string]interface{}, len(props.Args))
+	if len(props.Args) == 0 {
+		return nil
+	}
+
+	prefix := ""
+	if ctx.GuildConfig != nil {
+		prefix = ctx.GuildConfig.Prefix
+	}
+	path := ctx.Command
+	if path == "" {
+		path = props.Triggers[0]
+	}
+	usage := strings.ReplaceAll(LocalizedUsage(props, path, ctx.Locale()), "{command}", prefix+" "+path)
+
+	tokens := ctx.Args
+	for _, arg := range props.Args {
+		if arg.Type == ArgFlag {
+			continue
+		}
+		if arg.Type == ArgText {
+			if text := textArg(ctx, props, len(ctx.Args)-len(tokens), tokens); text != "" {
+				ctx.values[arg.Name] = text
+				tokens = nil
+				continue
+			}
+		}
+
+		if len(tokens) == 0 {
+			if arg.Optional {
+				continue
+			}
+			msg := localizedArgMessage(arg, path, "missing", arg.Missing, ctx.Locale())
+			if msg == "" {
+				msg = ctx.T("args.missing", arg.Name, usage)
+			}
+			return newArgError(arg, msg, prefix, usage)
+		}
+
+		value, msg := parseArg(ctx, arg, tokens[0])
+		if msg != "" {
+			if invalid := localizedArgMessage(arg, path, "invalid", arg.Invalid, ctx.Locale()); invalid != "" {
+				msg = invalid
+			}
+			return newArgError(arg, msg, prefix, usage)
+		}
+
+		ctx.values[arg.Name] = value
+		tokens = tokens[1:]
+	}
+
+	if len(tokens) > 0 {
+		last := props.Args[len(props.Args)-1]
+		return newArgError(last, ctx.T("args.extra", strings.Join(tokens, " "), usage), prefix, usage)
+	}
+	return nil
+}
+
+// textArg returns the message after its first consumed arguments as the user
+// typed it, keeping quotes and flags. Contexts without content fall back to
+// the remaining tokens.
+func textArg(ctx *CommandContext, props CommandProps, consumed int, tokens []string) string {
+	if ctx.Content == "" {
+		return strings.Join(tokens, " ")
+	}
+	return strings.TrimSpace(TextAfter(ctx.RawArgs(), consumed, FlagNames(props)...))
+}
+
+func newArgError(arg Arg, msg, prefix, usage string) *ArgError {
+	msg = strings.ReplaceAll(msg, "{prefix}", prefix)
+	msg = strings.ReplaceAll(msg, "{usage}", usage)
+	return &ArgError{Arg: arg, Message: msg}
+}
+
+// parseArg converts a single token, returning a user-facing message on failure
+func parseArg(ctx *CommandContext, arg Arg, token string) (interface{}, string) {
+	switch arg.Type {
+	case ArgUser:
+		if user := resolveUser(ctx, token); user != nil {
+			return user, ""
+		}
+		return nil, ctx.T("args.user", token)
+
+	case ArgMember:
+		if member := resolveMember(ctx, token); member != nil {
+			return member, ""
+		}
+		return nil, ctx.T("args.member", token)
+
+	case ArgChannel:
+		if channel := resolveChannel(ctx, token); channel != nil {
+			return channel, ""
+		}
+		return nil, ctx.T("args.channel", token)
+
+	case ArgInteger:
+		n, err := strconv.ParseInt(token, 10, 64)
+		if err != nil {
+			return nil, ctx.T("args.integer", token)
+		}
+		if (arg.Min != 0 || arg.Max != 0) && (n < arg.Min || n > arg.Max) {
+			return nil, ctx.T("args.range", arg.Name, arg.Min, arg.Max)
+		}
+		return n, ""
+
+	case ArgDuration:
+		d, err := time.ParseDuration(token)
+		if err != nil || d <= 0 {
+			return nil, ctx.T("args.duration", token)
+		}
+		return d, ""
+
+	case ArgChoice:
+		lower := strings.ToLower(token)
+		for _, choice := range arg.Choices {
+			if strings.ToLower(choice) == lower {
+				return choice, ""
+			}
+		}
+		return nil, ctx.T("args.choice", token, "`"+strings.Join(arg.Choices, "`, `")+"`")
+	}
+
+	return token, ""
+}
+
+// parseSnowflake extracts an ID from a mention like <@!123> or <#123>, or a raw ID
+func parseSnowflake(token, prefix string) string {
+	if strings.HasPrefix(token, prefix) && strings.HasSuffix(token, ">") {
+		token = strings.TrimSuffix(strings.TrimPrefix(token, prefix), ">")
+		token = strings.TrimPrefix(token, "!")
+	}
+	if token == "" {
+		return ""
+	}
+	for _, r := range token {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return token
+}
+
+func resolveUser(ctx *CommandContext, token string) *discordgo.User {
+	id := parseSnowflake(token, "<@")
+	if id == "" {
+		return nil
+	}
+
+	for _, user := range ctx.Message.Mentions {
+		if user.ID == id {
+			return user
+		}
+	}
+
+	if member := resolveMember(ctx, token); member != nil && member.User != nil {
+		return member.User
+	}
+
+	user, err := ctx.Session.User(id)
+	if err != nil {
+		return nil
+	}
+	return user
+}
+
+func resolveMember(ctx *CommandContext, token string) *discordgo.Member {
+	id := parseSnowflake(token, "<@")
+	if id == "" || ctx.Message.GuildID == "" {
+		return nil
+	}
+
+	member, err := ctx.Session.State.Member(ctx.Message.GuildID, id)
+	if err == nil {
+		return member
+	}
+
+	member, err = ctx.Session.GuildMember(ctx.Message.GuildID, id)
+	if err != nil {
+		return nil
+	}
+	return member
+}
+
+func resolveChannel(ctx *CommandContext, token string) *discordgo.Channel {
+	id := parseSnowflake(token, "<#")
+	if id == "" {
+		return nil
+	}
+
+	channel, err := ctx.Session.State.Channel(id)
+	if err != nil {
+		channel, err = ctx.Session.Channel(id)
+		if err != nil {
+			return nil
+		}
+	}
+
+	// Don't leak channels from other guilds
+	if channel.GuildID != ctx.Message.GuildID {
+		return nil
+	}
+	return channel
+}
+
+// HasArg reports whether an argument was provided
+func (ctx *CommandContext) HasArg(name string) bool {
+	_, ok := ctx.values[name]
+	return ok
+}
+
+// ArgUser returns a parsed ArgUser argument, or nil if it wasn't provided
+func (ctx *CommandContext) ArgUser(name string) *discordgo.User {
+	user, _ := ctx.values[name].(*discordgo.User)
+	return user
+}
+
+// ArgMember returns a parsed ArgMember argument, or nil if it wasn't provided
+func (ctx *CommandContext) ArgMember(name string) *discordgo.Member {
+	member, _ := ctx.values[name].(*discordgo.Member)
+	return member
+}
+
+// ArgChannel returns a parsed ArgChannel argument, or nil if it wasn't provided
+func (ctx *CommandContext) ArgChannel(name string) *discordgo.Channel {
+	channel, _ := ctx.values[name].(*discordgo.Channel)
+	return channel
+}
+
+// ArgInt returns a parsed ArgInteger argument, or def if it wasn't provided
+func (ctx *CommandContext) ArgInt(name string, def int64) int64 {
+	if n, ok := ctx.values[name].(int64); ok {
+		return n
+	}
+	return def
+}
+
+// ArgDuration returns a parsed ArgDuration argument, or def if it wasn't provided
+func (ctx *CommandContext) ArgDuration(name string, def time.Duration) time.Duration {
+	if d, ok := ctx.values[name].(time.Duration); ok {
+		return d
+	}
+	return def
+}
+
+// ArgString returns a parsed ArgChoice, ArgText or ArgWord argument, or "" if it wasn't provided
+func (ctx *CommandContext) ArgString(name string) string {
+	s, _ := ctx.values[name].(string)
+	return s
+}
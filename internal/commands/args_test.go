@@ -0,0 +1,209 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+)
+
+// echo replies with its arguments, to check how they are parsed
+var echo = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers: []string{"echo"},
+		Args: []commands.Arg{
+			{Name: "to", Type: commands.ArgWord},
+			{Name: "loud", Type: commands.ArgFlag},
+			{Name: "text", Type: commands.ArgText},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		return &commands.CommandResponse{Content: ctx.ArgString("text")}, nil
+	},
+}
+
+func TestArgTextKeepsRawText(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{`echo me hello world`, `hello world`},
+		{`echo "you two" hello  world`, `hello  world`},
+		{`echo me he said "hi" to me`, `he said "hi" to me`},
+		{`echo me "quoted"`, `"quoted"`},
+		{`echo me use -- to stop flags`, `use -- to stop flags`},
+		{`echo me --bots are cool`, `--bots are cool`},
+		{`echo --loud me hi`, `hi`},
+		{`echo me --loud`, `--loud`},
+		{"echo me line one\nline two", "line one\nline two"},
+		{"echo me `code  spaced`", "`code  spaced`"},
+	}
+
+	h := commandtest.New()
+	for _, tt := range tests {
+		resp, err := h.Run(echo, tt.content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Content != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.content, resp.Content, tt.want)
+		}
+	}
+}
+
+func TestArgTextMissing(t *testing.T) {
+	h := commandtest.New()
+	resp, err := h.Run(echo, "echo me")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content == "" {
+		t.Fatal("got no error for missing text")
+	}
+}
+
+// count declares a single number, so anything after it is left over
+var count = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers: []string{"count"},
+		Args: []commands.Arg{
+			{Name: "n", Type: commands.ArgInteger, Min: 1, Max: 10, Optional: true},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		return &commands.CommandResponse{Content: "ok"}, nil
+	},
+}
+
+func TestParseArgsRejectsExtraTokens(t *testing.T) {
+	h := commandtest.New()
+
+	ctx := h.Context("count 5 extra words")
+	err := commands.ParseArgs(ctx, count.Props())
+	var argErr *commands.ArgError
+	if !errors.As(err, &argErr) {
+		t.Fatalf("got %v, want an *ArgError", err)
+	}
+	if !strings.Contains(argErr.Message, "`extra words`") {
+		t.Errorf("got %q, want the extra tokens named", argErr.Message)
+	}
+
+	if resp, err := h.Run(count, "count 5"); err != nil || resp.Content != "ok" {
+		t.Errorf("count 5: got %v, %v", resp, err)
+	}
+}
+
+// blame takes a number and, optionally, free text after it
+var blame = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers: []string{"blame"},
+		Args: []commands.Arg{
+			{Name: "n", Type: commands.ArgInteger},
+			{Name: "reason", Type: commands.ArgText, Optional: true},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		return &commands.CommandResponse{Content: ctx.ArgString("reason")}, nil
+	},
+}
+
+func TestOptionalArgTextTakesTrailingWords(t *testing.T) {
+	h := commandtest.New()
+
+	resp, err := h.Run(blame, "blame 5 for stealing my memes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "for stealing my memes" {
+		t.Errorf("got %q, want the trailing words", resp.Content)
+	}
+
+	resp, err = h.Run(blame, "blame 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "" {
+		t.Errorf("got %q, want no reason", resp.Content)
+	}
+}
+
+func TestArgErrorsAreLocalized(t *testing.T) {
+	h := commandtest.New()
+	if err := h.Store.UpdateGuildLocale(context.Background(), commandtest.GuildID, "es"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"count five", "no es un número válido"},
+		{"count 50", "tiene que estar entre 1 y 10"},
+		{"count 5 6", "No sé qué hacer con `6`"},
+	}
+	for _, tt := range tests {
+		resp, err := h.Run(count, tt.content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(resp.Content, tt.want) {
+			t.Errorf("%q: got %q, want %q", tt.content, resp.Content, tt.want)
+		}
+	}
+}
+
+func TestUndeclaredFlagsStayArguments(t *testing.T) {
+	h := commandtest.New()
+
+	resp, err := h.Run(blame, "blame 5 use --force")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "use --force" {
+		t.Errorf("got %q, want the --word kept in the text", resp.Content)
+	}
+
+	ctx := h.Context("count --force")
+	if err := commands.ParseArgs(ctx, count.Props()); err == nil {
+		t.Error("undeclared flag was dropped instead of parsed as the count")
+	}
+}
+
+func TestDeclaredFlags(t *testing.T) {
+	h := commandtest.New()
+
+	ctx := h.Context("echo me --loud hi")
+	if len(ctx.Flags) != 0 {
+		t.Errorf("got flags %v for a command outside the registry", ctx.Flags)
+	}
+
+	if err := h.Registry.Register(echo); err != nil {
+		t.Fatal(err)
+	}
+	ctx = h.Context("echo me --loud hi")
+	if !ctx.HasFlag("loud") || strings.Join(ctx.Args, " ") != "me hi" {
+		t.Errorf("got args %q and flags %v, want --loud taken out", ctx.Args, ctx.Flags)
+	}
+	if usage := commands.Usage(echo.Props()); usage != "{command} <to> [--loud] <text...>" {
+		t.Errorf("got usage %q", usage)
+	}
+}
@@ -36,18 +36,11 @@ func (c *BaseCommand) Props() CommandProps {
 	if props.Cooldown == 0 {
 		props.Cooldown = 3000
 	}
-	if props.Usage == "" {
-		props.Usage = "{command}"
-	}
+	props.Usage = Usage(props)
 
 	return props
 }
 
 func (c *BaseCommand) Run(ctx *CommandContext) (*CommandResponse, error) {
-	// Check missing args
-	if c.Properties.MissingArgs != "" && len(ctx.Args) == 0 {
-		return &CommandResponse{Content: c.Properties.MissingArgs}, nil
-	}
-
 	return c.Handler(ctx)
 }
@@ -0,0 +1,71 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/dankmemer/bot/internal/database"
+)
+
+// CategoryKey normalizes a category name, so "Fun Commands", "fun commands"
+// and "fun" are the same category. Guild settings store categories this way.
+func CategoryKey(category string) string {
+	key := strings.ToLower(strings.TrimSpace(category))
+	return strings.TrimSpace(strings.TrimSuffix(key, " commands"))
+}
+
+// FindCategory returns the normalized name of the category called name, or
+// "" if there's no such category. Custom commands are in "custom".
+func (r *Registry) FindCategory(name string) string {
+	key := CategoryKey(name)
+	if key == "" {
+		return ""
+	}
+	if key == CategoryKey(CustomCategory) {
+		return key
+	}
+	for _, category := range r.GetCategories() {
+		if CategoryKey(category) == key {
+			return key
+		}
+	}
+	return ""
+}
+
+// GetByCategoryKey returns the commands in a normalized category, which may
+// span several category names like "Utility" and "Utility Commands"
+func (r *Registry) GetByCategoryKey(key string) []Command {
+	var result []Command
+	for _, category := range r.GetCategories() {
+		if CategoryKey(category) == key {
+			result = append(result, r.GetByCategory(category)...)
+		}
+	}
+	return result
+}
+
+// IsDisabled reports whether a guild disabled a command, by name or through
+// its category
+func IsDisabled(cfg *database.GuildConfig, props CommandProps) bool {
+	if cfg == nil {
+		return false
+	}
+	return slices.Contains(cfg.DisabledCommands, props.Triggers[0]) ||
+		slices.Contains(cfg.DisabledCategories, CategoryKey(props.Category))
+}
@@ -0,0 +1,94 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/database"
+)
+
+func TestCategoryKey(t *testing.T) {
+	tests := map[string]string{
+		"Fun":                "fun",
+		"Fun Commands":       "fun",
+		" fun commands ":     "fun",
+		"Image Manipulation": "image manipulation",
+		"Commands":           "commands",
+		"":                   "",
+	}
+	for category, want := range tests {
+		if got := commands.CategoryKey(category); got != want {
+			t.Errorf("CategoryKey(%q) = %q, want %q", category, got, want)
+		}
+	}
+}
+
+func TestFindCategory(t *testing.T) {
+	registry := commands.NewRegistry()
+	for _, props := range []commands.CommandProps{
+		{Triggers: []string{"joke"}, Category: "Fun"},
+		{Triggers: []string{"roast"}, Category: "Fun Commands"},
+		{Triggers: []string{"ping"}, Category: "Utility"},
+	} {
+		if err := registry.Register(&commands.BaseCommand{Properties: props}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]string{
+		"fun":          "fun",
+		"FUN COMMANDS": "fun",
+		"custom":       "custom",
+		"nsfw":         "",
+		"":             "",
+	}
+	for name, want := range tests {
+		if got := registry.FindCategory(name); got != want {
+			t.Errorf("FindCategory(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if n := len(registry.GetByCategoryKey("fun")); n != 2 {
+		t.Errorf("fun has %d commands, want 2", n)
+	}
+}
+
+func TestIsDisabled(t *testing.T) {
+	cfg := &database.GuildConfig{
+		DisabledCommands:   []string{"ping"},
+		DisabledCategories: []string{"fun"},
+	}
+
+	tests := []struct {
+		props commands.CommandProps
+		want  bool
+	}{
+		{commands.CommandProps{Triggers: []string{"ping"}, Category: "Utility"}, true},
+		{commands.CommandProps{Triggers: []string{"help"}, Category: "Utility"}, false},
+		{commands.CommandProps{Triggers: []string{"roast"}, Category: "Fun Commands"}, true},
+	}
+	for _, tt := range tests {
+		if got := commands.IsDisabled(cfg, tt.props); got != tt.want {
+			t.Errorf("IsDisabled(%s) = %v, want %v", tt.props.Triggers[0], got, tt.want)
+		}
+	}
+	if commands.IsDisabled(nil, tests[0].props) {
+		t.Error("disabled without a guild config")
+	}
+}
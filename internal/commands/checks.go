@@ -0,0 +1,54 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// CheckGuildOnly returns an error response if the command is used in DMs
+// without DMAllowed, or nil if it may run here
+func CheckGuildOnly(ctx *CommandContext, props CommandProps) *CommandResponse {
+	if ctx.Message.GuildID != "" || props.DMAllowed {
+		return nil
+	}
+	return &CommandResponse{Content: ctx.T("dm.guild_only")}
+}
+
+// CheckNSFW returns an error response if an NSFW command is used outside an
+// NSFW channel, or nil if it may run here
+func CheckNSFW(ctx *CommandContext, props CommandProps) *CommandResponse {
+	if !props.IsNSFW {
+		return nil
+	}
+
+	channel, err := ctx.Session.State.Channel(ctx.Message.ChannelID)
+	if err != nil {
+		channel, err = ctx.Session.Channel(ctx.Message.ChannelID)
+	}
+	if err == nil && channel.NSFW {
+		return nil
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       ctx.T("nsfw.title"),
+		Description: ctx.T("nsfw.description"),
+		Color:       utils.RandomColor(),
+	}
+	return &CommandResponse{Embed: embed}
+}
@@ -0,0 +1,740 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commandtest
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dankmemer/bot/internal/database"
+	"github.com/dankmemer/bot/internal/external"
+)
+
+// FakeStore is an in-memory commands.Store
+type FakeStore struct {
+	mu     sync.Mutex
+	coins  map[string]int64
+	guilds map[string]*database.GuildConfig
+	custom map[string]map[string]database.CustomCommand // Guild ID → name → command
+	usage  []database.CommandUsage
+	users  map[string]string // User ID → personal prefix
+	rules  []database.CommandRule
+	ruleID int64
+	donors map[string]database.Donator
+	cools  map[string]time.Time // User ID + " " + command → expiry
+	Stats  database.BotStats
+	Err    error // Returned by every method when set
+}
+
+// NewFakeStore creates an empty store
+func NewFakeStore() *FakeStore {
+	return &FakeStore{
+		coins:  make(map[string]int64),
+		guilds: make(map[string]*database.GuildConfig),
+		custom: make(map[string]map[string]database.CustomCommand),
+		users:  make(map[string]string),
+		donors: make(map[string]database.Donator),
+		cools:  make(map[string]time.Time),
+	}
+}
+
+// Guild returns a copy of a guild's config, creating the default one
+func (s *FakeStore) Guild(guildID string) database.GuildConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cfg := *s.guild(guildID)
+	cfg.Prefixes = append([]string{}, cfg.Prefixes...)
+	cfg.DisabledCommands = append([]string{}, cfg.DisabledCommands...)
+	cfg.DisabledCategories = append([]string{}, cfg.DisabledCategories...)
+	cfg.Aliases = maps.Clone(cfg.Aliases)
+	return cfg
+}
+
+// SetGuild replaces a guild's config
+func (s *FakeStore) SetGuild(cfg database.GuildConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.guilds[cfg.ID] = &cfg
+}
+
+func (s *FakeStore) guild(guildID string) *database.GuildConfig {
+	cfg, ok := s.guilds[guildID]
+	if !ok {
+		cfg = &database.GuildConfig{
+			ID:                 guildID,
+			Prefix:             Prefix,
+			Prefixes:           []string{Prefix},
+			DisabledCommands:   []string{},
+			DisabledCategories: []string{},
+			Aliases:            map[string]string{},
+			Locale:             "en",
+		}
+		s.guilds[guildID] = cfg
+	}
+	return cfg
+}
+
+// SetCoins sets a user's balance
+func (s *FakeStore) SetCoins(userID string, amount int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.coins[userID] = amount
+}
+
+func (s *FakeStore) GetCoins(ctx context.Context, userID string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.coins[userID], s.Err
+}
+
+func (s *FakeStore) AddCoins(ctx context.Context, userID string, amount int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.coins[userID] += amount
+	return nil
+}
+
+func (s *FakeStore) GetStats(ctx context.Context) (*database.BotStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.Stats
+	return &stats, s.Err
+}
+
+func (s *FakeStore) UpdateGuildPrefixes(ctx context.Context, guildID string, prefixes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	if len(prefixes) == 0 {
+		return fmt.Errorf("a guild needs at least one prefix")
+	}
+	cfg := s.guild(guildID)
+	cfg.Prefix = prefixes[0]
+	cfg.Prefixes = append([]string{}, prefixes...)
+	return nil
+}
+
+func (s *FakeStore) GetUserPrefix(ctx context.Context, userID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return "", s.Err
+	}
+	return s.users[userID], nil
+}
+
+func (s *FakeStore) SetUserPrefix(ctx context.Context, userID, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	if prefix == "" {
+		delete(s.users, userID)
+	} else {
+		s.users[userID] = prefix
+	}
+	return nil
+}
+
+func (s *FakeStore) UpdateGuildSuggestions(ctx context.Context, guildID string, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.guild(guildID).Suggestions = enabled
+	return nil
+}
+
+func (s *FakeStore) DisableCommands(ctx context.Context, guildID string, commands []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	cfg := s.guild(guildID)
+	cfg.DisabledCommands = append(cfg.DisabledCommands, commands...)
+	return nil
+}
+
+func (s *FakeStore) EnableCommands(ctx context.Context, guildID string, commands []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	enable := make(map[string]bool, len(commands))
+	for _, cmd := range commands {
+		enable[cmd] = true
+	}
+	cfg := s.guild(guildID)
+	var remaining []string
+	for _, cmd := range cfg.DisabledCommands {
+		if !enable[cmd] {
+			remaining = append(remaining, cmd)
+		}
+	}
+	cfg.DisabledCommands = remaining
+	return nil
+}
+
+func (s *FakeStore) DisableCategories(ctx context.Context, guildID string, categories []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	cfg := s.guild(guildID)
+	cfg.DisabledCategories = append(cfg.DisabledCategories, categories...)
+	return nil
+}
+
+func (s *FakeStore) EnableCategories(ctx context.Context, guildID string, categories []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	cfg := s.guild(guildID)
+	cfg.DisabledCategories = slices.DeleteFunc(cfg.DisabledCategories, func(category string) bool {
+		return slices.Contains(categories, category)
+	})
+	return nil
+}
+
+func (s *FakeStore) UpdateGuildAliases(ctx context.Context, guildID string, aliases map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.guild(guildID).Aliases = maps.Clone(aliases)
+	return nil
+}
+
+func (s *FakeStore) UpdateGuildLocale(ctx context.Context, guildID, locale string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.guild(guildID).Locale = locale
+	return nil
+}
+
+func (s *FakeStore) GetCustomCommand(ctx context.Context, guildID, name string) (*database.CustomCommand, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	cmd, ok := s.custom[guildID][name]
+	if !ok {
+		return nil, nil
+	}
+	return &cmd, nil
+}
+
+func (s *FakeStore) GetCustomCommands(ctx context.Context, guildID string) ([]database.CustomCommand, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	cmds := make([]database.CustomCommand, 0, len(s.custom[guildID]))
+	for _, cmd := range s.custom[guildID] {
+		cmds = append(cmds, cmd)
+	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+	return cmds, nil
+}
+
+func (s *FakeStore) SetCustomCommand(ctx context.Context, cmd database.CustomCommand) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	if s.custom[cmd.GuildID] == nil {
+		s.custom[cmd.GuildID] = make(map[string]database.CustomCommand)
+	}
+	cmd.UpdatedAt = time.Now()
+	s.custom[cmd.GuildID][cmd.Name] = cmd
+	return nil
+}
+
+func (s *FakeStore) DeleteCustomCommand(ctx context.Context, guildID, name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return false, s.Err
+	}
+	_, ok := s.custom[guildID][name]
+	delete(s.custom[guildID], name)
+	return ok, nil
+}
+
+func (s *FakeStore) ClaimCooldown(ctx context.Context, command, userID string, durationMs int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return 0, s.Err
+	}
+	key := userID + " " + command
+	if remaining := time.Until(s.cools[key]).Milliseconds(); remaining > 0 {
+		return remaining, nil
+	}
+	s.cools[key] = time.Now().Add(time.Duration(durationMs) * time.Millisecond)
+	return 0, nil
+}
+
+func (s *FakeStore) SetCooldown(ctx context.Context, command, userID string, durationMs int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.cools[userID+" "+command] = time.Now().Add(time.Duration(durationMs) * time.Millisecond)
+	return nil
+}
+
+func (s *FakeStore) ClearCooldown(ctx context.Context, command, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	delete(s.cools, userID+" "+command)
+	return nil
+}
+
+func (s *FakeStore) UpdateGuildPremium(ctx context.Context, guildID string, premium bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.guild(guildID).Premium = premium
+	return nil
+}
+
+func (s *FakeStore) GetDonator(ctx context.Context, userID string) (*database.Donator, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	d, ok := s.donors[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &d, nil
+}
+
+func (s *FakeStore) GetDonatorLevel(ctx context.Context, userID string) (int, error) {
+	d, err := s.GetDonator(ctx, userID)
+	if err != nil || d == nil || !d.Active() {
+		return 0, err
+	}
+	return d.Level, nil
+}
+
+func (s *FakeStore) GetDonators(ctx context.Context) ([]database.Donator, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	donators := make([]database.Donator, 0, len(s.donors))
+	for _, d := range s.donors {
+		donators = append(donators, d)
+	}
+	sort.Slice(donators, func(i, j int) bool {
+		if donators[i].Level != donators[j].Level {
+			return donators[i].Level > donators[j].Level
+		}
+		return donators[i].ID < donators[j].ID
+	})
+	return donators, nil
+}
+
+func (s *FakeStore) SetDonator(ctx context.Context, userID string, level int, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.donors[userID] = database.Donator{ID: userID, Level: level, ExpiresAt: expiresAt}
+	return nil
+}
+
+func (s *FakeStore) RemoveDonator(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	delete(s.donors, userID)
+	return nil
+}
+
+func (s *FakeStore) GetCommandRules(ctx context.Context, guildID string) ([]database.CommandRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	var rules []database.CommandRule
+	for _, rule := range s.rules {
+		if rule.GuildID == guildID {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (s *FakeStore) SetCommandRule(ctx context.Context, rule database.CommandRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	rule.UpdatedAt = time.Now()
+	for i, existing := range s.rules {
+		if existing.GuildID == rule.GuildID && existing.Command == rule.Command && existing.Category == rule.Category &&
+			existing.Scope == rule.Scope && existing.ScopeID == rule.ScopeID {
+			rule.ID = existing.ID
+			s.rules[i] = rule
+			return nil
+		}
+	}
+	s.ruleID++
+	rule.ID = s.ruleID
+	s.rules = append(s.rules, rule)
+	return nil
+}
+
+func (s *FakeStore) DeleteCommandRule(ctx context.Context, guildID string, id int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return false, s.Err
+	}
+	for i, rule := range s.rules {
+		if rule.GuildID == guildID && rule.ID == id {
+			s.rules = append(s.rules[:i], s.rules[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *FakeStore) ClearCommandRules(ctx context.Context, guildID string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return 0, s.Err
+	}
+	kept := s.rules[:0]
+	for _, rule := range s.rules {
+		if rule.GuildID != guildID {
+			kept = append(kept, rule)
+		}
+	}
+	n := int64(len(s.rules) - len(kept))
+	s.rules = kept
+	return n, nil
+}
+
+// AddUsage records command invocations for the usage reports
+func (s *FakeStore) AddUsage(usage ...database.CommandUsage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range usage {
+		if u.CreatedAt.IsZero() {
+			u.CreatedAt = time.Now()
+		}
+		s.usage = append(s.usage, u)
+	}
+}
+
+// usageSince returns the invocations in a window, like the database queries
+func (s *FakeStore) usageSince(guildID string, since time.Time) []database.CommandUsage {
+	var usage []database.CommandUsage
+	for _, u := range s.usage {
+		if u.CreatedAt.Before(since) || (guildID != "" && u.GuildID != guildID) {
+			continue
+		}
+		usage = append(usage, u)
+	}
+	return usage
+}
+
+func (s *FakeStore) GetCommandUsageStats(ctx context.Context, guildID string, since time.Time, order database.UsageOrder, limit int) ([]database.CommandUsageStat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	byCommand := make(map[string]*database.CommandUsageStat)
+	total := make(map[string]int64)
+	for _, u := range s.usageSince(guildID, since) {
+		stat, ok := byCommand[u.Command]
+		if !ok {
+			stat = &database.CommandUsageStat{Command: u.Command}
+			byCommand[u.Command] = stat
+		}
+		stat.Uses++
+		if u.Outcome != database.OutcomeOK {
+			stat.Failures++
+		}
+		stat.MaxDuration = max(stat.MaxDuration, u.Duration)
+		total[u.Command] += u.Duration
+	}
+
+	stats := make([]database.CommandUsageStat, 0, len(byCommand))
+	for name, stat := range byCommand {
+		stat.AvgDuration = total[name] / stat.Uses
+		if order == database.UsageByFailures && stat.Failures == 0 {
+			continue
+		}
+		stats = append(stats, *stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		switch {
+		case order == database.UsageByUses && a.Uses != b.Uses:
+			return a.Uses > b.Uses
+		case order == database.UsageByDuration && a.AvgDuration != b.AvgDuration:
+			return a.AvgDuration > b.AvgDuration
+		case order == database.UsageByFailures && a.Failures != b.Failures:
+			return a.Failures > b.Failures
+		case order == database.UsageByFailures && a.Uses != b.Uses:
+			return a.Uses < b.Uses
+		}
+		return a.Command < b.Command
+	})
+	if len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats, nil
+}
+
+func (s *FakeStore) GetCommandUsageTotals(ctx context.Context, guildID string, since time.Time) (*database.CommandUsageTotals, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	var totals database.CommandUsageTotals
+	users := make(map[string]bool)
+	guilds := make(map[string]bool)
+	for _, u := range s.usageSince(guildID, since) {
+		totals.Uses++
+		if u.Outcome != database.OutcomeOK {
+			totals.Failures++
+		}
+		users[u.UserHash] = true
+		guilds[u.GuildID] = true
+	}
+	totals.Users = int64(len(users))
+	totals.Guilds = int64(len(guilds))
+	return &totals, nil
+}
+
+// ImageCall is a request made to FakeImageGen
+type ImageCall struct {
+	Endpoint string
+	Data     string            // Set by Generate
+	Params   map[string]string // Set by GenerateCustom
+}
+
+// FakeImageGen records image requests and returns Image (a placeholder PNG
+// header by default)
+type FakeImageGen struct {
+	mu    sync.Mutex
+	calls []ImageCall
+	Image []byte
+	Err   error
+}
+
+func (g *FakeImageGen) Generate(ctx context.Context, endpoint, data string) ([]byte, error) {
+	return g.record(ImageCall{Endpoint: endpoint, Data: data})
+}
+
+func (g *FakeImageGen) GenerateCustom(ctx context.Context, endpoint string, params map[string]string) ([]byte, error) {
+	return g.record(ImageCall{Endpoint: endpoint, Params: params})
+}
+
+// Calls returns the recorded requests
+func (g *FakeImageGen) Calls() []ImageCall {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]ImageCall(nil), g.calls...)
+}
+
+func (g *FakeImageGen) record(call ImageCall) ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.calls = append(g.calls, call)
+	if g.Err != nil {
+		return nil, g.Err
+	}
+	if g.Image == nil {
+		return []byte("\x89PNG\r\n\x1a\n"), nil
+	}
+	return g.Image, nil
+}
+
+// FakeReddit serves posts per endpoint
+type FakeReddit struct {
+	mu    sync.Mutex
+	posts map[string][]external.RedditPost
+	Err   error
+}
+
+// NewFakeReddit creates a fetcher without posts
+func NewFakeReddit() *FakeReddit {
+	return &FakeReddit{posts: make(map[string][]external.RedditPost)}
+}
+
+// SetPosts sets the posts returned for an endpoint
+func (r *FakeReddit) SetPosts(endpoint string, posts ...external.RedditPost) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.posts[endpoint] = posts
+}
+
+func (r *FakeReddit) FetchPosts(ctx context.Context, endpoint string) ([]external.RedditPost, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	posts, ok := r.posts[endpoint]
+	if !ok {
+		return nil, fmt.Errorf("no posts for %s", endpoint)
+	}
+	return append([]external.RedditPost(nil), posts...), nil
+}
+
+// PlayCall is a PlayAudio call made to FakeVoice
+type PlayCall struct {
+	GuildID   string
+	ChannelID string
+	AudioPath string
+}
+
+// FakeVoice records played audio. Audio keeps playing until Stop is called.
+type FakeVoice struct {
+	mu      sync.Mutex
+	playing map[string]bool
+	played  []PlayCall
+	Err     error
+}
+
+// NewFakeVoice creates a player that isn't playing anywhere
+func NewFakeVoice() *FakeVoice {
+	return &FakeVoice{playing: make(map[string]bool)}
+}
+
+func (v *FakeVoice) IsPlaying(guildID string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.playing[guildID]
+}
+
+func (v *FakeVoice) PlayAudio(guildID, channelID, audioPath string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.Err != nil {
+		return v.Err
+	}
+	v.playing[guildID] = true
+	v.played = append(v.played, PlayCall{GuildID: guildID, ChannelID: channelID, AudioPath: audioPath})
+	return nil
+}
+
+func (v *FakeVoice) Stop(guildID string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.playing, guildID)
+}
+
+// Played returns the recorded PlayAudio calls
+func (v *FakeVoice) Played() []PlayCall {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]PlayCall(nil), v.played...)
+}
+
+// FakeResponder records placeholders and progress updates
+type FakeResponder struct {
+	mu      sync.Mutex
+	updates []string
+	typing  bool
+}
+
+func (r *FakeResponder) Defer(placeholder string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.updates) == 0 {
+		r.updates = append(r.updates, placeholder)
+	}
+	return nil
+}
+
+func (r *FakeResponder) Typing() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.typing = true
+}
+
+func (r *FakeResponder) Progress(content string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates = append(r.updates, content)
+	return nil
+}
+
+// Updates returns the placeholder followed by every progress update
+func (r *FakeResponder) Updates() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.updates...)
+}
+
+// Typed reports whether the command showed the typing indicator
+func (r *FakeResponder) Typed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.typing
+}
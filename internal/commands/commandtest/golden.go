@@ -0,0 +1,118 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commandtest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/dankmemer/bot/internal/commands"
+)
+
+// UpdateEnv is the environment variable that makes AssertGolden rewrite
+// golden files instead of comparing against them
+const UpdateEnv = "COMMANDTEST_UPDATE"
+
+// TB is the part of testing.TB used by AssertGolden
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+type snapshotFile struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+type snapshot struct {
+	Content string                  `json:"content,omitempty"`
+	Embed   *discordgo.MessageEmbed `json:"embed,omitempty"`
+	Files   []snapshotFile          `json:"files,omitempty"`
+	Reply   bool                    `json:"reply,omitempty"`
+
+	Components []discordgo.MessageComponent `json:"components,omitempty"`
+}
+
+// Snapshot renders a response as stable, indented JSON. Embed colors are
+// zeroed since most commands pick them at random, files are reduced to their
+// name and size, and only the rows of components are kept.
+func Snapshot(resp *commands.CommandResponse) string {
+	if resp == nil {
+		return "null\n"
+	}
+
+	snap := snapshot{Content: resp.Content, Reply: resp.Reply}
+	if resp.Embed != nil {
+		embed := *resp.Embed
+		embed.Color = 0
+		snap.Embed = &embed
+	}
+	if resp.Components != nil {
+		snap.Components = resp.Components.Rows
+	}
+	files := resp.Files
+	if resp.File != nil {
+		files = append([]*discordgo.File{resp.File}, files...)
+	}
+	for _, f := range files {
+		size := 0
+		if f.Reader != nil {
+			if r, ok := f.Reader.(interface{ Len() int }); ok {
+				size = r.Len()
+			}
+		}
+		snap.Files = append(snap.Files, snapshotFile{Name: f.Name, Size: size})
+	}
+
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(snap)
+	return buf.String()
+}
+
+// AssertGolden compares got with testdata/<name>.golden. With
+// COMMANDTEST_UPDATE=1 the file is written instead.
+func AssertGolden(t TB, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("creating testdata: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("writing %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v (run with %s=1 to create it)", path, err, UpdateEnv)
+		return
+	}
+	if string(want) != got {
+		t.Errorf("%s mismatch\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
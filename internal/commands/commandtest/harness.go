@@ -0,0 +1,337 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package commandtest runs commands offline. It provides a Discord session
+// backed by a pre-populated state and a recording HTTP transport, in-memory
+// fakes for the services commands use, and golden-file helpers.
+//
+//	h := commandtest.New()
+//	resp, err := h.Run(cmd, "kill <@"+h.AddUser("victim").ID+">")
+//	commandtest.AssertGolden(t, "kill", commandtest.Snapshot(resp))
+package commandtest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// IDs of the fake guild, channel and users
+const (
+	BotID       = "100000000000000000"
+	AuthorID    = "100000000000000001"
+	GuildID     = "200000000000000000"
+	ChannelID   = "300000000000000000"
+	VoiceID     = "300000000000000001"
+	DMChannelID = "300000000000000002"
+	Prefix      = "pls"
+)
+
+// DefaultPermissions are given to @everyone in the fake guild
+const DefaultPermissions = discordgo.PermissionViewChannel |
+	discordgo.PermissionSendMessages |
+	discordgo.PermissionEmbedLinks |
+	discordgo.PermissionAttachFiles |
+	discordgo.PermissionAddReactions |
+	discordgo.PermissionReadMessageHistory |
+	discordgo.PermissionVoiceConnect |
+	discordgo.PermissionVoiceSpeak
+
+// Harness holds a fake Discord session and fake services for running commands
+type Harness struct {
+	Session   *discordgo.Session
+	Transport *Transport // Records every REST call made through Session
+
+	Guild   *discordgo.Guild
+	Channel *discordgo.Channel
+	Author  *discordgo.User
+	BotUser *discordgo.User
+
+	DM bool // Send messages in a DM with the bot instead of Channel
+
+	Config   *utils.Config
+	Registry *commands.Registry
+	Store    *FakeStore
+	ImageGen *FakeImageGen
+	Reddit   *FakeReddit
+	Voice    *FakeVoice
+
+	Responder *FakeResponder // Records ctx.Defer, ctx.Typing and ctx.Progress
+
+	last   *commands.CommandContext // Context of the last Run, the origin of Click
+	nextID atomic.Int64
+}
+
+// New creates a harness with a guild containing a text channel, a voice
+// channel, the bot and the message author
+func New() *Harness {
+	h := &Harness{
+		Config: &utils.Config{
+			DefaultPrefix: Prefix,
+			Version:       "test",
+			DonatorTiers:  utils.DefaultDonatorTiers,
+		},
+		Registry: commands.NewRegistry(),
+		Store:    NewFakeStore(),
+		ImageGen: &FakeImageGen{},
+		Reddit:   NewFakeReddit(),
+		Voice:    NewFakeVoice(),
+
+		Responder: &FakeResponder{},
+	}
+	h.nextID.Store(400000000000000000)
+
+	h.BotUser = &discordgo.User{ID: BotID, Username: "Dank Memer", Bot: true}
+	h.Author = &discordgo.User{ID: AuthorID, Username: "author"}
+
+	h.Transport = NewTransport(h.BotUser)
+	session, _ := discordgo.New("Bot test")
+	session.Client.Transport = h.Transport
+	session.State.User = h.BotUser
+	h.Session = session
+
+	h.Guild = &discordgo.Guild{
+		ID:      GuildID,
+		Name:    "Test Server",
+		OwnerID: "0",
+		Roles: []*discordgo.Role{
+			{ID: GuildID, Name: "@everyone", Permissions: DefaultPermissions},
+		},
+	}
+	h.Channel = &discordgo.Channel{ID: ChannelID, GuildID: GuildID, Name: "general", Type: discordgo.ChannelTypeGuildText}
+	voice := &discordgo.Channel{ID: VoiceID, GuildID: GuildID, Name: "Voice", Type: discordgo.ChannelTypeGuildVoice}
+
+	session.State.GuildAdd(h.Guild)
+	session.State.ChannelAdd(h.Channel)
+	session.State.ChannelAdd(voice)
+	h.addMember(h.BotUser)
+	h.addMember(h.Author)
+
+	return h
+}
+
+// Services returns the fake services, as commands see them
+func (h *Harness) Services() *commands.Services {
+	return &commands.Services{
+		DB:       h.Store,
+		Config:   h.Config,
+		Registry: h.Registry,
+		ImageGen: h.ImageGen,
+		Reddit:   h.Reddit,
+		Voice:    h.Voice,
+		Logger:   zerolog.Nop(),
+	}
+}
+
+// AddUser adds a member with the given username to the guild
+func (h *Harness) AddUser(username string) *discordgo.User {
+	user := &discordgo.User{ID: h.newID(), Username: username}
+	h.addMember(user)
+	return user
+}
+
+func (h *Harness) addMember(user *discordgo.User) {
+	h.Session.State.MemberAdd(&discordgo.Member{GuildID: GuildID, User: user, Roles: []string{}})
+}
+
+// SetPermissions gives a user a role with the given permissions, on top of
+// DefaultPermissions
+func (h *Harness) SetPermissions(userID string, permissions int64) {
+	role := &discordgo.Role{ID: h.newID(), Name: "perms-" + userID, Permissions: permissions}
+	h.Session.State.RoleAdd(GuildID, role)
+
+	member, err := h.Session.State.Member(GuildID, userID)
+	if err != nil {
+		return
+	}
+	member.Roles = append(member.Roles, role.ID)
+}
+
+// JoinVoice puts a user in the fake voice channel
+func (h *Harness) JoinVoice(userID string) {
+	guild, err := h.Session.State.Guild(GuildID)
+	if err != nil {
+		return
+	}
+	h.Session.State.Lock()
+	defer h.Session.State.Unlock()
+	guild.VoiceStates = append(guild.VoiceStates, &discordgo.VoiceState{
+		GuildID:   GuildID,
+		ChannelID: VoiceID,
+		UserID:    userID,
+	})
+}
+
+// Context builds the context of a prefixed message like the bot's message
+// handler does. content is the message without the prefix, e.g. "kill @user".
+// Flags are taken out if the command is in Registry and declares them.
+func (h *Harness) Context(content string) *commands.CommandContext {
+	parts, _ := commands.Tokenize(content)
+	var cmd commands.Command
+	if len(parts) > 0 {
+		cmd = h.Registry.Find(parts[0])
+	}
+	return h.context(content, cmd)
+}
+
+func (h *Harness) context(content string, cmd commands.Command) *commands.CommandContext {
+	parts, flags := commands.Tokenize(content)
+	var args []string
+	trigger := ""
+	if len(parts) > 0 {
+		trigger = strings.ToLower(parts[0])
+		args = parts[1:]
+	}
+	if cmd != nil {
+		if declared := commands.DeclaredFlags(cmd, args); len(declared) > 0 {
+			parts, flags = commands.Tokenize(content, declared...)
+			args = parts[1:]
+		}
+	}
+
+	member, _ := h.Session.State.Member(GuildID, h.Author.ID)
+	m := &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ID:        h.newID(),
+			ChannelID: h.Channel.ID,
+			GuildID:   GuildID,
+			Author:    h.Author,
+			Member:    member,
+			Content:   Prefix + " " + content,
+			Mentions:  h.mentions(args),
+		},
+	}
+	if h.DM {
+		m.ChannelID = DMChannelID
+		m.GuildID = ""
+		m.Member = nil
+	}
+
+	guildConfig := h.Store.Guild(GuildID)
+
+	return &commands.CommandContext{
+		Context:     context.Background(),
+		Session:     h.Session,
+		Message:     m,
+		Content:     content,
+		Args:        args,
+		CleanArgs:   h.cleanArgs(args),
+		Flags:       flags,
+		GuildConfig: &guildConfig,
+		Services:    h.Services(),
+		Command:     trigger,
+		Responder:   h.Responder,
+	}
+}
+
+// Run runs a command with the given content (without the prefix). Like the
+// bot, it checks UserPermissions, parses arguments and sends the placeholder
+// first. The global middleware (cooldowns, disabled commands, ...) is not
+// applied.
+func (h *Harness) Run(cmd commands.Command, content string) (*commands.CommandResponse, error) {
+	ctx := h.context(content, cmd)
+	props := cmd.Props()
+	ctx.Command = props.Triggers[0]
+	h.last = ctx
+
+	if resp := commands.CheckUserPermissions(ctx, props); resp != nil {
+		return resp, nil
+	}
+	if err := commands.ParseArgs(ctx, props); err != nil {
+		return &commands.CommandResponse{Content: err.Error()}, nil
+	}
+	if props.Placeholder != "" {
+		_ = ctx.Defer(props.Placeholder)
+	} else if props.Typing {
+		ctx.Typing()
+	}
+	return cmd.Run(ctx)
+}
+
+// Exec looks up the command named by the first word of content in Registry
+// and runs it. Returns nil if there is no such command.
+func (h *Harness) Exec(content string) (*commands.CommandResponse, error) {
+	parts, _ := commands.Tokenize(content)
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	cmd := h.Registry.Find(parts[0])
+	if cmd == nil {
+		return nil, nil
+	}
+	return h.Run(cmd, content)
+}
+
+// Click calls the component handler of a response as if the author used the
+// component with the given custom ID. Like the bot, a response without
+// components leaves the message unchanged.
+func (h *Harness) Click(resp *commands.CommandResponse, action string, values ...string) (*commands.CommandResponse, error) {
+	if resp == nil || resp.Components == nil || resp.Components.Handler == nil {
+		return nil, fmt.Errorf("response has no component handler")
+	}
+	return resp.Components.Handler(&commands.ComponentContext{
+		Context: context.Background(),
+		Session: h.Session,
+		User:    h.Author,
+		Action:  action,
+		Values:  values,
+		Origin:  h.last,
+	})
+}
+
+func (h *Harness) mentions(args []string) []*discordgo.User {
+	var users []*discordgo.User
+	for _, arg := range args {
+		if id := mentionID(arg); id != "" {
+			if member, err := h.Session.State.Member(GuildID, id); err == nil {
+				users = append(users, member.User)
+			}
+		}
+	}
+	return users
+}
+
+func (h *Harness) cleanArgs(args []string) []string {
+	clean := make([]string, len(args))
+	for i, arg := range args {
+		clean[i] = arg
+		if id := mentionID(arg); id != "" {
+			if member, err := h.Session.State.Member(GuildID, id); err == nil {
+				clean[i] = member.User.Username
+			}
+		}
+	}
+	return clean
+}
+
+func mentionID(arg string) string {
+	if !strings.HasPrefix(arg, "<@") || !strings.HasSuffix(arg, ">") {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSuffix(strings.TrimPrefix(arg, "<@"), ">"), "!")
+}
+
+func (h *Harness) newID() string {
+	return strconv.FormatInt(h.nextID.Add(1), 10)
+}
@@ -0,0 +1,180 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commandtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Request is a REST call recorded by Transport
+type Request struct {
+	Method string
+	Path   string // Path relative to the API root, e.g. "channels/1/messages"
+	Body   []byte // JSON body; the payload_json part for multipart requests
+	Files  []string
+}
+
+// Transport is an http.RoundTripper that records Discord REST calls instead
+// of sending them. Creating messages echoes them back with an ID so that
+// follow-up edits and deletes work.
+type Transport struct {
+	mu       sync.Mutex
+	requests []Request
+	author   *discordgo.User
+	nextID   atomic.Int64
+}
+
+// NewTransport creates a transport that answers as the given bot user
+func NewTransport(author *discordgo.User) *Transport {
+	t := &Transport{author: author}
+	t.nextID.Store(500000000000000000)
+	return t
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	req := Request{
+		Method: r.Method,
+		Path:   strings.TrimPrefix(r.URL.Path, "/api/v"+discordgo.APIVersion+"/"),
+	}
+	if r.Body != nil {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body, req.Files = splitMultipart(r.Header.Get("Content-Type"), body)
+	}
+
+	t.mu.Lock()
+	t.requests = append(t.requests, req)
+	t.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodDelete || r.Method == http.MethodPut:
+		return respond(r, http.StatusNoContent, nil), nil
+	case strings.HasSuffix(req.Path, "/messages") || strings.Contains(req.Path, "/messages/"):
+		if r.Method == http.MethodGet {
+			break
+		}
+		return respond(r, http.StatusOK, t.echoMessage(req)), nil
+	case r.Method != http.MethodGet:
+		body := req.Body
+		if len(body) == 0 {
+			body = []byte("{}")
+		}
+		return respond(r, http.StatusOK, body), nil
+	}
+
+	return respond(r, http.StatusNotFound, []byte(`{"message": "Unknown", "code": 0}`)), nil
+}
+
+// Requests returns every recorded call
+func (t *Transport) Requests() []Request {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Request(nil), t.requests...)
+}
+
+// Messages returns the messages sent or edited through the session, in order
+func (t *Transport) Messages() []*discordgo.MessageSend {
+	var messages []*discordgo.MessageSend
+	for _, req := range t.Requests() {
+		if req.Method != http.MethodPost && req.Method != http.MethodPatch {
+			continue
+		}
+		if !strings.Contains(req.Path, "/messages") {
+			continue
+		}
+		var msg discordgo.MessageSend
+		if json.Unmarshal(req.Body, &msg) == nil {
+			messages = append(messages, &msg)
+		}
+	}
+	return messages
+}
+
+// Reset forgets all recorded calls
+func (t *Transport) Reset() {
+	t.mu.Lock()
+	t.requests = nil
+	t.mu.Unlock()
+}
+
+// echoMessage builds the message Discord would return for a send or edit
+func (t *Transport) echoMessage(req Request) []byte {
+	msg := make(map[string]interface{})
+	_ = json.Unmarshal(req.Body, &msg)
+
+	parts := strings.Split(req.Path, "/")
+	if len(parts) >= 2 && parts[0] == "channels" {
+		msg["channel_id"] = parts[1]
+	}
+	if req.Method == http.MethodPost {
+		msg["id"] = strconv.FormatInt(t.nextID.Add(1), 10)
+	} else {
+		msg["id"] = parts[len(parts)-1]
+	}
+	msg["author"] = t.author
+
+	data, _ := json.Marshal(msg)
+	return data
+}
+
+// splitMultipart returns the JSON payload and file names of a request body
+func splitMultipart(contentType string, body []byte) ([]byte, []string) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return body, nil
+	}
+
+	var payload []byte
+	var files []string
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		if part.FormName() == "payload_json" {
+			payload, _ = io.ReadAll(part)
+		} else if part.FileName() != "" {
+			files = append(files, part.FileName())
+		}
+	}
+	return payload, files
+}
+
+func respond(r *http.Request, status int, body []byte) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    r,
+	}
+}
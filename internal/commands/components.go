@@ -0,0 +1,93 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"context"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DefaultComponentTimeout is how long in milliseconds components keep
+// working when Components.Timeout isn't set
+const DefaultComponentTimeout = 300000
+
+// ComponentHandler handles a click on a button or a select menu choice. The
+// returned response replaces the message; a nil response leaves it as is.
+type ComponentHandler func(ctx *ComponentContext) (*CommandResponse, error)
+
+// Components are buttons and select menus attached to a response
+type Components struct {
+	Rows    []discordgo.MessageComponent // Action rows; custom IDs are the actions passed to Handler
+	Handler ComponentHandler             // Called when a component is used
+	Timeout int64                        // Milliseconds before they stop working (default: DefaultComponentTimeout)
+	Users   []string                     // Users allowed to use them (default: whoever ran the command)
+	Public  bool                         // Anyone may use them
+	Remove  bool                         // Remove them when they time out instead of disabling them
+}
+
+// ComponentContext holds the context of a component interaction
+type ComponentContext struct {
+	Context     context.Context // Cancelled after DefaultTimeout or when the bot shuts down
+	Session     *discordgo.Session
+	Interaction *discordgo.Interaction
+	User        *discordgo.User // Who used the component
+	Action      string          // Custom ID the command gave the component
+	Values      []string        // Chosen select menu values
+	Origin      *CommandContext // Context of the command that sent the components
+}
+
+// DisableComponents returns a copy of rows with every button and select menu
+// disabled
+func DisableComponents(rows []discordgo.MessageComponent) []discordgo.MessageComponent {
+	return MapComponents(rows, func(c discordgo.MessageComponent) discordgo.MessageComponent {
+		switch c := c.(type) {
+		case discordgo.Button:
+			c.Disabled = c.Style != discordgo.LinkButton
+			return c
+		case discordgo.SelectMenu:
+			c.Disabled = true
+			return c
+		}
+		return c
+	})
+}
+
+// MapComponents returns a copy of rows with fn applied to every component
+// inside the action rows
+func MapComponents(rows []discordgo.MessageComponent, fn func(discordgo.MessageComponent) discordgo.MessageComponent) []discordgo.MessageComponent {
+	mapped := make([]discordgo.MessageComponent, 0, len(rows))
+	for _, row := range rows {
+		switch r := row.(type) {
+		case discordgo.ActionsRow:
+			components := make([]discordgo.MessageComponent, len(r.Components))
+			for i, c := range r.Components {
+				components[i] = fn(c)
+			}
+			mapped = append(mapped, discordgo.ActionsRow{Components: components})
+		case *discordgo.ActionsRow:
+			components := make([]discordgo.MessageComponent, len(r.Components))
+			for i, c := range r.Components {
+				components[i] = fn(c)
+			}
+			mapped = append(mapped, discordgo.ActionsRow{Components: components})
+		default:
+			mapped = append(mapped, row)
+		}
+	}
+	return mapped
+}
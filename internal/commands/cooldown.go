@@ -0,0 +1,40 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"strings"
+
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// ScaleCooldown shortens a cooldown in milliseconds by the CooldownMultiplier
+// of a donator tier
+func ScaleCooldown(cfg *utils.Config, cooldown int64, level int) int64 {
+	if tier := cfg.DonatorTier(level); tier != nil && tier.CooldownMultiplier > 0 && tier.CooldownMultiplier < 1 {
+		return int64(float64(cooldown) * tier.CooldownMultiplier)
+	}
+	return cooldown
+}
+
+// CooldownResponse tells the user to wait remaining milliseconds before
+// using the command at path again
+func CooldownResponse(props CommandProps, path, locale string, remaining int64) *CommandResponse {
+	msg := LocalizedCooldownMessage(props, path, locale)
+	msg = strings.Replace(msg, "{cooldown}", utils.FormatDurationIn(locale, remaining), 1)
+	return &CommandResponse{Content: msg}
+}
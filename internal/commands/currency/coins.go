@@ -31,16 +31,11 @@ func init() {
 			Triggers:    []string{"coins", "balance", "bal"},
 			Description: "u got dis many coins ok",
 			Category:    "Currency",
+			DMAllowed:   true,
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			// Get database from bot
-			b, ok := ctx.Bot.(dbBot)
-			if !ok {
-				return nil, fmt.Errorf("cannot access database")
-			}
-
 			// Get balance
-			coins, err := b.GetDB().GetCoins(ctx.Message.Author.ID)
+			coins, err := ctx.Services.DB.GetCoins(ctx.Context, ctx.Message.Author.ID)
 			if err != nil {
 				return nil, err
 			}
@@ -25,36 +25,40 @@ import (
 	"github.com/dankmemer/bot/internal/utils"
 )
 
+// dailyCoins is what pls daily pays users who don't donate
+const dailyCoins = 100
+
 func init() {
 	bot.Register(&commands.BaseCommand{
 		Properties: commands.CommandProps{
 			Triggers:        []string{"daily"},
 			Description:     "u got dis many coins ok",
 			Category:        "Currency",
+			DMAllowed:       true,
 			Cooldown:        86400000, // 24 hours
 			CooldownMessage: "I'm not made of money dude, wait {cooldown}",
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			// Get database from bot
-			b, ok := ctx.Bot.(dbBot)
-			if !ok {
-				return nil, fmt.Errorf("cannot access database")
+			// Donators get more
+			amount := int64(dailyCoins)
+			if tier := ctx.Services.Config.DonatorTier(ctx.DonatorLevel()); tier != nil && tier.DailyCoins > amount {
+				amount = tier.DailyCoins
 			}
 
 			// Add coins
-			if err := b.GetDB().AddCoins(ctx.Message.Author.ID, 100); err != nil {
+			if err := ctx.Services.DB.AddCoins(ctx.Context, ctx.Message.Author.ID, amount); err != nil {
 				return nil, err
 			}
 
 			// Get new balance
-			coins, err := b.GetDB().GetCoins(ctx.Message.Author.ID)
+			coins, err := ctx.Services.DB.GetCoins(ctx.Context, ctx.Message.Author.ID)
 			if err != nil {
 				return nil, err
 			}
 
 			embed := &discordgo.MessageEmbed{
 				Title:       "here are ur daily coins ok",
-				Description: fmt.Sprintf("u got 100, now u have %d", coins),
+				Description: fmt.Sprintf("u got %d, now u have %d", amount, coins),
 				Thumbnail: &discordgo.MessageEmbedThumbnail{
 					URL: "https://dankmemer.lol/coin.png",
 				},
@@ -68,13 +72,3 @@ func init() {
 		},
 	})
 }
-
-// Interface to access bot's database
-type dbBot interface {
-	GetDB() database
-}
-
-type database interface {
-	AddCoins(userID string, amount int64) error
-	GetCoins(userID string) (int64, error)
-}
@@ -0,0 +1,46 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package currency_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dankmemer/bot/internal/bot"
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+)
+
+func TestDailyDonatorCoins(t *testing.T) {
+	tests := map[int]int64{0: 100, 1: 250, 2: 500, 3: 1000}
+	for level, want := range tests {
+		h := commandtest.New()
+		if err := bot.RegisterCommands(&bot.Bot{Commands: h.Registry}); err != nil {
+			t.Fatal(err)
+		}
+		if level > 0 {
+			h.Store.SetDonator(context.Background(), commandtest.AuthorID, level, time.Time{})
+		}
+
+		if _, err := h.Exec("daily"); err != nil {
+			t.Fatal(err)
+		}
+		if coins, _ := h.Store.GetCoins(context.Background(), commandtest.AuthorID); coins != want {
+			t.Errorf("level %d: got %d coins, want %d", level, coins, want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/dankmemer/bot/internal/database"
+)
+
+// CustomCategory is the category custom commands are listed under
+const CustomCategory = "Custom Commands"
+
+// CustomCommand is a guild's text command, answered by rendering its template.
+// The bot builds one from the database when a trigger isn't a built-in.
+type CustomCommand struct {
+	Name     string
+	Template *Template
+}
+
+// NewCustomCommand parses a stored custom command
+func NewCustomCommand(c *database.CustomCommand) (*CustomCommand, error) {
+	tmpl, err := ParseTemplate(c.Response)
+	if err != nil {
+		return nil, err
+	}
+	return &CustomCommand{Name: c.Name, Template: tmpl}, nil
+}
+
+func (c *CustomCommand) Props() CommandProps {
+	props := CommandProps{
+		Triggers:    []string{c.Name},
+		Description: "A custom command of this server",
+		Usage:       "{command} [args...]",
+		Category:    CustomCategory,
+		Cooldown:    3000,
+	}
+	if c.Template.Embed() {
+		props.Permissions = []int64{discordgo.PermissionEmbedLinks}
+	}
+	return props
+}
+
+func (c *CustomCommand) Run(ctx *CommandContext) (*CommandResponse, error) {
+	return c.Template.Render(ctx), nil
+}
@@ -0,0 +1,49 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import "strconv"
+
+// DonatorLevel returns the invoking user's active donator tier, or 0 if they
+// have none. It is looked up once per command.
+func (ctx *CommandContext) DonatorLevel() int {
+	if ctx.donatorKnown {
+		return ctx.donatorLevel
+	}
+	level, err := ctx.Services.DB.GetDonatorLevel(ctx.Context, ctx.Message.Author.ID)
+	if err != nil {
+		ctx.Services.Logger.Error().Err(err).Str("user", ctx.Message.Author.ID).Msg("Failed to get donator level")
+		return 0
+	}
+	ctx.donatorLevel, ctx.donatorKnown = level, true
+	return level
+}
+
+// CheckDonator returns an error response if the invoking user's donator tier
+// is below the command's DonatorLevel, or nil if they may use it. Developers
+// always pass.
+func CheckDonator(ctx *CommandContext, props CommandProps) *CommandResponse {
+	if props.DonatorLevel == 0 || isDev(ctx) || ctx.DonatorLevel() >= props.DonatorLevel {
+		return nil
+	}
+
+	name := strconv.Itoa(props.DonatorLevel)
+	if tier := ctx.Services.Config.DonatorTier(props.DonatorLevel); tier != nil && tier.Name != "" {
+		name = tier.Name
+	}
+	return &CommandResponse{Content: ctx.T("donator.required", name)}
+}
@@ -0,0 +1,80 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+func TestScaleCooldown(t *testing.T) {
+	cfg := &utils.Config{DonatorTiers: utils.DefaultDonatorTiers}
+	tests := map[int]int64{0: 10000, 1: 7500, 2: 5000, 3: 2500, 5: 2500}
+	for level, want := range tests {
+		if got := commands.ScaleCooldown(cfg, 10000, level); got != want {
+			t.Errorf("level %d: cooldown %d, want %d", level, got, want)
+		}
+	}
+
+	// Tiers can't lengthen cooldowns
+	cfg.DonatorTiers = []utils.DonatorTier{{Name: "Donator", CooldownMultiplier: 2}}
+	if got := commands.ScaleCooldown(cfg, 10000, 1); got != 10000 {
+		t.Errorf("multiplier 2: cooldown %d, want 10000", got)
+	}
+}
+
+func TestCheckDonator(t *testing.T) {
+	props := commands.CommandProps{Triggers: []string{"postmeme"}, DonatorLevel: 2}
+	tests := []struct {
+		name    string
+		level   int
+		expires time.Time
+		dev     bool
+		allowed bool
+	}{
+		{"not a donator", 0, time.Time{}, false, false},
+		{"lower tier", 1, time.Time{}, false, false},
+		{"required tier", 2, time.Time{}, false, true},
+		{"higher tier", 3, time.Now().Add(time.Hour), false, true},
+		{"expired", 3, time.Now().Add(-time.Hour), false, false},
+		{"developer", 0, time.Time{}, true, true},
+	}
+
+	for _, tt := range tests {
+		h := commandtest.New()
+		if tt.level > 0 {
+			h.Store.SetDonator(context.Background(), commandtest.AuthorID, tt.level, tt.expires)
+		}
+		if tt.dev {
+			h.Config.Devs = []string{commandtest.AuthorID}
+		}
+
+		resp := commands.CheckDonator(h.Context("postmeme"), props)
+		if allowed := resp == nil; allowed != tt.allowed {
+			t.Errorf("%s: allowed = %v, want %v", tt.name, allowed, tt.allowed)
+		}
+		if resp != nil && !strings.Contains(resp.Content, "Super Donator") {
+			t.Errorf("%s: got %q, want the tier named", tt.name, resp.Content)
+		}
+	}
+}
@@ -31,13 +31,14 @@ func init() {
 		Properties: commands.CommandProps{
 			Triggers:    []string{"asktrump", "donald"},
 			Description: "Ask the president whatever you'd like!",
-			Usage:       "{command} <question>",
 			Category:    "Fun Commands",
-			MissingArgs: "You gotta give me something to ask Trump :eyes:",
 			Permissions: []int64{discordgo.PermissionEmbedLinks},
+			Args: []commands.Arg{
+				{Name: "question", Type: commands.ArgText, Description: "Your question for the president", Missing: "You gotta give me something to ask Trump :eyes:"},
+			},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			question := strings.Join(ctx.Args, " ")
+			question := ctx.ArgString("question")
 
 			// Count question marks for exclamation points
 			qCount := strings.Count(question, "?")
@@ -36,7 +36,7 @@ func init() {
 			Permissions: []int64{discordgo.PermissionEmbedLinks},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			data, err := external.Get("http://api.icndb.com/jokes/random", nil)
+			data, err := external.Get(ctx.Context, "http://api.icndb.com/jokes/random", nil)
 			if err != nil {
 				return &commands.CommandResponse{Content: "Couldn't fetch a Chuck Norris joke right now"}, nil
 			}
@@ -29,15 +29,12 @@ func init() {
 		Properties: commands.CommandProps{
 			Triggers:    []string{"google", "lmgtfy"},
 			Description: "Sick of someone asking dumb questions? LMGTFY it for them!",
-			Usage:       "{command} search terms",
-			MissingArgs: "Hey, what do you want me to google?",
 			Category:    "Fun Commands",
+			Args: []commands.Arg{
+				{Name: "query", Type: commands.ArgText, Description: "What to search for", Missing: "Hey, what do you want me to google?"},
+			},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			if len(ctx.Args) == 0 {
-				return &commands.CommandResponse{Content: "Hey, what do you want me to google?"}, nil
-			}
-
 			query := strings.Join(ctx.Args, "+")
 			url := fmt.Sprintf("http://lmgtfy.com/?q=%s", query)
 
@@ -29,27 +29,35 @@ func init() {
 		Properties: commands.CommandProps{
 			Triggers:    []string{"kill", "murder"},
 			Description: "Sick of someone? Easy! Just kill them!",
-			Usage:       "{command} @user",
 			Category:    "Fun Commands",
+			Args: []commands.Arg{
+				{
+					Name:        "user",
+					Type:        commands.ArgUser,
+					Description: "Who to kill",
+					Missing:     "Ok you're dead. Please tag someone else to kill.",
+					Invalid:     "Ok you're dead. Please tag someone else to kill.",
+				},
+				{
+					Name:        "reason",
+					Type:        commands.ArgText,
+					Description: "Why they have to go",
+					Optional:    true,
+				},
+			},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			// Check for self-kill
-			if len(ctx.Args) > 0 && strings.ToLower(ctx.Args[0]) == "me" {
-				return &commands.CommandResponse{Content: "Ok you're dead. Please tag someone else to kill."}, nil
-			}
+			target := ctx.ArgUser("user")
 
-			if len(ctx.Message.Mentions) == 0 {
-				return &commands.CommandResponse{Content: "Ok you're dead. Please tag someone else to kill."}, nil
-			}
-
-			if ctx.Message.Mentions[0].ID == ctx.Message.Author.ID {
+			// Check for self-kill
+			if target.ID == ctx.Message.Author.ID {
 				return &commands.CommandResponse{Content: "Ok you're dead. Please tag someone else to kill."}, nil
 			}
 
 			msg := utils.GetKillMessage()
 
 			// Replace placeholders
-			msg = strings.ReplaceAll(msg, "$mention", ctx.Message.Mentions[0].Username)
+			msg = strings.ReplaceAll(msg, "$mention", target.Username)
 			msg = strings.ReplaceAll(msg, "$author", ctx.Message.Author.Username)
 
 			return &commands.CommandResponse{Content: msg}, nil
@@ -30,13 +30,15 @@ func init() {
 		Properties: commands.CommandProps{
 			Triggers:    []string{"mock"},
 			Description: "Mock the stupid shit your friend says!",
-			Usage:       "{command} <text to be mocked>",
 			Category:    "Fun Commands",
-			MissingArgs: "You gotta give me something to mock :eyes:",
+			DMAllowed:   true,
 			Permissions: []int64{discordgo.PermissionAttachFiles},
+			Args: []commands.Arg{
+				{Name: "text", Type: commands.ArgText, Description: "Text to be mocked", Missing: "You gotta give me something to mock :eyes:"},
+			},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			text := strings.Join(ctx.Args, " ")
+			text := ctx.ArgString("text")
 
 			// Replace c with k, v with c
 			text = strings.ReplaceAll(text, "c", "k")
@@ -17,8 +17,6 @@
 package fun
 
 import (
-	"strings"
-
 	"github.com/dankmemer/bot/internal/bot"
 	"github.com/dankmemer/bot/internal/commands"
 )
@@ -28,12 +26,13 @@ func init() {
 		Properties: commands.CommandProps{
 			Triggers:    []string{"repeat", "say"},
 			Description: "Make the bot say whatever you want!",
-			Usage:       "{command} <what you want the bot to say>",
 			Category:    "Fun Commands",
-			MissingArgs: "What do you want me to say?",
+			Args: []commands.Arg{
+				{Name: "text", Type: commands.ArgText, Description: "What you want the bot to say", Missing: "What do you want me to say?"},
+			},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			return &commands.CommandResponse{Content: strings.Join(ctx.Args, " ")}, nil
+			return &commands.CommandResponse{Content: ctx.ArgString("text")}, nil
 		},
 	})
 }
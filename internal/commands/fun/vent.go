@@ -18,7 +18,6 @@ package fun
 
 import (
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -33,45 +32,40 @@ func init() {
 			Triggers:    []string{"vent", "bother", "message"},
 			Description: "I know I am your only friend, this should give you someone to vent to",
 			Cooldown:    15000,
-			MissingArgs: "What do you want to vent to me about?",
 			Category:    "Fun Commands",
+			DMAllowed:   true,
+			Args: []commands.Arg{
+				{Name: "message", Type: commands.ArgText, Description: "What you want to vent about", Missing: "What do you want to vent to me about?"},
+			},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			if len(ctx.Args) == 0 {
-				return &commands.CommandResponse{Content: "What do you want to vent to me about?"}, nil
-			}
-
 			// Get config for vent channel
-			botInterface, ok := ctx.Bot.(interface {
-				GetConfig() *utils.Config
-			})
-			if !ok {
-				return &commands.CommandResponse{Content: "My owner is listening to your venting now, what a great listener. amirite ladies???? 😍"}, nil
-			}
-
-			config := botInterface.GetConfig()
-			ventChannelID := config.VentChannel
+			ventChannelID := ctx.Services.Config.VentChannel
 			if ventChannelID == "" {
 				return &commands.CommandResponse{Content: "My owner is listening to your venting now, what a great listener. amirite ladies???? 😍"}, nil
 			}
 
 			// Get channel and guild info
-			channel, err := ctx.Session.State.Channel(ctx.Message.ChannelID)
-			if err != nil {
-				channel, _ = ctx.Session.Channel(ctx.Message.ChannelID)
-			}
-
-			var channelName, guildName, guildID string
-			if channel != nil {
-				channelName = channel.Name
-				guild, err := ctx.Session.State.Guild(channel.GuildID)
+			sentFrom, footer := "DMs", "Sent in DMs"
+			if ctx.Message.GuildID != "" {
+				channel, err := ctx.Session.State.Channel(ctx.Message.ChannelID)
 				if err != nil {
-					guild, _ = ctx.Session.Guild(channel.GuildID)
+					channel, _ = ctx.Session.Channel(ctx.Message.ChannelID)
 				}
-				if guild != nil {
-					guildName = guild.Name
-					guildID = guild.ID
+
+				var channelName, guildName string
+				if channel != nil {
+					channelName = channel.Name
+					guild, err := ctx.Session.State.Guild(channel.GuildID)
+					if err != nil {
+						guild, _ = ctx.Session.Guild(channel.GuildID)
+					}
+					if guild != nil {
+						guildName = guild.Name
+					}
 				}
+				sentFrom = fmt.Sprintf("#%s in %s", channelName, guildName)
+				footer = fmt.Sprintf("Guild ID: %s", ctx.Message.GuildID)
 			}
 
 			// Send vent to channel
@@ -83,17 +77,17 @@ func init() {
 						ctx.Message.Author.Discriminator,
 						ctx.Message.Author.ID),
 				},
-				Description: strings.Join(ctx.Args, " "),
+				Description: ctx.ArgString("message"),
 				Fields: []*discordgo.MessageEmbedField{
 					{
 						Name:  "Sent from:",
-						Value: fmt.Sprintf("#%s in %s", channelName, guildName),
+						Value: sentFrom,
 					},
 				},
 				Color:     utils.RandomColor(),
 				Timestamp: time.Now().Format(time.RFC3339),
 				Footer: &discordgo.MessageEmbedFooter{
-					Text: fmt.Sprintf("Guild ID: %s", guildID),
+					Text: footer,
 				},
 			}
 
@@ -46,7 +46,7 @@ func init() {
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
 			// Get latest comic number
-			latestData, err := external.Get("https://xkcd.com/info.0.json", nil)
+			latestData, err := external.Get(ctx.Context, "https://xkcd.com/info.0.json", nil)
 			if err != nil {
 				return &commands.CommandResponse{Content: "Couldn't fetch xkcd"}, nil
 			}
@@ -58,7 +58,7 @@ func init() {
 
 			// Get random comic
 			randomNum := rand.Intn(latest.Num) + 1
-			comicData, err := external.Get(fmt.Sprintf("https://xkcd.com/%d/info.0.json", randomNum), nil)
+			comicData, err := external.Get(ctx.Context, fmt.Sprintf("https://xkcd.com/%d/info.0.json", randomNum), nil)
 			if err != nil {
 				return &commands.CommandResponse{Content: "Couldn't fetch comic"}, nil
 			}
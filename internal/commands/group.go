@@ -0,0 +1,206 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// Group is a command made of nested subcommands, e.g. "config prefix set".
+// Subcommands may be groups themselves and each has its own props, so
+// permissions, cooldown messages and argument schemas apply per subcommand.
+type Group struct {
+	Properties  CommandProps
+	Subcommands []Command
+	Default     Command // Run with the group's arguments when no subcommand matches, nil shows help
+}
+
+func (g *Group) Props() CommandProps {
+	props := g.Properties
+
+	// Apply defaults
+	if props.Cooldown == 0 {
+		props.Cooldown = 3000
+	}
+	if props.Usage == "" {
+		names := make([]string, 0, len(g.Subcommands))
+		for _, sub := range g.Subcommands {
+			names = append(names, sub.Props().Triggers[0])
+		}
+		props.Usage = "{command} <" + strings.Join(names, "|") + ">"
+	}
+
+	return props
+}
+
+// Find looks up a direct subcommand by trigger name
+func (g *Group) Find(trigger string) Command {
+	trigger = strings.ToLower(trigger)
+	for _, sub := range g.Subcommands {
+		for _, t := range sub.Props().Triggers {
+			if strings.ToLower(t) == trigger {
+				return sub
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Group) Run(ctx *CommandContext) (*CommandResponse, error) {
+	path := ctx.Command
+	if path == "" {
+		path = g.Properties.Triggers[0]
+	}
+
+	if sub := g.Subcommand(ctx.Args); sub != nil {
+		return runSubcommand(ctx, sub, path+" "+sub.Props().Triggers[0], 1)
+	}
+
+	if g.Default != nil {
+		return runSubcommand(ctx, g.Default, path, 0)
+	}
+
+	return &CommandResponse{Embed: GroupHelp(ctx, g, path)}, nil
+}
+
+// Subcommand returns the subcommand picked by the first of args, or nil if
+// the group runs its default or shows its help
+func (g *Group) Subcommand(args []string) Command {
+	if len(args) == 0 {
+		return nil
+	}
+	return g.Find(args[0])
+}
+
+// runSubcommand runs the same checks as the bot does for a command on the
+// subcommand's own props, then runs it with the consumed arguments removed
+func runSubcommand(ctx *CommandContext, sub Command, path string, consumed int) (*CommandResponse, error) {
+	props := sub.Props()
+
+	// Subcommands have their own cooldown, keyed by their path. The bot
+	// applies the top-level group's cooldown to its default, and nested
+	// groups leave it to their leaves.
+	_, nested := sub.(*Group)
+	cooldown := !nested && strings.Contains(path, " ")
+
+	if props.OwnerOnly && !isDev(ctx) {
+		return nil, nil
+	}
+	for _, check := range []func(*CommandContext, CommandProps) *CommandResponse{
+		CheckGuildOnly, CheckUserPermissions, CheckBotPermissions, CheckNSFW, CheckDonator,
+	} {
+		if resp := check(ctx, props); resp != nil {
+			return resp, nil
+		}
+	}
+
+	subCtx := *ctx
+	subCtx.Command = path
+	subCtx.Args = ctx.Args[consumed:]
+	if len(ctx.CleanArgs) >= consumed {
+		subCtx.CleanArgs = ctx.CleanArgs[consumed:]
+	}
+
+	if err := ParseArgs(&subCtx, props); err != nil {
+		return &CommandResponse{Content: err.Error()}, nil
+	}
+
+	if !cooldown {
+		return sub.Run(&subCtx)
+	}
+
+	userID := ctx.Message.Author.ID
+	ms := ScaleCooldown(ctx.Services.Config, props.Cooldown, ctx.DonatorLevel())
+	remaining, err := ctx.Services.DB.ClaimCooldown(ctx.Context, path, userID, ms)
+	if err != nil {
+		return nil, err
+	}
+	if remaining > 0 {
+		return CooldownResponse(props, path, ctx.Locale(), remaining), nil
+	}
+
+	resp, err := sub.Run(&subCtx)
+
+	// Like the bot, restart the cooldown once the command is done and give
+	// it back if it failed. Timeouts keep it.
+	timedOut := errors.Is(ctx.Context.Err(), context.DeadlineExceeded)
+	writeCtx := context.WithoutCancel(ctx.Context)
+	var cdErr error
+	switch {
+	case err == nil:
+		cdErr = ctx.Services.DB.SetCooldown(writeCtx, path, userID, ms)
+	case !timedOut:
+		cdErr = ctx.Services.DB.ClearCooldown(writeCtx, path, userID)
+	}
+	if cdErr != nil {
+		ctx.Services.Logger.Error().Err(cdErr).Str("command", path).Msg("Failed to update cooldown")
+	}
+	return resp, err
+}
+
+// SubcommandTree renders the subcommands of a group as an indented list of
+// usage lines, one per leaf command. path is the group's full invocation.
+func SubcommandTree(g *Group, prefix, path, locale string) string {
+	var lines []string
+	writeSubcommandTree(&lines, g, prefix, path, locale, 0)
+	return strings.Join(lines, "\n")
+}
+
+func writeSubcommandTree(lines *[]string, g *Group, prefix, path, locale string, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, sub := range g.Subcommands {
+		props := sub.Props()
+		if props.OwnerOnly {
+			continue
+		}
+
+		subPath := path + " " + props.Triggers[0]
+		desc := LocalizedDescription(props, subPath, locale)
+		if group, ok := sub.(*Group); ok {
+			*lines = append(*lines, fmt.Sprintf("%s%s %s — %s", indent, prefix, subPath, desc))
+			writeSubcommandTree(lines, group, prefix, subPath, locale, depth+1)
+			continue
+		}
+
+		usage := strings.ReplaceAll(LocalizedUsage(props, subPath, locale), "{command}", prefix+" "+subPath)
+		*lines = append(*lines, fmt.Sprintf("%s%s — %s", indent, usage, desc))
+	}
+}
+
+// GroupHelp builds the help embed of a group, listing its subcommands
+func GroupHelp(ctx *CommandContext, g *Group, path string) *discordgo.MessageEmbed {
+	prefix := ""
+	if ctx.GuildConfig != nil {
+		prefix = ctx.GuildConfig.Prefix
+	}
+	props := g.Props()
+
+	return &discordgo.MessageEmbed{
+		Title:       path,
+		Description: LocalizedDescription(props, path, ctx.Locale()),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: ctx.T("help.subcommands"), Value: "```" + SubcommandTree(g, prefix, path, ctx.Locale()) + "```"},
+		},
+		Color: utils.RandomColor(),
+	}
+}
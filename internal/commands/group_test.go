@@ -0,0 +1,74 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+)
+
+func reply(content string) func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+	return func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		return &commands.CommandResponse{Content: content}, nil
+	}
+}
+
+// newTestGroup is a normal group with an NSFW and a server-only subcommand
+func newTestGroup() *commands.Group {
+	return &commands.Group{
+		Properties: commands.CommandProps{Triggers: []string{"stuff"}, DMAllowed: true},
+		Subcommands: []commands.Command{
+			&commands.BaseCommand{
+				Properties: commands.CommandProps{Triggers: []string{"lewd"}, IsNSFW: true, DMAllowed: true},
+				Handler:    reply("lewd"),
+			},
+			&commands.BaseCommand{
+				Properties: commands.CommandProps{Triggers: []string{"server"}},
+				Handler:    reply("server"),
+			},
+		},
+	}
+}
+
+func TestSubcommandChecks(t *testing.T) {
+	h := commandtest.New()
+	group := newTestGroup()
+
+	resp, err := h.Run(group, "stuff lewd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content == "lewd" || resp.Embed == nil {
+		t.Errorf("NSFW subcommand ran in a normal channel: %+v", resp)
+	}
+
+	ctx := h.Context("stuff server")
+	ctx.Message.GuildID = ""
+	resp, err = group.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content == "server" {
+		t.Error("server-only subcommand ran in DMs")
+	}
+
+	if resp, err := h.Run(group, "stuff server"); err != nil || resp.Content != "server" {
+		t.Errorf("stuff server: got %+v, %v", resp, err)
+	}
+}
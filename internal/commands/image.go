@@ -19,6 +19,7 @@ package commands
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -26,6 +27,11 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
+const (
+	ImageConcurrencyKey = "imgen" // Shared by image commands, they all use the image API
+	ImageConcurrency    = 8       // Image commands running at once by default
+)
+
 // ImageCommand handles image manipulation commands that use an external API
 type ImageCommand struct {
 	Properties   CommandProps
@@ -56,6 +62,18 @@ func (c *ImageCommand) Props() CommandProps {
 		props.Category = "Image Manipulation"
 	}
 
+	// Typing covers checking the input, Run then shows a placeholder while
+	// the image is generated
+	props.Typing = true
+
+	// Bound the requests in flight to the image API
+	if props.ConcurrencyKey == "" {
+		props.ConcurrencyKey = ImageConcurrencyKey
+	}
+	if props.Concurrency == 0 {
+		props.Concurrency = ImageConcurrency
+	}
+
 	// Add required permissions
 	props.Permissions = append(props.Permissions,
 		discordgo.PermissionEmbedLinks,
@@ -74,22 +92,21 @@ func (c *ImageCommand) Run(ctx *CommandContext) (*CommandResponse, error) {
 		return nil, nil // Error already sent
 	}
 
-	// Get the bot interface
-	bot, ok := ctx.Bot.(imageGenBot)
-	if !ok {
-		return nil, fmt.Errorf("bot does not support image generation")
+	// Generating can take a few seconds
+	if err := ctx.Progress("%s", ctx.T("image.generating")); err != nil {
+		ctx.Services.Logger.Error().Err(err).Msg("Failed to send placeholder")
 	}
 
 	// Make API request
 	var imageData []byte
 	if c.RequestURL != "" {
 		url := strings.Replace(c.RequestURL, "$URL", dataSrc, 1)
-		imageData, err = bot.GetImageGen().GenerateCustom("", map[string]string{
+		imageData, err = ctx.Services.ImageGen.GenerateCustom(ctx.Context, "", map[string]string{
 			"url": url,
 		})
 	} else {
 		// Use default API endpoint
-		imageData, err = bot.GetImageGen().Generate("/"+c.Properties.Triggers[0], dataSrc)
+		imageData, err = ctx.Services.ImageGen.Generate(ctx.Context, "/"+c.Properties.Triggers[0], dataSrc)
 	}
 
 	if err != nil {
@@ -113,7 +130,7 @@ func (c *ImageCommand) parseDataSource(ctx *CommandContext) (string, error) {
 	if c.TextOnly {
 		// Text-only command
 		if c.RequiredArgs != "" && len(ctx.Args) == 0 {
-			return "", fmt.Errorf(c.RequiredArgs)
+			return "", errors.New(c.RequiredArgs)
 		}
 
 		text := strings.Join(ctx.Args, " ")
@@ -137,7 +154,7 @@ func (c *ImageCommand) parseDataSource(ctx *CommandContext) (string, error) {
 	}
 
 	if c.RequiredArgs != "" && len(ctx.Args) == 0 {
-		return "", fmt.Errorf(c.RequiredArgs)
+		return "", errors.New(c.RequiredArgs)
 	}
 
 	if c.RequiredArgs != "" {
@@ -200,15 +217,5 @@ func isASCII(s string) bool {
 	return true
 }
 
-// Interface to access bot's image generator
-type imageGenBot interface {
-	GetImageGen() ImageGenerator
-}
-
-type ImageGenerator interface {
-	Generate(endpoint, data string) ([]byte, error)
-	GenerateCustom(endpoint string, params map[string]string) ([]byte, error)
-}
-
 // Mention parsing regex
 var mentionRegex = regexp.MustCompile(`<@!?(\d+)>`)
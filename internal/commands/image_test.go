@@ -0,0 +1,56 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+)
+
+func TestImageCommandShowsPlaceholder(t *testing.T) {
+	h := commandtest.New()
+	cmd := &commands.ImageCommand{
+		Properties:   commands.CommandProps{Triggers: []string{"sign"}},
+		TextOnly:     true,
+		RequiredArgs: "What should the sign say?",
+	}
+
+	resp, err := h.Run(cmd, "sign")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "What should the sign say?" {
+		t.Errorf("got %q, want the missing text message", resp.Content)
+	}
+	if updates := h.Responder.Updates(); len(updates) != 0 {
+		t.Errorf("placeholder %q sent for invalid input", updates)
+	}
+
+	resp, err = h.Run(cmd, "sign hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.File == nil {
+		t.Fatal("got no image")
+	}
+	if got, want := h.Responder.Updates(), []string{"Generating your image..."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("updates %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,90 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"strings"
+
+	"github.com/dankmemer/bot/internal/i18n"
+)
+
+// Locale returns the language the command should reply in
+func (ctx *CommandContext) Locale() string {
+	if ctx.GuildConfig == nil || ctx.GuildConfig.Locale == "" {
+		return i18n.Default
+	}
+	return ctx.GuildConfig.Locale
+}
+
+// T returns a message from the guild's catalog, formatted with args
+func (ctx *CommandContext) T(key string, args ...interface{}) string {
+	return i18n.T(ctx.Locale(), key, args...)
+}
+
+// Plural returns the form of a message for n from the guild's catalog
+func (ctx *CommandContext) Plural(key string, n int64, args ...interface{}) string {
+	return i18n.Plural(ctx.Locale(), key, n, args...)
+}
+
+// LocalizedDescription returns a command's description in a locale. path is
+// the command's full trigger path, e.g. "config prefix", and the catalog key
+// is "commands.config.prefix.description". Falls back to props.Description.
+func LocalizedDescription(props CommandProps, path, locale string) string {
+	if desc, ok := i18n.Lookup(locale, commandKey(path, "description")); ok {
+		return desc
+	}
+	return props.Description
+}
+
+// LocalizedUsage returns a command's usage in a locale, like Usage. The
+// catalog key is "commands.<path>.usage".
+func LocalizedUsage(props CommandProps, path, locale string) string {
+	if usage, ok := i18n.Lookup(locale, commandKey(path, "usage")); ok {
+		return usage
+	}
+	return Usage(props)
+}
+
+// LocalizedCooldownMessage returns a command's cooldown message in a locale,
+// with {cooldown} left for the caller to replace. The catalog key is
+// "commands.<path>.cooldown", falling back to props.CooldownMessage and then
+// the default message.
+func LocalizedCooldownMessage(props CommandProps, path, locale string) string {
+	if msg, ok := i18n.Lookup(locale, commandKey(path, "cooldown")); ok {
+		return msg
+	}
+	if props.CooldownMessage != "" {
+		return props.CooldownMessage
+	}
+	return i18n.T(locale, "cooldown.default")
+}
+
+// localizedArgMessage returns the custom missing or invalid message of an
+// argument in a locale. The catalog key is
+// "commands.<path>.args.<name>.<field>", falling back to fallback.
+func localizedArgMessage(arg Arg, path, field, fallback, locale string) string {
+	if msg, ok := i18n.Lookup(locale, commandKey(path, "args."+arg.Name+"."+field)); ok {
+		return msg
+	}
+	return fallback
+}
+
+// commandKey returns the catalog key of a field of a command, e.g.
+// "commands.config.prefix.description"
+func commandKey(path, field string) string {
+	return "commands." + strings.ReplaceAll(path, " ", ".") + "." + field
+}
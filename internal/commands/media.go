@@ -68,13 +68,10 @@ func (c *MediaCommand) Run(ctx *CommandContext) (*CommandResponse, error) {
 
 	// Get token from config if specified
 	if c.TokenKey != "" {
-		bot, ok := ctx.Bot.(mediaConfigBot)
-		if ok {
-			token := bot.GetConfigValue(c.TokenKey)
-			if token != "" {
-				headers["Authorization"] = token
-				headers["Key"] = token
-			}
+		token := ctx.Services.Config.APIs.Tokens[c.TokenKey]
+		if token != "" {
+			headers["Authorization"] = token
+			headers["Key"] = token
 		}
 	}
 
@@ -85,7 +82,7 @@ func (c *MediaCommand) Run(ctx *CommandContext) (*CommandResponse, error) {
 	if c.JSONKey != "" {
 		// Parse JSON response
 		var result map[string]interface{}
-		err = external.GetJSON(c.RequestURL, headers, &result)
+		err = external.GetJSON(ctx.Context, c.RequestURL, headers, &result)
 		if err != nil {
 			return &CommandResponse{Content: fmt.Sprintf("Error fetching media: %s", err.Error())}, nil
 		}
@@ -98,7 +95,7 @@ func (c *MediaCommand) Run(ctx *CommandContext) (*CommandResponse, error) {
 		}
 	} else {
 		// Use raw response as URL
-		data, err := external.Get(c.RequestURL, headers)
+		data, err := external.Get(ctx.Context, c.RequestURL, headers)
 		if err != nil {
 			return &CommandResponse{Content: fmt.Sprintf("Error fetching media: %s", err.Error())}, nil
 		}
@@ -147,8 +144,3 @@ func (c *MediaCommand) Run(ctx *CommandContext) (*CommandResponse, error) {
 
 	return &CommandResponse{Embed: embed}, nil
 }
-
-// Interface to access bot's config
-type mediaConfigBot interface {
-	GetConfigValue(key string) string
-}
@@ -32,7 +32,7 @@ func init() {
 			Category:    "Memey Commands",
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			data, err := external.Get("https://icanhazdadjoke.com/", map[string]string{
+			data, err := external.Get(ctx.Context, "https://icanhazdadjoke.com/", map[string]string{
 				"Accept": "application/json",
 			})
 			if err != nil {
@@ -0,0 +1,174 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dankmemer/bot/internal/i18n"
+)
+
+// maxJumpOptions is the most options Discord allows in a select menu
+const maxJumpOptions = 25
+
+// PageProvider renders a page of a Paginator, counting from 0
+type PageProvider func(ctx context.Context, page int) (*discordgo.MessageEmbed, error)
+
+// Paginator is a response that pages through embeds. Only the user who ran
+// the command can turn pages, and the controls are removed once they time out.
+type Paginator struct {
+	Pages    []*discordgo.MessageEmbed // Pages known up front
+	Provider PageProvider              // Renders pages on demand when Pages is empty
+	Count    int                       // Number of pages Provider renders
+	Labels   []string                  // Names of the pages in the jump menu (default: "Page n")
+	Start    int                       // Page shown first
+	Timeout  int64                     // Milliseconds before the controls are removed (default: DefaultComponentTimeout)
+
+	locale string // Language of the controls, taken from the command
+}
+
+// Response renders the first page with its controls
+func (p *Paginator) Response(ctx *CommandContext) (*CommandResponse, error) {
+	p.locale = ctx.Locale()
+	return p.render(ctx.Context, p.Start)
+}
+
+func (p *Paginator) count() int {
+	if len(p.Pages) > 0 {
+		return len(p.Pages)
+	}
+	return p.Count
+}
+
+func (p *Paginator) label(page int) string {
+	if page < len(p.Labels) && p.Labels[page] != "" {
+		return p.Labels[page]
+	}
+	return i18n.T(p.locale, "paginator.page", page+1)
+}
+
+func (p *Paginator) page(ctx context.Context, page int) (*discordgo.MessageEmbed, error) {
+	if len(p.Pages) > 0 {
+		return p.Pages[page], nil
+	}
+	if p.Provider == nil {
+		return nil, fmt.Errorf("paginator has no pages")
+	}
+	return p.Provider(ctx, page)
+}
+
+func (p *Paginator) render(ctx context.Context, page int) (*CommandResponse, error) {
+	count := p.count()
+	if count == 0 {
+		return nil, fmt.Errorf("paginator has no pages")
+	}
+	page = max(0, min(page, count-1))
+
+	embed, err := p.page(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	if count == 1 {
+		return &CommandResponse{Embed: embed}, nil
+	}
+
+	// Copy the embed so pages can be rendered again
+	shown := *embed
+	footer := i18n.T(p.locale, "paginator.footer", page+1, count)
+	if embed.Footer != nil && embed.Footer.Text != "" {
+		footer = embed.Footer.Text + " • " + footer
+	}
+	shown.Footer = &discordgo.MessageEmbedFooter{Text: footer}
+	if embed.Footer != nil {
+		shown.Footer.IconURL = embed.Footer.IconURL
+	}
+
+	return &CommandResponse{
+		Embed: &shown,
+		Components: &Components{
+			Rows:    p.controls(page, count),
+			Handler: p.handle(page),
+			Timeout: p.Timeout,
+			Remove:  true,
+		},
+	}, nil
+}
+
+// controls renders the first/previous/next/last buttons and the jump menu
+func (p *Paginator) controls(page, count int) []discordgo.MessageComponent {
+	buttons := discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+		discordgo.Button{Label: "⏮", Style: discordgo.SecondaryButton, CustomID: "first", Disabled: page == 0},
+		discordgo.Button{Label: "◀", Style: discordgo.PrimaryButton, CustomID: "prev", Disabled: page == 0},
+		discordgo.Button{Label: "▶", Style: discordgo.PrimaryButton, CustomID: "next", Disabled: page == count-1},
+		discordgo.Button{Label: "⏭", Style: discordgo.SecondaryButton, CustomID: "last", Disabled: page == count-1},
+	}}
+
+	// Show the pages around the current one when there are too many for the menu
+	from := max(0, min(page-maxJumpOptions/2, count-maxJumpOptions))
+	to := min(count, from+maxJumpOptions)
+
+	options := make([]discordgo.SelectMenuOption, 0, to-from)
+	for i := from; i < to; i++ {
+		options = append(options, discordgo.SelectMenuOption{
+			Label:   p.label(i),
+			Value:   strconv.Itoa(i),
+			Default: i == page,
+		})
+	}
+	jump := discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+		discordgo.SelectMenu{
+			MenuType:    discordgo.StringSelectMenu,
+			CustomID:    "jump",
+			Placeholder: i18n.T(p.locale, "paginator.jump"),
+			Options:     options,
+		},
+	}}
+
+	return []discordgo.MessageComponent{buttons, jump}
+}
+
+// handle returns the handler of the controls shown on a page
+func (p *Paginator) handle(page int) ComponentHandler {
+	return func(ctx *ComponentContext) (*CommandResponse, error) {
+		next := page
+		switch ctx.Action {
+		case "first":
+			next = 0
+		case "prev":
+			next = page - 1
+		case "next":
+			next = page + 1
+		case "last":
+			next = p.count() - 1
+		case "jump":
+			if len(ctx.Values) == 0 {
+				return nil, nil
+			}
+			n, err := strconv.Atoi(ctx.Values[0])
+			if err != nil {
+				return nil, nil
+			}
+			next = n
+		default:
+			return nil, nil
+		}
+		return p.render(ctx.Context, next)
+	}
+}
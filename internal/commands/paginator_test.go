@@ -0,0 +1,86 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+)
+
+// pages is a command paging through count numbered embeds
+func pages(count int) commands.Command {
+	return &commands.BaseCommand{
+		Properties: commands.CommandProps{Triggers: []string{"pages"}},
+		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+			paginator := &commands.Paginator{
+				Count: count,
+				Provider: func(ctx context.Context, page int) (*discordgo.MessageEmbed, error) {
+					return &discordgo.MessageEmbed{Title: fmt.Sprintf("Page %d", page+1)}, nil
+				},
+			}
+			return paginator.Response(ctx)
+		},
+	}
+}
+
+func TestPaginator(t *testing.T) {
+	h := commandtest.New()
+	resp, err := h.Run(pages(3), "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commandtest.AssertGolden(t, "paginator_first", commandtest.Snapshot(resp))
+
+	steps := []struct {
+		action string
+		values []string
+		want   string
+	}{
+		{"next", nil, "Page 2"},
+		{"next", nil, "Page 3"},
+		{"next", nil, "Page 3"}, // Stays on the last page
+		{"first", nil, "Page 1"},
+		{"prev", nil, "Page 1"},
+		{"last", nil, "Page 3"},
+		{"jump", []string{"1"}, "Page 2"},
+	}
+	for _, step := range steps {
+		resp, err = h.Click(resp, step.action, step.values...)
+		if err != nil {
+			t.Fatalf("%s: %v", step.action, err)
+		}
+		if resp.Embed.Title != step.want {
+			t.Fatalf("%s: on %q, want %q", step.action, resp.Embed.Title, step.want)
+		}
+	}
+}
+
+func TestPaginatorSinglePage(t *testing.T) {
+	resp, err := commandtest.New().Run(pages(1), "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Components != nil {
+		t.Error("single page has controls")
+	}
+}
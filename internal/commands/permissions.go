@@ -0,0 +1,164 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// PermissionNames maps Discord permissions to their display names
+var PermissionNames = map[int64]string{
+	discordgo.PermissionSendMessages:      "Send Messages",
+	discordgo.PermissionEmbedLinks:        "Embed Links",
+	discordgo.PermissionAttachFiles:       "Attach Files",
+	discordgo.PermissionReadMessages:      "Read Messages",
+	discordgo.PermissionManageMessages:    "Manage Messages",
+	discordgo.PermissionVoiceConnect:      "Connect (Voice)",
+	discordgo.PermissionVoiceSpeak:        "Speak (Voice)",
+	discordgo.PermissionAddReactions:      "Add Reactions",
+	discordgo.PermissionManageRoles:       "Manage Roles",
+	discordgo.PermissionManageChannels:    "Manage Channels",
+	discordgo.PermissionManageGuild:       "Manage Server",
+	discordgo.PermissionAdministrator:     "Administrator",
+	discordgo.PermissionUseExternalEmojis: "Use External Emojis",
+}
+
+// HasUserPermissions checks whether the invoking user has all of the given
+// permissions in the current channel. Developers always pass.
+func HasUserPermissions(ctx *CommandContext, required int64) bool {
+	if required == 0 || isDev(ctx) {
+		return true
+	}
+	if ctx.Message.GuildID == "" {
+		// Users have no server permissions in DMs
+		return false
+	}
+
+	var perms int64
+	if ctx.Interaction != nil && ctx.Interaction.Member != nil {
+		// Slash commands include the member's resolved permissions
+		perms = ctx.Interaction.Member.Permissions
+	} else {
+		var err error
+		perms, err = ctx.Session.State.UserChannelPermissions(ctx.Message.Author.ID, ctx.Message.ChannelID)
+		if err != nil {
+			perms, err = ctx.Session.UserChannelPermissions(ctx.Message.Author.ID, ctx.Message.ChannelID)
+			if err != nil {
+				return false
+			}
+		}
+	}
+
+	if perms&discordgo.PermissionAdministrator != 0 {
+		return true
+	}
+	return perms&required == required
+}
+
+// CheckUserPermissions returns an error response if the invoking user lacks
+// the command's UserPermissions, or nil if they may use it
+func CheckUserPermissions(ctx *CommandContext, props CommandProps) *CommandResponse {
+	if HasUserPermissions(ctx, props.UserPermissions) {
+		return nil
+	}
+
+	var names []string
+	for perm, name := range PermissionNames {
+		if props.UserPermissions&perm != 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return &CommandResponse{
+		Content: ctx.T("permissions.user", strings.Join(names, "`, `")),
+	}
+}
+
+// permissionGifs show how to grant a permission, by permission
+var permissionGifs = map[int64]string{
+	discordgo.PermissionSendMessages:      "https://i.imgur.com/REDACTEd.gif",
+	discordgo.PermissionEmbedLinks:        "https://i.imgur.com/REDACTEd.gif",
+	discordgo.PermissionAttachFiles:       "https://i.imgur.com/REDACTEd.gif",
+	discordgo.PermissionReadMessages:      "https://i.imgur.com/REDACTEd.gif",
+	discordgo.PermissionManageMessages:    "https://i.imgur.com/REDACTEd.gif",
+	discordgo.PermissionVoiceConnect:      "https://i.imgur.com/REDACTEd.gif",
+	discordgo.PermissionVoiceSpeak:        "https://i.imgur.com/REDACTEd.gif",
+	discordgo.PermissionAddReactions:      "https://i.imgur.com/REDACTEd.gif",
+	discordgo.PermissionManageRoles:       "https://i.imgur.com/REDACTEd.gif",
+	discordgo.PermissionManageChannels:    "https://i.imgur.com/REDACTEd.gif",
+	discordgo.PermissionManageGuild:       "https://i.imgur.com/REDACTEd.gif",
+	discordgo.PermissionAdministrator:     "https://i.imgur.com/REDACTEd.gif",
+	discordgo.PermissionUseExternalEmojis: "https://i.imgur.com/REDACTEd.gif",
+}
+
+// CheckBotPermissions returns an error response if the bot lacks the
+// command's Permissions in the channel, or nil if it has them. DM channels
+// have no permission overwrites, so they always pass.
+func CheckBotPermissions(ctx *CommandContext, props CommandProps) *CommandResponse {
+	if ctx.Message.GuildID == "" || len(props.Permissions) == 0 {
+		return nil
+	}
+
+	s := ctx.Session
+	perms, err := s.State.UserChannelPermissions(s.State.User.ID, ctx.Message.ChannelID)
+	if err != nil {
+		perms, err = s.UserChannelPermissions(s.State.User.ID, ctx.Message.ChannelID)
+		if err != nil {
+			ctx.Services.Logger.Error().Err(err).Msg("Failed to get permissions")
+			return nil // Fail open, let Discord API reject if needed
+		}
+	}
+
+	var missing int
+	var names []string
+	var gifURL string
+	for _, perm := range props.Permissions {
+		if perms&perm != 0 {
+			continue
+		}
+		missing++
+		if name, ok := PermissionNames[perm]; ok {
+			names = append(names, name)
+		}
+		if gif, ok := permissionGifs[perm]; ok && gifURL == "" {
+			gifURL = gif
+		}
+	}
+	if missing == 0 {
+		return nil
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       ctx.T("permissions.bot.title"),
+		Description: ctx.T("permissions.bot.description", strings.Join(names, "`, `")),
+		Color:       utils.RandomColor(),
+	}
+	if gifURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: gifURL}
+	}
+	return &CommandResponse{Embed: embed}
+}
+
+// isDev checks if the invoking user is one of the bot's developers
+func isDev(ctx *CommandContext) bool {
+	return utils.Contains(ctx.Services.Config.Devs, ctx.Message.Author.ID)
+}
@@ -17,9 +17,12 @@
 package commands
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/dankmemer/bot/internal/external"
 	"github.com/dankmemer/bot/internal/utils"
 )
 
@@ -36,6 +39,9 @@ type RedditCommand struct {
 	Properties CommandProps
 	Endpoint   string         // Reddit endpoint (e.g., "/r/memes/top.json")
 	PostType   RedditPostType // Type of posts to fetch
+
+	indexes map[string]int // Guild ID to the index of the next post
+	mu      sync.Mutex
 }
 
 func NewRedditCommand(props CommandProps, endpoint string, postType RedditPostType) *RedditCommand {
@@ -54,6 +60,14 @@ func (c *RedditCommand) Props() CommandProps {
 		props.Cooldown = 3000
 	}
 
+	// Fetching can take a few seconds
+	props.Typing = true
+
+	if len(props.Args) == 0 {
+		props.Args = []Arg{{Name: "random", Type: ArgFlag, Description: "Pick a random post"}}
+	}
+	props.Usage = Usage(props)
+
 	// Add required permissions
 	props.Permissions = append(props.Permissions, discordgo.PermissionEmbedLinks)
 
@@ -61,21 +75,15 @@ func (c *RedditCommand) Props() CommandProps {
 }
 
 func (c *RedditCommand) Run(ctx *CommandContext) (*CommandResponse, error) {
-	// Get the bot interface
-	bot, ok := ctx.Bot.(redditBot)
-	if !ok {
-		return nil, fmt.Errorf("bot does not support Reddit fetching")
-	}
-
 	// Fetch posts
-	var posts []redditPost
+	var posts []external.RedditPost
 	var err error
 
-	client := bot.GetRedditClient()
+	client := ctx.Services.Reddit
 	if c.PostType == RedditPostTypeImage {
-		posts, err = fetchImagePosts(client, c.Endpoint)
+		posts, err = fetchImagePosts(ctx.Context, client, c.Endpoint)
 	} else {
-		posts, err = fetchTextPosts(client, c.Endpoint)
+		posts, err = fetchTextPosts(ctx.Context, client, c.Endpoint)
 	}
 
 	if err != nil {
@@ -86,17 +94,14 @@ func (c *RedditCommand) Run(ctx *CommandContext) (*CommandResponse, error) {
 		return &CommandResponse{Content: "No posts found!"}, nil
 	}
 
-	// Get index for this guild/command
-	cmdName := c.Properties.Triggers[0]
-	index := bot.GetRedditIndex(ctx.Message.GuildID, cmdName)
-
-	if index >= len(posts) {
-		index = 0
+	var post external.RedditPost
+	if ctx.HasFlag("random") {
+		// --random skips the rotation
+		post = utils.RandomInArray(posts)
+	} else {
+		post = posts[c.nextIndex(ctx.Message.GuildID, len(posts))]
 	}
 
-	post := posts[index]
-	bot.IncrementRedditIndex(ctx.Message.GuildID, cmdName, len(posts))
-
 	// Build embed
 	embed := &discordgo.MessageEmbed{
 		Title: utils.TruncateString(post.Title, 256),
@@ -118,34 +123,33 @@ func (c *RedditCommand) Run(ctx *CommandContext) (*CommandResponse, error) {
 	return &CommandResponse{Embed: embed}, nil
 }
 
-// Interface to access bot's Reddit client
-type redditBot interface {
-	GetRedditClient() redditClient
-	GetRedditIndex(guildID, command string) int
-	IncrementRedditIndex(guildID, command string, maxIndex int) int
-}
+// nextIndex returns the index of the post to show in a guild and advances
+// the guild's rotation
+func (c *RedditCommand) nextIndex(guildID string, count int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-type redditClient interface {
-	FetchPosts(endpoint string) ([]redditPost, error)
-}
+	if c.indexes == nil {
+		c.indexes = make(map[string]int)
+	}
+
+	index := c.indexes[guildID]
+	if index >= count {
+		index = 0
+	}
+	c.indexes[guildID] = (index + 1) % count
 
-type redditPost struct {
-	Title     string
-	URL       string
-	Permalink string
-	Author    string
-	Selftext  string
-	PostHint  string
+	return index
 }
 
-// Helper functions to convert from external package types
-func fetchImagePosts(client redditClient, endpoint string) ([]redditPost, error) {
-	posts, err := client.FetchPosts(endpoint)
+// Helper functions to filter posts by type
+func fetchImagePosts(ctx context.Context, client RedditFetcher, endpoint string) ([]external.RedditPost, error) {
+	posts, err := client.FetchPosts(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	var imagePosts []redditPost
+	var imagePosts []external.RedditPost
 	for _, post := range posts {
 		if post.PostHint == "image" || isRedditImageURL(post.URL) {
 			imagePosts = append(imagePosts, post)
@@ -154,13 +158,13 @@ func fetchImagePosts(client redditClient, endpoint string) ([]redditPost, error)
 	return imagePosts, nil
 }
 
-func fetchTextPosts(client redditClient, endpoint string) ([]redditPost, error) {
-	posts, err := client.FetchPosts(endpoint)
+func fetchTextPosts(ctx context.Context, client RedditFetcher, endpoint string) ([]external.RedditPost, error) {
+	posts, err := client.FetchPosts(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	var textPosts []redditPost
+	var textPosts []external.RedditPost
 	for _, post := range posts {
 		if post.Selftext != "" && len(post.Selftext) <= 2000 && len(post.Title) <= 256 {
 			textPosts = append(textPosts, post)
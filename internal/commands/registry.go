@@ -17,13 +17,18 @@
 package commands
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 	"sync"
+
+	"github.com/dankmemer/bot/internal/utils"
 )
 
 // Registry holds all registered commands
 type Registry struct {
 	commands []Command
+	triggers map[string]Command // Lowercased trigger to command
 	mu       sync.RWMutex
 }
 
@@ -31,30 +36,178 @@ type Registry struct {
 func NewRegistry() *Registry {
 	return &Registry{
 		commands: make([]Command, 0),
+		triggers: make(map[string]Command),
 	}
 }
 
-// Register adds a command to the registry
-func (r *Registry) Register(cmd Command) {
+// Register adds a command to the registry. It fails without registering
+// anything if the command has no triggers or claims a trigger that is
+// already taken.
+func (r *Registry) Register(cmd Command) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+
+	props := cmd.Props()
+	if len(props.Triggers) == 0 {
+		return fmt.Errorf("command has no triggers")
+	}
+
+	seen := make(map[string]bool, len(props.Triggers))
+	for _, t := range props.Triggers {
+		trigger := strings.ToLower(t)
+		if trigger == "" {
+			return fmt.Errorf("command %q has an empty trigger", props.Triggers[0])
+		}
+		if seen[trigger] {
+			return fmt.Errorf("command %q lists trigger %q twice", props.Triggers[0], trigger)
+		}
+		if existing, ok := r.triggers[trigger]; ok {
+			return fmt.Errorf("trigger %q of command %q is already registered by %q",
+				trigger, props.Triggers[0], existing.Props().Triggers[0])
+		}
+		seen[trigger] = true
+	}
+
+	if group, ok := cmd.(*Group); ok {
+		if err := checkSubcommandTriggers(group, props.Triggers[0]); err != nil {
+			return err
+		}
+	}
+
+	for trigger := range seen {
+		r.triggers[trigger] = cmd
+	}
 	r.commands = append(r.commands, cmd)
+	return nil
+}
+
+// checkSubcommandTriggers makes sure no two subcommands of a group, at any
+// depth, share a trigger
+func checkSubcommandTriggers(g *Group, path string) error {
+	owners := make(map[string]string)
+	for _, sub := range g.Subcommands {
+		props := sub.Props()
+		if len(props.Triggers) == 0 {
+			return fmt.Errorf("subcommand of %q has no triggers", path)
+		}
+
+		for _, t := range props.Triggers {
+			trigger := strings.ToLower(t)
+			if owner, ok := owners[trigger]; ok {
+				return fmt.Errorf("trigger %q of %q is already registered by %q",
+					trigger, path+" "+props.Triggers[0], path+" "+owner)
+			}
+			owners[trigger] = props.Triggers[0]
+		}
+
+		if group, ok := sub.(*Group); ok {
+			if err := checkSubcommandTriggers(group, path+" "+props.Triggers[0]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // Find looks up a command by trigger name
 func (r *Registry) Find(trigger string) Command {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	return r.triggers[strings.ToLower(trigger)]
+}
 
-	trigger = strings.ToLower(trigger)
-	for _, cmd := range r.commands {
-		for _, t := range cmd.Props().Triggers {
-			if strings.ToLower(t) == trigger {
-				return cmd
-			}
+// Resolve finds the command named by the leading words of a path like
+// "config prefix set", descending into groups. It returns the command, its
+// canonical path of primary triggers and how many words it used, or nil if
+// the first word isn't a command.
+func (r *Registry) Resolve(words []string) (Command, string, int) {
+	if len(words) == 0 {
+		return nil, "", 0
+	}
+	cmd := r.Find(words[0])
+	if cmd == nil {
+		return nil, "", 0
+	}
+
+	path := cmd.Props().Triggers[0]
+	used := 1
+	for _, word := range words[1:] {
+		group, ok := cmd.(*Group)
+		if !ok {
+			break
+		}
+		sub := group.Find(word)
+		if sub == nil {
+			break
 		}
+		cmd = sub
+		path += " " + sub.Props().Triggers[0]
+		used++
 	}
-	return nil
+	return cmd, path, used
+}
+
+// AliasTable returns every registered trigger mapped to the primary trigger
+// of the command that owns it
+func (r *Registry) AliasTable() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	table := make(map[string]string, len(r.triggers))
+	for trigger, cmd := range r.triggers {
+		table[trigger] = cmd.Props().Triggers[0]
+	}
+	return table
+}
+
+// Suggest returns up to max primary triggers whose triggers are close to
+// input by edit distance, closest first. Commands for which skip returns
+// true are never suggested.
+func (r *Registry) Suggest(input string, max int, skip func(Command) bool) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	input = strings.ToLower(input)
+
+	// Allow roughly one typo per three characters
+	threshold := len([]rune(input)) / 3
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	// Keep the closest trigger of every command
+	best := make(map[string]int)
+	for trigger, cmd := range r.triggers {
+		if skip != nil && skip(cmd) {
+			continue
+		}
+
+		distance := utils.Levenshtein(input, trigger)
+		if distance > threshold {
+			continue
+		}
+
+		name := cmd.Props().Triggers[0]
+		if d, ok := best[name]; !ok || distance < d {
+			best[name] = distance
+		}
+	}
+
+	result := make([]string, 0, len(best))
+	for name := range best {
+		result = append(result, name)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if best[result[i]] != best[result[j]] {
+			return best[result[i]] < best[result[j]]
+		}
+		return result[i] < result[j]
+	})
+
+	if len(result) > max {
+		result = result[:max]
+	}
+	return result
 }
 
 // GetAll returns all registered commands
@@ -0,0 +1,92 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/dankmemer/bot/internal/commands"
+)
+
+func newTestRegistry(t *testing.T) *commands.Registry {
+	t.Helper()
+	registry := commands.NewRegistry()
+	for _, triggers := range [][]string{
+		{"meme", "memes"},
+		{"help", "cmds"},
+		{"hello"},
+		{"kill"},
+		{"secret"},
+	} {
+		cmd := &commands.BaseCommand{Properties: commands.CommandProps{Triggers: triggers, OwnerOnly: triggers[0] == "secret"}}
+		if err := registry.Register(cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return registry
+}
+
+func TestSuggest(t *testing.T) {
+	registry := newTestRegistry(t)
+	skipOwner := func(cmd commands.Command) bool { return cmd.Props().OwnerOnly }
+
+	tests := []struct {
+		input string
+		max   int
+		want  []string
+	}{
+		{"memr", 3, []string{"meme"}},
+		{"MEMS", 3, []string{"meme"}},
+		{"helo", 3, []string{"hello", "help"}},
+		{"helo", 1, []string{"hello"}},
+		{"kil", 3, []string{"kill"}},
+		{"secrit", 3, []string{}},
+		{"xyzzy", 3, []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := registry.Suggest(tt.input, tt.max, skipOwner); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Suggest(%q, %d) = %q, want %q", tt.input, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestApplicationCommandsOverflow(t *testing.T) {
+	registry := commands.NewRegistry()
+	for i := range commands.MaxApplicationCommands + 3 {
+		cmd := &commands.BaseCommand{Properties: commands.CommandProps{
+			Triggers:    []string{fmt.Sprintf("cmd%03d", i)},
+			Description: "A command",
+		}}
+		if err := registry.Register(cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, skipped, overflow := registry.ApplicationCommands()
+	if len(result) != commands.MaxApplicationCommands {
+		t.Errorf("registered %d commands, want %d", len(result), commands.MaxApplicationCommands)
+	}
+	if len(skipped) != 0 {
+		t.Errorf("skipped %q, want none", skipped)
+	}
+	if want := []string{"cmd100", "cmd101", "cmd102"}; !reflect.DeepEqual(overflow, want) {
+		t.Errorf("overflow %q, want %q", overflow, want)
+	}
+}
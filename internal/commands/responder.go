@@ -0,0 +1,55 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import "fmt"
+
+// Responder shows that a command is still working. The bot gives every
+// command one; the final response replaces whatever it sent.
+type Responder interface {
+	// Defer sends a placeholder message that the response will replace
+	Defer(placeholder string) error
+	// Typing shows the typing indicator until the response is sent
+	Typing()
+	// Progress replaces the placeholder's content, sending it if needed
+	Progress(content string) error
+}
+
+// Defer sends a placeholder that is edited into the response when the command
+// finishes. Slash commands are always deferred, so it does nothing there.
+func (ctx *CommandContext) Defer(placeholder string) error {
+	if ctx.Responder == nil {
+		return nil
+	}
+	return ctx.Responder.Defer(placeholder)
+}
+
+// Typing shows the typing indicator until the response is sent
+func (ctx *CommandContext) Typing() {
+	if ctx.Responder != nil {
+		ctx.Responder.Typing()
+	}
+}
+
+// Progress updates the placeholder with the command's progress, e.g.
+// ctx.Progress("Downloading %d images...", n)
+func (ctx *CommandContext) Progress(format string, args ...interface{}) error {
+	if ctx.Responder == nil {
+		return nil
+	}
+	return ctx.Responder.Progress(fmt.Sprintf(format, args...))
+}
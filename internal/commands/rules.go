@@ -0,0 +1,103 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"slices"
+
+	"github.com/dankmemer/bot/internal/database"
+)
+
+// MaxCommandRules is the most command rules a guild can have
+const MaxCommandRules = 100
+
+// RuleSubject is where and by whom a command is run, matched against the
+// scopes of command rules
+type RuleSubject struct {
+	GuildID   string // Also the ID of the @everyone role
+	ChannelID string
+	Roles     []string
+	UserID    string
+}
+
+// Rule levels from most to least specific
+const (
+	ruleLevelUser = iota
+	ruleLevelRole
+	ruleLevelChannel
+	ruleLevelEveryone
+	ruleLevels
+)
+
+// level returns how specific a rule is for the subject, or -1 if the rule's
+// scope doesn't match it
+func (s RuleSubject) level(rule database.CommandRule) int {
+	switch rule.Scope {
+	case database.RuleUser:
+		if rule.ScopeID == s.UserID {
+			return ruleLevelUser
+		}
+	case database.RuleRole:
+		if rule.ScopeID == s.GuildID {
+			return ruleLevelEveryone
+		}
+		if slices.Contains(s.Roles, rule.ScopeID) {
+			return ruleLevelRole
+		}
+	case database.RuleChannel:
+		if rule.ScopeID == s.ChannelID {
+			return ruleLevelChannel
+		}
+	}
+	return -1
+}
+
+// ruleTargets reports whether a rule names the command itself (direct) or
+// its category
+func ruleTargets(rule database.CommandRule, props CommandProps, direct bool) bool {
+	if direct {
+		return rule.Command != "" && rule.Command == props.Triggers[0]
+	}
+	return rule.Category != "" && rule.Category == CategoryKey(props.Category)
+}
+
+// EvaluateRules decides whether a command may run for subject and returns
+// the rule that decided, or nil if no rule applies and the command is allowed.
+//
+// The most specific matching rules decide: user rules beat role rules, which
+// beat channel rules, which beat @everyone rules. At each of those a rule for
+// the command beats a rule for its category, and deny beats allow.
+func EvaluateRules(rules []database.CommandRule, props CommandProps, subject RuleSubject) (bool, *database.CommandRule) {
+	for level := 0; level < ruleLevels; level++ {
+		for _, direct := range []bool{true, false} {
+			var decided *database.CommandRule
+			for i := range rules {
+				rule := &rules[i]
+				if subject.level(*rule) != level || !ruleTargets(*rule, props, direct) {
+					continue
+				}
+				if decided == nil || rule.Action == database.RuleDeny {
+					decided = rule
+				}
+			}
+			if decided != nil {
+				return decided.Action == database.RuleAllow, decided
+			}
+		}
+	}
+	return true, nil
+}
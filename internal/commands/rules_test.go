@@ -0,0 +1,98 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/database"
+)
+
+func TestEvaluateRules(t *testing.T) {
+	const (
+		guild   = "1"
+		channel = "2"
+		role    = "3"
+		user    = "4"
+	)
+	subject := commands.RuleSubject{GuildID: guild, ChannelID: channel, Roles: []string{role}, UserID: user}
+	meme := commands.CommandProps{Triggers: []string{"meme"}, Category: "Meme Commands"}
+
+	rule := func(command string, scope database.RuleScope, scopeID string, action database.RuleAction) database.CommandRule {
+		r := database.CommandRule{GuildID: guild, Scope: scope, ScopeID: scopeID, Action: action}
+		if command == "category" {
+			r.Category = commands.CategoryKey(meme.Category)
+		} else {
+			r.Command = command
+		}
+		return r
+	}
+
+	tests := []struct {
+		name  string
+		rules []database.CommandRule
+		want  bool
+	}{
+		{"no rules", nil, true},
+		{"other command", []database.CommandRule{
+			rule("kill", database.RuleChannel, channel, database.RuleDeny),
+		}, true},
+		{"other channel", []database.CommandRule{
+			rule("meme", database.RuleChannel, "9", database.RuleDeny),
+		}, true},
+		{"channel deny", []database.CommandRule{
+			rule("meme", database.RuleChannel, channel, database.RuleDeny),
+		}, false},
+		{"everyone deny", []database.CommandRule{
+			rule("meme", database.RuleRole, guild, database.RuleDeny),
+		}, false},
+		{"channel beats everyone", []database.CommandRule{
+			rule("meme", database.RuleRole, guild, database.RuleDeny),
+			rule("meme", database.RuleChannel, channel, database.RuleAllow),
+		}, true},
+		{"role beats channel", []database.CommandRule{
+			rule("meme", database.RuleChannel, channel, database.RuleDeny),
+			rule("meme", database.RuleRole, role, database.RuleAllow),
+		}, true},
+		{"user beats role", []database.CommandRule{
+			rule("meme", database.RuleRole, role, database.RuleAllow),
+			rule("meme", database.RuleUser, user, database.RuleDeny),
+		}, false},
+		{"command beats category", []database.CommandRule{
+			rule("category", database.RuleChannel, channel, database.RuleDeny),
+			rule("meme", database.RuleChannel, channel, database.RuleAllow),
+		}, true},
+		{"category applies", []database.CommandRule{
+			rule("category", database.RuleChannel, channel, database.RuleDeny),
+		}, false},
+		{"deny beats allow", []database.CommandRule{
+			rule("meme", database.RuleChannel, channel, database.RuleAllow),
+			rule("meme", database.RuleChannel, channel, database.RuleDeny),
+		}, false},
+	}
+
+	for _, tt := range tests {
+		allowed, decided := commands.EvaluateRules(tt.rules, meme, subject)
+		if allowed != tt.want {
+			t.Errorf("%s: allowed %v, want %v", tt.name, allowed, tt.want)
+		}
+		if decided != nil && decided.Action == database.RuleAllow != allowed {
+			t.Errorf("%s: decided by %+v", tt.name, decided)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dankmemer/bot/internal/database"
+	"github.com/dankmemer/bot/internal/external"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// Services are the bot's dependencies available to commands. They are
+// shared by every invocation and must be safe for concurrent use.
+type Services struct {
+	DB       Store
+	Config   *utils.Config
+	Registry *Registry
+	ImageGen ImageGenerator
+	Reddit   RedditFetcher
+	Voice    VoicePlayer
+	Logger   zerolog.Logger
+}
+
+// Store is the part of the database commands use
+type Store interface {
+	GetCoins(ctx context.Context, userID string) (int64, error)
+	AddCoins(ctx context.Context, userID string, amount int64) error
+	GetStats(ctx context.Context) (*database.BotStats, error)
+	UpdateGuildPrefixes(ctx context.Context, guildID string, prefixes []string) error
+	GetUserPrefix(ctx context.Context, userID string) (string, error)
+	SetUserPrefix(ctx context.Context, userID, prefix string) error
+	UpdateGuildSuggestions(ctx context.Context, guildID string, enabled bool) error
+	DisableCommands(ctx context.Context, guildID string, commands []string) error
+	EnableCommands(ctx context.Context, guildID string, commands []string) error
+	DisableCategories(ctx context.Context, guildID string, categories []string) error
+	EnableCategories(ctx context.Context, guildID string, categories []string) error
+	UpdateGuildAliases(ctx context.Context, guildID string, aliases map[string]string) error
+	UpdateGuildLocale(ctx context.Context, guildID, locale string) error
+	UpdateGuildPremium(ctx context.Context, guildID string, premium bool) error
+
+	ClaimCooldown(ctx context.Context, command, userID string, durationMs int64) (int64, error)
+	SetCooldown(ctx context.Context, command, userID string, durationMs int64) error
+	ClearCooldown(ctx context.Context, command, userID string) error
+
+	GetDonator(ctx context.Context, userID string) (*database.Donator, error)
+	GetDonatorLevel(ctx context.Context, userID string) (int, error)
+	GetDonators(ctx context.Context) ([]database.Donator, error)
+	SetDonator(ctx context.Context, userID string, level int, expiresAt time.Time) error
+	RemoveDonator(ctx context.Context, userID string) error
+
+	GetCustomCommand(ctx context.Context, guildID, name string) (*database.CustomCommand, error)
+	GetCustomCommands(ctx context.Context, guildID string) ([]database.CustomCommand, error)
+	SetCustomCommand(ctx context.Context, cmd database.CustomCommand) error
+	DeleteCustomCommand(ctx context.Context, guildID, name string) (bool, error)
+
+	GetCommandRules(ctx context.Context, guildID string) ([]database.CommandRule, error)
+	SetCommandRule(ctx context.Context, rule database.CommandRule) error
+	DeleteCommandRule(ctx context.Context, guildID string, id int64) (bool, error)
+	ClearCommandRules(ctx context.Context, guildID string) (int64, error)
+
+	GetCommandUsageStats(ctx context.Context, guildID string, since time.Time, order database.UsageOrder, limit int) ([]database.CommandUsageStat, error)
+	GetCommandUsageTotals(ctx context.Context, guildID string, since time.Time) (*database.CommandUsageTotals, error)
+}
+
+// ImageGenerator renders images through the image generation API
+type ImageGenerator interface {
+	Generate(ctx context.Context, endpoint, data string) ([]byte, error)
+	GenerateCustom(ctx context.Context, endpoint string, params map[string]string) ([]byte, error)
+}
+
+// RedditFetcher fetches posts from Reddit
+type RedditFetcher interface {
+	FetchPosts(ctx context.Context, endpoint string) ([]external.RedditPost, error)
+}
+
+// VoicePlayer plays audio in voice channels
+type VoicePlayer interface {
+	IsPlaying(guildID string) bool
+	PlayAudio(guildID, channelID, audioPath string) error
+	Stop(guildID string)
+}
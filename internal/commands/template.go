@@ -0,0 +1,204 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// MaxTemplateLength is the longest template a custom command may have
+const MaxTemplateLength = 2000
+
+// Template is a parsed custom command response. Templates are text with:
+//
+//	$author, $mention      Names of the invoker and the first mentioned user
+//	$server, $channel      The server's name and a link to the channel
+//	$args, $1 ... $9       All arguments, or the nth one
+//	{a|b|c}                One of the options, picked at random
+//	{embed}                Send the response as an embed
+//	{title:...} {footer:...} {image:url} {thumbnail:url} {color:#ff0000}
+//	                       Embed fields, which imply {embed}
+type Template struct {
+	nodes []templateNode
+	embed bool
+
+	title, footer, image, thumbnail string
+	color                           int
+}
+
+// templateNode is literal text, or a random choice if options is set
+type templateNode struct {
+	text    string
+	options []string
+}
+
+// templateFields are the {name:value} directives and where they're stored
+var templateFields = map[string]func(t *Template, value string) error{
+	"title":     func(t *Template, v string) error { t.title = v; return nil },
+	"footer":    func(t *Template, v string) error { t.footer = v; return nil },
+	"image":     func(t *Template, v string) error { t.image = v; return nil },
+	"thumbnail": func(t *Template, v string) error { t.thumbnail = v; return nil },
+	"color": func(t *Template, v string) error {
+		color, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(v), "#"), 16, 32)
+		if err != nil || color < 0 || color > 0xFFFFFF {
+			return fmt.Errorf("`%s` isn't a valid color. Use a hex color like `#ff0000`.", v)
+		}
+		t.color = int(color)
+		return nil
+	},
+}
+
+// ParseTemplate parses a custom command response. Its errors are meant to be
+// shown to the user.
+func ParseTemplate(text string) (*Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("The response can't be empty.")
+	}
+	if len(text) > MaxTemplateLength {
+		return nil, fmt.Errorf("The response can't be over %d characters long.", MaxTemplateLength)
+	}
+
+	t := &Template{}
+	rest := text
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			t.nodes = append(t.nodes, templateNode{text: rest})
+			break
+		}
+		closing := strings.IndexByte(rest[open:], '}')
+		if closing < 0 {
+			return nil, errors.New("You opened a `{` without closing it.")
+		}
+		closing += open
+
+		if open > 0 {
+			t.nodes = append(t.nodes, templateNode{text: rest[:open]})
+		}
+		inner := rest[open+1 : closing]
+		rest = rest[closing+1:]
+
+		if strings.Contains(inner, "{") {
+			return nil, errors.New("`{` and `}` can't be nested.")
+		}
+		if strings.ToLower(inner) == "embed" {
+			t.embed = true
+			continue
+		}
+		if name, value, ok := strings.Cut(inner, ":"); ok {
+			if set, ok := templateFields[strings.ToLower(name)]; ok {
+				if err := set(t, value); err != nil {
+					return nil, err
+				}
+				t.embed = true
+				continue
+			}
+		}
+		if strings.Contains(inner, "|") {
+			t.nodes = append(t.nodes, templateNode{options: strings.Split(inner, "|")})
+			continue
+		}
+
+		// Not a directive, keep the braces
+		t.nodes = append(t.nodes, templateNode{text: "{" + inner + "}"})
+	}
+
+	return t, nil
+}
+
+// Embed reports whether the template renders an embed
+func (t *Template) Embed() bool {
+	return t.embed
+}
+
+// Render fills in the template for an invocation
+func (t *Template) Render(ctx *CommandContext) *CommandResponse {
+	vars := templateVariables(ctx)
+
+	var body strings.Builder
+	for _, node := range t.nodes {
+		if node.options != nil {
+			body.WriteString(node.options[rand.Intn(len(node.options))])
+		} else {
+			body.WriteString(node.text)
+		}
+	}
+	text := strings.TrimSpace(vars.Replace(body.String()))
+
+	if !t.embed {
+		return &CommandResponse{Content: text}
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       vars.Replace(t.title),
+		Description: text,
+		Color:       t.color,
+	}
+	if t.footer != "" {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: vars.Replace(t.footer)}
+	}
+	if t.image != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: vars.Replace(t.image)}
+	}
+	if t.thumbnail != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: vars.Replace(t.thumbnail)}
+	}
+	return &CommandResponse{Embed: embed}
+}
+
+// templateVariables returns a replacer for the $variables of an invocation.
+// Values users control can't ping @everyone or @here.
+func templateVariables(ctx *CommandContext) *strings.Replacer {
+	author := ctx.Message.Author.Username
+	mention := author
+	if len(ctx.Message.Mentions) > 0 {
+		mention = ctx.Message.Mentions[0].Username
+	}
+
+	server := ""
+	if guild, err := ctx.Session.State.Guild(ctx.Message.GuildID); err == nil {
+		server = guild.Name
+	}
+
+	pairs := []string{
+		"$author", escapeMassMentions(author),
+		"$mention", escapeMassMentions(mention),
+		"$server", escapeMassMentions(server),
+		"$channel", "<#" + ctx.Message.ChannelID + ">",
+		"$args", escapeMassMentions(strings.Join(ctx.CleanArgs, " ")),
+	}
+	for i := 1; i <= 9; i++ {
+		arg := ""
+		if i <= len(ctx.CleanArgs) {
+			arg = ctx.CleanArgs[i-1]
+		}
+		pairs = append(pairs, "$"+strconv.Itoa(i), escapeMassMentions(arg))
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// escapeMassMentions breaks @everyone and @here with a zero-width space
+func escapeMassMentions(s string) string {
+	s = strings.ReplaceAll(s, "@everyone", "@\u200beveryone")
+	return strings.ReplaceAll(s, "@here", "@\u200bhere")
+}
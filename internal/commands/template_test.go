@@ -0,0 +1,108 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+)
+
+func TestParseTemplateErrors(t *testing.T) {
+	tests := map[string]string{
+		"":                 "can't be empty",
+		"   ":              "can't be empty",
+		"hi {a|b":          "without closing",
+		"{a|{b}}":          "can't be nested",
+		"{color:purple}":   "isn't a valid color",
+		"{color:#1000000}": "isn't a valid color",
+		strings.Repeat("a", commands.MaxTemplateLength+1): "can't be over",
+	}
+
+	for text, want := range tests {
+		_, err := commands.ParseTemplate(text)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseTemplate(%.20q) = %v, want an error containing %q", text, err, want)
+		}
+	}
+}
+
+func TestTemplateRender(t *testing.T) {
+	h := commandtest.New()
+	victim := h.AddUser("victim")
+	ctx := h.Context("slap <@" + victim.ID + "> @everyone hard")
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"$author slaps $mention", "author slaps victim"},
+		{"$1 and $3, not $9", "victim and hard, not"},
+		{"all: $args", "all: victim @​everyone hard"},
+		{"in $server $channel", "in Test Server <#" + commandtest.ChannelID + ">"},
+		{"{not a directive}", "{not a directive}"},
+	}
+
+	for _, tt := range tests {
+		tmpl, err := commands.ParseTemplate(tt.template)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.template, err)
+		}
+		if got := tmpl.Render(ctx).Content; got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateChoice(t *testing.T) {
+	tmpl, err := commands.ParseTemplate("{heads|tails}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := commandtest.New().Context("flip")
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		got := tmpl.Render(ctx).Content
+		if !slices.Contains([]string{"heads", "tails"}, got) {
+			t.Fatalf("got %q, want one of the options", got)
+		}
+		seen[got] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("only saw %v in 100 renders", seen)
+	}
+}
+
+func TestTemplateEmbed(t *testing.T) {
+	tmpl, err := commands.ParseTemplate("{title:Hi $author}{color:#ff0000}{footer:by $mention}{image:https://example.com/$1.png}Welcome!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tmpl.Embed() {
+		t.Fatal("embed fields didn't imply {embed}")
+	}
+
+	resp := tmpl.Render(commandtest.New().Context("greet cat"))
+	commandtest.AssertGolden(t, "template_embed", commandtest.Snapshot(resp))
+	if resp.Embed.Color != 0xff0000 {
+		t.Errorf("color %x, want ff0000", resp.Embed.Color)
+	}
+}
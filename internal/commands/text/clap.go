@@ -28,9 +28,11 @@ func init() {
 		Properties: commands.CommandProps{
 			Triggers:    []string{"clap"},
 			Description: "Make the bot say whatever you want with sass!",
-			Usage:       "{command} <what you want the bot to say>",
 			Category:    "Text Commands",
-			MissingArgs: "What do you want me to say?",
+			DMAllowed:   true,
+			Args: []commands.Arg{
+				{Name: "text", Type: commands.ArgText, Description: "What you want the bot to say", Missing: "What do you want me to say?"},
+			},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
 			text := strings.Join(ctx.Args, " 👏 ")
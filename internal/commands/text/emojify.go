@@ -47,12 +47,14 @@ func init() {
 		Properties: commands.CommandProps{
 			Triggers:    []string{"emojify"},
 			Description: "Make the bot say whatever you want with emojis!",
-			Usage:       "{command} <what you want the bot to say>",
 			Category:    "Text Commands",
-			MissingArgs: "What do you want me to put into emojis?",
+			DMAllowed:   true,
+			Args: []commands.Arg{
+				{Name: "text", Type: commands.ArgText, Description: "What you want the bot to say", Missing: "What do you want me to put into emojis?"},
+			},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			text := strings.ToLower(strings.Join(ctx.Args, " "))
+			text := strings.ToLower(ctx.ArgString("text"))
 
 			var result strings.Builder
 			for _, letter := range text {
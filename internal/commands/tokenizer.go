@@ -0,0 +1,236 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize splits command content into positional arguments and flags.
+//
+//   - Whitespace separates arguments
+//   - "double quotes" (or “smart quotes”) group words into one argument
+//   - \" is a literal quote, inside or outside of quotes
+//   - `code` and ```code blocks``` are kept verbatim as one argument
+//   - --flag sets a flag to "", --key=value sets it to value
+//   - A lone -- stops flag parsing, everything after it is positional
+//
+// Only the flags named in declared are taken out, other --words are kept as
+// arguments. Without declared flags, -- is an argument too.
+func Tokenize(content string, declared ...string) (args []string, flags map[string]string) {
+	flags = make(map[string]string)
+	runes := []rune(content)
+
+	flagsDone := len(declared) == 0
+	for i := 0; ; {
+		token, quoted, next, ok := scanToken(runes, i)
+		if !ok {
+			break
+		}
+		i = next
+
+		switch {
+		case !quoted && !flagsDone && token == "--":
+			flagsDone = true
+		case !quoted && !flagsDone && isFlag(token, declared):
+			name, value, _ := strings.Cut(token[2:], "=")
+			flags[strings.ToLower(name)] = value
+		default:
+			args = append(args, token)
+		}
+	}
+
+	return args, flags
+}
+
+// TextAfter returns content after its first n positional arguments,
+// untokenized so quotes, flags and newlines are kept. declared are the flags
+// Tokenize took out, which don't count as arguments.
+func TextAfter(content string, n int, declared ...string) string {
+	runes := []rune(content)
+
+	flagsDone := len(declared) == 0
+	for i := 0; ; {
+		start := i
+		for start < len(runes) && unicode.IsSpace(runes[start]) {
+			start++
+		}
+		if n == 0 {
+			return string(runes[start:])
+		}
+
+		token, quoted, next, ok := scanToken(runes, i)
+		if !ok {
+			return ""
+		}
+		i = next
+
+		switch {
+		case !quoted && !flagsDone && token == "--":
+			flagsDone = true
+		case !quoted && !flagsDone && isFlag(token, declared):
+		default:
+			n--
+		}
+	}
+}
+
+// scanToken reads the token starting at or after runes[i], returning it,
+// whether it started with a quote and the index after it. ok is false when
+// only whitespace is left.
+func scanToken(runes []rune, i int) (token string, quoted bool, next int, ok bool) {
+	for i < len(runes) && unicode.IsSpace(runes[i]) {
+		i++
+	}
+	if i == len(runes) {
+		return "", false, i, false
+	}
+
+	var current strings.Builder
+	for ; i < len(runes) && !unicode.IsSpace(runes[i]); i++ {
+		r := runes[i]
+
+		switch {
+		case r == '\\' && i+1 < len(runes) && isQuote(runes[i+1]):
+			// Escaped quote
+			current.WriteRune(runes[i+1])
+			i++
+
+		case r == '`':
+			// Code spans and blocks are copied verbatim
+			fence := "`"
+			if strings.HasPrefix(string(runes[i:]), "```") {
+				fence = "```"
+			}
+			end := strings.Index(string(runes[i+len(fence):]), fence)
+			if end < 0 {
+				current.WriteString(string(runes[i:]))
+				return current.String(), quoted, len(runes), true
+			}
+			block := string(runes[i:i+len(fence)]) + string(runes[i+len(fence):])[:end] + fence
+			current.WriteString(block)
+			i += len([]rune(block)) - 1
+
+		case isQuote(r):
+			if current.Len() == 0 && !quoted {
+				quoted = true
+			}
+
+			// Read until the closing quote, honoring escapes
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && isQuote(runes[i+1]) {
+					current.WriteRune(runes[i+1])
+					i++
+					continue
+				}
+				if isQuote(runes[i]) {
+					break
+				}
+				current.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return current.String(), quoted, i, true
+			}
+
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	return current.String(), quoted, i, true
+}
+
+func isQuote(r rune) bool {
+	return r == '"' || r == '“' || r == '”'
+}
+
+// isFlag reports whether token is --name or --name=value for a declared name
+func isFlag(token string, declared []string) bool {
+	if !strings.HasPrefix(token, "--") {
+		return false
+	}
+	name, _, _ := strings.Cut(token[2:], "=")
+	for _, flag := range declared {
+		if strings.EqualFold(name, flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeclaredFlags returns the flags declared by the command that args, the
+// words after cmd's trigger, would run, following subcommands of groups
+func DeclaredFlags(cmd Command, args []string) []string {
+	for {
+		group, ok := cmd.(*Group)
+		if !ok {
+			break
+		}
+		sub := group.Subcommand(args)
+		if sub == nil {
+			break
+		}
+		cmd, args = sub, args[1:]
+	}
+	return FlagNames(cmd.Props())
+}
+
+// FlagNames returns the names of the ArgFlag arguments of a command
+func FlagNames(props CommandProps) []string {
+	var names []string
+	for _, arg := range props.Args {
+		if arg.Type == ArgFlag {
+			names = append(names, arg.Name)
+		}
+	}
+	return names
+}
+
+// Flag returns the value of a --flag or --flag=value option and whether it was set
+func (ctx *CommandContext) Flag(name string) (string, bool) {
+	value, ok := ctx.Flags[strings.ToLower(name)]
+	return value, ok
+}
+
+// HasFlag reports whether a --flag option was set
+func (ctx *CommandContext) HasFlag(name string) bool {
+	_, ok := ctx.Flags[strings.ToLower(name)]
+	return ok
+}
+
+// RawArgs returns the message content after the command path, untokenized,
+// so quotes, flags and newlines are kept. Useful for free text like custom
+// command responses.
+func (ctx *CommandContext) RawArgs() string {
+	rest := ctx.Content
+	for range strings.Fields(ctx.Command) {
+		_, rest = CutWord(rest)
+	}
+	return rest
+}
+
+// CutWord splits off the first whitespace-separated word of s, returning it
+// and the rest with leading whitespace trimmed
+func CutWord(s string) (word, rest string) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	end := strings.IndexFunc(s, unicode.IsSpace)
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], strings.TrimLeftFunc(s[end:], unicode.IsSpace)
+}
@@ -0,0 +1,82 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package commands_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dankmemer/bot/internal/commands"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		content  string
+		declared []string
+		args     []string
+		flags    map[string]string
+	}{
+		{`kill @user`, nil, []string{"kill", "@user"}, map[string]string{}},
+		{`say "hello world" twice`, nil, []string{"say", "hello world", "twice"}, map[string]string{}},
+		{`say “smart quotes”`, nil, []string{"say", "smart quotes"}, map[string]string{}},
+		{`say \"literal\"`, nil, []string{"say", `"literal"`}, map[string]string{}},
+		{`say ""`, nil, []string{"say", ""}, map[string]string{}},
+		{"run `a b` ```c\nd```", nil, []string{"run", "`a b`", "```c\nd```"}, map[string]string{}},
+		{`clean 5 --bots --limit=3`, []string{"bots", "limit"}, []string{"clean", "5"}, map[string]string{"bots": "", "limit": "3"}},
+		{`clean 5 --BOTS`, []string{"bots"}, []string{"clean", "5"}, map[string]string{"bots": ""}},
+		{`clean 5 --bots --all`, []string{"bots"}, []string{"clean", "5", "--all"}, map[string]string{"bots": ""}},
+		{`say use --force`, nil, []string{"say", "use", "--force"}, map[string]string{}},
+		{`say -- --bots`, nil, []string{"say", "--", "--bots"}, map[string]string{}},
+		{`clean -- --bots`, []string{"bots"}, []string{"clean", "--bots"}, map[string]string{}},
+		{`clean "--bots"`, []string{"bots"}, []string{"clean", "--bots"}, map[string]string{}},
+		{`math --5`, []string{"bots"}, []string{"math", "--5"}, map[string]string{}},
+		{`say "unterminated`, nil, []string{"say", "unterminated"}, map[string]string{}},
+		{"   ", nil, nil, map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		args, flags := commands.Tokenize(tt.content, tt.declared...)
+		if !reflect.DeepEqual(args, tt.args) || !reflect.DeepEqual(flags, tt.flags) {
+			t.Errorf("Tokenize(%q, %q) = %q %v, want %q %v", tt.content, tt.declared, args, flags, tt.args, tt.flags)
+		}
+	}
+}
+
+func TestTextAfter(t *testing.T) {
+	tests := []struct {
+		content  string
+		n        int
+		declared []string
+		want     string
+	}{
+		{`hello "world"`, 0, nil, `hello "world"`},
+		{`  hello world`, 0, nil, `hello world`},
+		{`#general say "hi" -- --loud`, 1, []string{"loud"}, `say "hi" -- --loud`},
+		{`"two words" rest of it`, 1, nil, `rest of it`},
+		{`--flag word rest`, 1, []string{"flag"}, `rest`},
+		{`--flag word rest`, 1, nil, `word rest`},
+		{`one`, 1, nil, ``},
+		{`one`, 2, nil, ``},
+		{"a ```b c``` d\ne", 2, nil, "d\ne"},
+	}
+
+	for _, tt := range tests {
+		if got := commands.TextAfter(tt.content, tt.n, tt.declared...); got != tt.want {
+			t.Errorf("TextAfter(%q, %d) = %q, want %q", tt.content, tt.n, got, tt.want)
+		}
+	}
+}
@@ -17,29 +17,47 @@
 package commands
 
 import (
+	"context"
+
 	"github.com/bwmarrin/discordgo"
 	"github.com/dankmemer/bot/internal/database"
 )
 
 // CommandContext holds all context for command execution
 type CommandContext struct {
+	Context     context.Context // Cancelled when the command times out or the bot shuts down
 	Session     *discordgo.Session
 	Message     *discordgo.MessageCreate
-	Args        []string   // Arguments after command
-	CleanArgs   []string   // Arguments with mentions resolved to usernames
+	Content     string            // Message content after the prefix, see RawArgs
+	Args        []string          // Arguments after command
+	CleanArgs   []string          // Arguments with mentions resolved to usernames
+	Flags       map[string]string // --flag and --key=value options
 	GuildConfig *database.GuildConfig
-	Bot         interface{} // Will be *bot.Bot, using interface to avoid circular import
+	Services    *Services              // The bot's database, config, API clients and so on
+	Interaction *discordgo.Interaction // Set when invoked as a slash command
+	Command     string                 // Full path of the running command, e.g. "config prefix set"
+	Responder   Responder              // Sends placeholders and progress updates
+
+	values       map[string]interface{} // Arguments parsed from the command's schema
+	donatorLevel int                    // Looked up by DonatorLevel
+	donatorKnown bool                   // Whether donatorLevel was looked up
 }
 
 // CommandResponse represents the result of command execution
 type CommandResponse struct {
-	Content string                  // Plain text response
-	Embed   *discordgo.MessageEmbed // Embed response
-	File    *discordgo.File         // File attachment
-	Files   []*discordgo.File       // Multiple file attachments
-	Reply   bool                    // Whether to mention author in reply
+	Content  string                  // Plain text response
+	Embed    *discordgo.MessageEmbed // Embed response
+	File     *discordgo.File         // File attachment
+	Files    []*discordgo.File       // Multiple file attachments
+	Reply    bool                    // Whether to mention author in reply
+	FollowUp bool                    // Send as a new message after the placeholder instead of editing it
+
+	Components *Components // Buttons and select menus
 }
 
+// DefaultTimeout is the timeout in milliseconds of commands that don't set one
+const DefaultTimeout = 30000
+
 // CommandProps defines command metadata
 type CommandProps struct {
 	Triggers        []string // Command triggers (first is primary)
@@ -48,10 +66,18 @@ type CommandProps struct {
 	Category        string   // Category name
 	Cooldown        int64    // Cooldown in milliseconds (default: 3000)
 	CooldownMessage string   // Custom cooldown message ({cooldown} is replaced with time)
+	Timeout         int64    // Timeout in milliseconds (default: DefaultTimeout)
+	Placeholder     string   // Sent before the command runs and edited into the response
+	Typing          bool     // Show the typing indicator while the command runs
 	Permissions     []int64  // Required Discord permissions
+	UserPermissions int64    // Discord permissions the invoking user needs (devs bypass)
 	IsNSFW          bool     // NSFW flag
 	OwnerOnly       bool     // Developer-only flag
-	MissingArgs     string   // Message when args required but missing
+	DMAllowed       bool     // Usable in DMs, where guild settings don't apply
+	DonatorLevel    int      // Donator tier needed, 0 for everyone (devs bypass)
+	Concurrency     int      // Most runs at once across the bot, 0 for no limit
+	ConcurrencyKey  string   // Commands sharing a Concurrency limit (default: the primary trigger)
+	Args            []Arg    // Typed argument schema, parsed before Run
 }
 
 // Command is the base interface all commands must implement
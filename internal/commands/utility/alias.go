@@ -0,0 +1,179 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utility
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dankmemer/bot/internal/bot"
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+func init() {
+	bot.Register(&commands.Group{
+		Properties: commands.CommandProps{
+			Triggers:    []string{"alias", "aliases"},
+			Description: "Give commands your own shorthand on this server",
+			Category:    "Utility",
+		},
+		Subcommands: []commands.Command{aliasAddCommand, aliasRemoveCommand, aliasListCommand},
+	})
+}
+
+var aliasAddCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:        []string{"add", "set"},
+		Description:     "Add an alias for a command",
+		Cooldown:        5000,
+		UserPermissions: discordgo.PermissionManageServer,
+		Args: []commands.Arg{
+			{
+				Name:        "alias",
+				Type:        commands.ArgWord,
+				Description: "The shorthand",
+				Missing:     "What should the alias be?\n\nExample: `{prefix} alias add m meme`",
+			},
+			{
+				Name:        "command",
+				Type:        commands.ArgText,
+				Description: "The command it runs, optionally with arguments",
+				Missing:     "Which command should the alias run?\n\nExample: `{prefix} alias add m meme`",
+			},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		alias := strings.ToLower(ctx.ArgString("alias"))
+		aliases := ctx.GuildConfig.Aliases
+
+		if !customNameRegex.MatchString(alias) {
+			return &commands.CommandResponse{Content: "Aliases can only have letters, numbers, `-` and `_`, and can't be over 32 characters long."}, nil
+		}
+		if ctx.Services.Registry.Find(alias) != nil {
+			return &commands.CommandResponse{Content: fmt.Sprintf("`%s` is already one of my commands, aliases can't replace them.", alias)}, nil
+		}
+		if target, ok := aliases[alias]; ok {
+			return &commands.CommandResponse{
+				Content: fmt.Sprintf("`%s` is already an alias for `%s`. Remove it first with `%s alias remove %s`.", alias, target, ctx.GuildConfig.Prefix, alias),
+			}, nil
+		}
+		if len(aliases) >= commands.MaxAliases {
+			return &commands.CommandResponse{Content: fmt.Sprintf("You can't have more than %d aliases.", commands.MaxAliases)}, nil
+		}
+
+		custom, err := ctx.Services.DB.GetCustomCommand(ctx.Context, ctx.Message.GuildID, alias)
+		if err != nil {
+			return nil, err
+		}
+		if custom != nil {
+			return &commands.CommandResponse{Content: fmt.Sprintf("`%s` is already a custom command.", alias)}, nil
+		}
+
+		// Keep the target's quotes and flags as typed
+		_, target := commands.CutWord(ctx.RawArgs())
+		words, _ := commands.Tokenize(target)
+		name := strings.ToLower(words[0])
+		if _, ok := aliases[name]; ok {
+			return &commands.CommandResponse{Content: "Aliases can't point to other aliases."}, nil
+		}
+		if cmd, _, _ := ctx.Services.Registry.Resolve(words); cmd == nil || cmd.Props().OwnerOnly {
+			custom, err := ctx.Services.DB.GetCustomCommand(ctx.Context, ctx.Message.GuildID, name)
+			if err != nil {
+				return nil, err
+			}
+			if custom == nil {
+				return &commands.CommandResponse{Content: fmt.Sprintf("`%s` isn't a command.", name)}, nil
+			}
+		}
+
+		updated := maps.Clone(aliases)
+		if updated == nil {
+			updated = map[string]string{}
+		}
+		updated[alias] = target
+		if err := ctx.Services.DB.UpdateGuildAliases(ctx.Context, ctx.Message.GuildID, updated); err != nil {
+			return nil, err
+		}
+
+		return &commands.CommandResponse{
+			Content: fmt.Sprintf("`%[1]s %[2]s` now runs `%[1]s %[3]s`.", ctx.GuildConfig.Prefix, alias, target),
+		}, nil
+	},
+}
+
+var aliasRemoveCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:        []string{"remove", "delete"},
+		Description:     "Remove an alias",
+		Cooldown:        5000,
+		UserPermissions: discordgo.PermissionManageServer,
+		Args: []commands.Arg{
+			{Name: "alias", Type: commands.ArgWord, Description: "The alias to remove"},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		alias := strings.ToLower(ctx.ArgString("alias"))
+		if _, ok := ctx.GuildConfig.Aliases[alias]; !ok {
+			return &commands.CommandResponse{Content: fmt.Sprintf("There's no alias called `%s`.", alias)}, nil
+		}
+
+		updated := maps.Clone(ctx.GuildConfig.Aliases)
+		delete(updated, alias)
+		if err := ctx.Services.DB.UpdateGuildAliases(ctx.Context, ctx.Message.GuildID, updated); err != nil {
+			return nil, err
+		}
+
+		return &commands.CommandResponse{Content: fmt.Sprintf("Removed the alias `%s`.", alias)}, nil
+	},
+}
+
+var aliasListCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"list"},
+		Description: "List this server's aliases",
+		Permissions: []int64{discordgo.PermissionEmbedLinks},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		aliases := ctx.GuildConfig.Aliases
+		if len(aliases) == 0 {
+			return &commands.CommandResponse{
+				Content: fmt.Sprintf("This server has no aliases. Add one with `%s alias add <alias> <command>`.", ctx.GuildConfig.Prefix),
+			}, nil
+		}
+
+		names := make([]string, 0, len(aliases))
+		for alias := range aliases {
+			names = append(names, alias)
+		}
+		sort.Strings(names)
+
+		lines := make([]string, len(names))
+		for i, alias := range names {
+			lines[i] = fmt.Sprintf("`%s` → `%s`", alias, aliases[alias])
+		}
+
+		return &commands.CommandResponse{Embed: &discordgo.MessageEmbed{
+			Title:       fmt.Sprintf("Aliases (%d/%d)", len(aliases), commands.MaxAliases),
+			Description: strings.Join(lines, "\n"),
+			Color:       utils.RandomColor(),
+		}}, nil
+	},
+}
@@ -0,0 +1,166 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utility
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dankmemer/bot/internal/bot"
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/database"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// usageReportSize is the most commands listed per ranking
+const usageReportSize = 10
+
+// usageWindows are the time windows usage reports cover
+var usageWindows = map[string]time.Duration{
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+}
+
+var usageWindowArg = commands.Arg{
+	Name:        "window",
+	Type:        commands.ArgChoice,
+	Description: "How far back to look (default: week)",
+	Choices:     []string{"day", "week", "month"},
+	Optional:    true,
+}
+
+func init() {
+	bot.Register(&commands.BaseCommand{
+		Properties: commands.CommandProps{
+			Triggers:    []string{"analytics", "usage"},
+			Description: "See which commands run the most, slowest and worst",
+			Category:    "Utility Commands",
+			OwnerOnly:   true,
+			Permissions: []int64{discordgo.PermissionEmbedLinks},
+			Args:        []commands.Arg{usageWindowArg},
+		},
+		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+			return usageReport(ctx, "")
+		},
+	})
+
+	bot.Register(&commands.BaseCommand{
+		Properties: commands.CommandProps{
+			Triggers:    []string{"topcommands", "topcmds"},
+			Description: "See the most used commands in this server",
+			Category:    "Utility",
+			Cooldown:    10000,
+			Permissions: []int64{discordgo.PermissionEmbedLinks},
+			Args:        []commands.Arg{usageWindowArg},
+		},
+		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+			return usageReport(ctx, ctx.Message.GuildID)
+		},
+	})
+}
+
+// usageReport ranks the commands run in a guild, or in every guild if
+// guildID is empty
+func usageReport(ctx *commands.CommandContext, guildID string) (*commands.CommandResponse, error) {
+	if !ctx.Services.Config.Analytics.Enabled {
+		return &commands.CommandResponse{Content: ctx.T("analytics.disabled")}, nil
+	}
+
+	window := ctx.ArgString("window")
+	if window == "" {
+		window = "week"
+	}
+	since := time.Now().Add(-usageWindows[window])
+	period := ctx.T("analytics.window." + window)
+
+	db := ctx.Services.DB
+	totals, err := db.GetCommandUsageTotals(ctx.Context, guildID, since)
+	if err != nil {
+		return nil, err
+	}
+	if totals.Uses == 0 {
+		return &commands.CommandResponse{Content: ctx.T("analytics.empty", period)}, nil
+	}
+
+	used, err := db.GetCommandUsageStats(ctx.Context, guildID, since, database.UsageByUses, usageReportSize)
+	if err != nil {
+		return nil, err
+	}
+	slowest, err := db.GetCommandUsageStats(ctx.Context, guildID, since, database.UsageByDuration, usageReportSize)
+	if err != nil {
+		return nil, err
+	}
+	failing, err := db.GetCommandUsageStats(ctx.Context, guildID, since, database.UsageByFailures, usageReportSize)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := ctx.Plural("analytics.commands", totals.Uses)
+	users := ctx.Plural("analytics.users", totals.Users)
+	failed := float64(totals.Failures) * 100 / float64(totals.Uses)
+
+	title := ctx.T("analytics.title", period)
+	summary := ctx.T("analytics.summary", runs, users, totals.Failures, failed)
+	if guildID == "" {
+		servers := ctx.Plural("analytics.servers", totals.Guilds)
+		summary = ctx.T("analytics.summary_global", runs, users, servers, totals.Failures, failed)
+	} else if guild, err := ctx.Session.State.Guild(guildID); err == nil {
+		title = ctx.T("analytics.title_guild", guild.Name, period)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: summary,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: ctx.T("analytics.most_used"), Value: usageLines(ctx, used, func(s database.CommandUsageStat) string {
+				return ctx.Plural("analytics.uses", s.Uses)
+			})},
+			{Name: ctx.T("analytics.slowest"), Value: usageLines(ctx, slowest, func(s database.CommandUsageStat) string {
+				return ctx.T("analytics.duration", formatMillis(s.AvgDuration), formatMillis(s.MaxDuration))
+			})},
+			{Name: ctx.T("analytics.most_failing"), Value: usageLines(ctx, failing, func(s database.CommandUsageStat) string {
+				return ctx.T("analytics.failures", s.Failures, s.Uses)
+			})},
+		},
+		Color: utils.RandomColor(),
+	}
+
+	return &commands.CommandResponse{Embed: embed}, nil
+}
+
+// usageLines renders a ranking, one command per line
+func usageLines(ctx *commands.CommandContext, stats []database.CommandUsageStat, detail func(database.CommandUsageStat) string) string {
+	if len(stats) == 0 {
+		return ctx.T("analytics.nothing")
+	}
+	lines := make([]string, 0, len(stats))
+	for i, s := range stats {
+		lines = append(lines, fmt.Sprintf("%d. `%s` — %s", i+1, s.Command, detail(s)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatMillis formats a duration like "850ms" or "2.3s"
+func formatMillis(ms int64) string {
+	if ms < 1000 {
+		return fmt.Sprintf("%dms", ms)
+	}
+	return fmt.Sprintf("%.1fs", float64(ms)/1000)
+}
@@ -0,0 +1,70 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utility_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+	"github.com/dankmemer/bot/internal/database"
+)
+
+func TestTopCommandsLocalized(t *testing.T) {
+	h := newHarness(t)
+	h.Config.Analytics.Enabled = true
+	h.Store.AddUsage(
+		database.CommandUsage{Command: "meme", GuildID: commandtest.GuildID, UserHash: "a", Duration: 120, Outcome: database.OutcomeOK},
+		database.CommandUsage{Command: "meme", GuildID: commandtest.GuildID, UserHash: "b", Duration: 80, Outcome: database.OutcomeOK},
+		database.CommandUsage{Command: "ping", GuildID: commandtest.GuildID, UserHash: "a", Duration: 10, Outcome: database.OutcomeOK},
+	)
+	if err := h.Store.UpdateGuildLocale(context.Background(), commandtest.GuildID, "de"); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := h.Exec("topcommands")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Embed == nil {
+		t.Fatalf("got %q, want a report", resp.Content)
+	}
+	if !strings.Contains(resp.Embed.Description, "**3** Befehle von **2** Nutzern") {
+		t.Errorf("got summary %q", resp.Embed.Description)
+	}
+
+	used := resp.Embed.Fields[0]
+	if used.Name != "Am häufigsten genutzt" {
+		t.Errorf("got field %q", used.Name)
+	}
+	if !strings.Contains(used.Value, "`meme` — 2 Nutzungen") || !strings.Contains(used.Value, "`ping` — 1 Nutzung") {
+		t.Errorf("got ranking %q", used.Value)
+	}
+}
+
+func TestTopCommandsDisabled(t *testing.T) {
+	h := newHarness(t)
+
+	resp, err := h.Exec("topcommands")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "Command usage isn't being recorded on this bot." {
+		t.Errorf("got %q", resp.Content)
+	}
+}
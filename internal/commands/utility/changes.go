@@ -27,6 +27,7 @@ func init() {
 			Triggers:    []string{"changes", "changelog"},
 			Description: "Come check out our updates!",
 			Category:    "Utility Commands",
+			DMAllowed:   true,
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
 			return &commands.CommandResponse{
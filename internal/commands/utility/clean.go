@@ -17,7 +17,6 @@
 package utility
 
 import (
-	"strconv"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -36,16 +35,23 @@ func init() {
 				discordgo.PermissionManageMessages,
 				discordgo.PermissionReadMessageHistory,
 			},
+			Args: []commands.Arg{
+				{Name: "count", Type: commands.ArgInteger, Description: "How many messages to clean", Optional: true, Min: 1, Max: 100},
+				{Name: "bots", Type: commands.ArgFlag, Description: "Also clean up after other bots"},
+			},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
 			// Get the bot's user ID
 			botUserID := ctx.Session.State.User.ID
 
 			// Determine how many messages to clean
-			count := 10
-			if len(ctx.Args) > 0 {
-				if num, err := strconv.Atoi(ctx.Args[0]); err == nil && num > 0 && num <= 100 {
-					count = num
+			count := int(ctx.ArgInt("count", 10))
+
+			// --bots also cleans up after other bots, which needs Manage Messages
+			includeBots := ctx.HasFlag("bots")
+			if includeBots {
+				if resp := commands.CheckUserPermissions(ctx, commands.CommandProps{UserPermissions: discordgo.PermissionManageMessages}); resp != nil {
+					return resp, nil
 				}
 			}
 
@@ -60,7 +66,7 @@ func init() {
 			var toDelete []string
 
 			for _, msg := range messages {
-				if msg.Author.ID == botUserID {
+				if msg.Author.ID == botUserID || (includeBots && msg.Author.Bot) {
 					msgTime := time.Time(msg.Timestamp)
 					if msgTime.After(cutoff) {
 						toDelete = append(toDelete, msg.ID)
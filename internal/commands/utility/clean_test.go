@@ -0,0 +1,68 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utility_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/dankmemer/bot/internal/bot"
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+)
+
+// newHarness returns a harness with every utility command registered
+func newHarness(t *testing.T) *commandtest.Harness {
+	t.Helper()
+	h := commandtest.New()
+	if err := bot.RegisterCommands(&bot.Bot{Commands: h.Registry}); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestCleanBotsNeedsManageMessages(t *testing.T) {
+	h := newHarness(t)
+
+	resp, err := h.Exec("clean --bots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp == nil || !strings.Contains(resp.Content, "Manage Messages") {
+		t.Fatalf("got %+v, want the missing permission", resp)
+	}
+	for _, req := range h.Transport.Requests() {
+		if req.Method == http.MethodDelete || req.Method == http.MethodPost {
+			t.Fatalf("%s %s sent without permission", req.Method, req.Path)
+		}
+	}
+}
+
+func TestCleanBotsWithManageMessages(t *testing.T) {
+	h := newHarness(t)
+	h.SetPermissions(commandtest.AuthorID, discordgo.PermissionManageMessages)
+
+	resp, err := h.Exec("clean --bots")
+	if err == nil && resp != nil && strings.Contains(resp.Content, "Manage Messages") {
+		t.Fatalf("got %q, want the clean to go ahead", resp.Content)
+	}
+	if len(h.Transport.Requests()) == 0 {
+		t.Fatal("no messages fetched")
+	}
+}
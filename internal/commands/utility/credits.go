@@ -47,6 +47,7 @@ func init() {
 			Triggers:    []string{"credits", "helpers"},
 			Description: "Thanks to all of you!",
 			Category:    "Utility Commands",
+			DMAllowed:   true,
 			Permissions: []int64{discordgo.PermissionEmbedLinks},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
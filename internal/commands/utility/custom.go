@@ -0,0 +1,250 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utility
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dankmemer/bot/internal/bot"
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/database"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// maxCustomCommands is how many custom commands a server can have
+const maxCustomCommands = 50
+
+// customCommandsPerPage is how many custom commands are listed per page
+const customCommandsPerPage = 20
+
+// customNameRegex matches valid names of custom commands and aliases
+var customNameRegex = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+func init() {
+	bot.Register(&commands.Group{
+		Properties: commands.CommandProps{
+			Triggers:    []string{"customcommand", "customcommands", "cc"},
+			Description: "Make your own commands for this server",
+			Category:    "Utility",
+		},
+		Subcommands: []commands.Command{
+			customCreateCommand,
+			customEditCommand,
+			customDeleteCommand,
+			customListCommand,
+			customShowCommand,
+		},
+	})
+}
+
+// customResponseArgs are the arguments of create and edit. The response is
+// read with RawArgs to keep its quotes and newlines.
+var customResponseArgs = []commands.Arg{
+	{
+		Name:        "name",
+		Type:        commands.ArgWord,
+		Description: "Name of the command",
+		Missing:     "What should the command be called?\n\nExample: `{usage}`",
+	},
+	{
+		Name:        "response",
+		Type:        commands.ArgText,
+		Description: "What the command replies, see the help for placeholders",
+		Missing:     "What should the command reply? You can use `$author`, `$mention`, `$args`, `$1`, `{a|b|c}` and `{embed}`.\n\nExample: `{usage}`",
+	},
+}
+
+var customCreateCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:        []string{"create", "add"},
+		Description:     "Create a custom command",
+		Cooldown:        5000,
+		UserPermissions: discordgo.PermissionManageServer,
+		Args:            customResponseArgs,
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		name := strings.ToLower(ctx.ArgString("name"))
+		if !customNameRegex.MatchString(name) {
+			return &commands.CommandResponse{Content: "Command names can only have letters, numbers, `-` and `_`, and can't be over 32 characters long."}, nil
+		}
+		if ctx.Services.Registry.Find(name) != nil {
+			return &commands.CommandResponse{Content: fmt.Sprintf("`%s` is already one of my commands, pick another name.", name)}, nil
+		}
+
+		if target, ok := ctx.GuildConfig.Aliases[name]; ok {
+			return &commands.CommandResponse{Content: fmt.Sprintf("`%s` is already an alias for `%s`, pick another name.", name, target)}, nil
+		}
+
+		existing, err := ctx.Services.DB.GetCustomCommands(ctx.Context, ctx.Message.GuildID)
+		if err != nil {
+			return nil, err
+		}
+		for _, cmd := range existing {
+			if cmd.Name == name {
+				return &commands.CommandResponse{
+					Content: fmt.Sprintf("`%s` already exists. Use `%s cc edit %s` to change it.", name, ctx.GuildConfig.Prefix, name),
+				}, nil
+			}
+		}
+		if len(existing) >= maxCustomCommands {
+			return &commands.CommandResponse{Content: fmt.Sprintf("You can't have more than %d custom commands.", maxCustomCommands)}, nil
+		}
+
+		return saveCustomCommand(ctx, name, "Created")
+	},
+}
+
+var customEditCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:        []string{"edit"},
+		Description:     "Change the response of a custom command",
+		Cooldown:        5000,
+		UserPermissions: discordgo.PermissionManageServer,
+		Args:            customResponseArgs,
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		name := strings.ToLower(ctx.ArgString("name"))
+		existing, err := ctx.Services.DB.GetCustomCommand(ctx.Context, ctx.Message.GuildID, name)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return customNotFound(name), nil
+		}
+
+		return saveCustomCommand(ctx, name, "Updated")
+	},
+}
+
+var customDeleteCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:        []string{"delete", "remove"},
+		Description:     "Delete a custom command",
+		Cooldown:        5000,
+		UserPermissions: discordgo.PermissionManageServer,
+		Args: []commands.Arg{
+			{Name: "name", Type: commands.ArgWord, Description: "Name of the command"},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		name := strings.ToLower(ctx.ArgString("name"))
+		deleted, err := ctx.Services.DB.DeleteCustomCommand(ctx.Context, ctx.Message.GuildID, name)
+		if err != nil {
+			return nil, err
+		}
+		if !deleted {
+			return customNotFound(name), nil
+		}
+
+		// Don't leave a deleted command in the disabled list
+		if utils.Contains(ctx.GuildConfig.DisabledCommands, name) {
+			if err := ctx.Services.DB.EnableCommands(ctx.Context, ctx.Message.GuildID, []string{name}); err != nil {
+				return nil, err
+			}
+		}
+
+		return &commands.CommandResponse{Content: fmt.Sprintf("Deleted `%s`.", name)}, nil
+	},
+}
+
+var customListCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"list"},
+		Description: "List this server's custom commands",
+		Permissions: []int64{discordgo.PermissionEmbedLinks},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		cmds, err := ctx.Services.DB.GetCustomCommands(ctx.Context, ctx.Message.GuildID)
+		if err != nil {
+			return nil, err
+		}
+		if len(cmds) == 0 {
+			return &commands.CommandResponse{
+				Content: fmt.Sprintf("This server has no custom commands. Make one with `%s cc create <name> <response>`.", ctx.GuildConfig.Prefix),
+			}, nil
+		}
+
+		var pages []*discordgo.MessageEmbed
+		for start := 0; start < len(cmds); start += customCommandsPerPage {
+			names := make([]string, 0, customCommandsPerPage)
+			for _, cmd := range cmds[start:min(len(cmds), start+customCommandsPerPage)] {
+				names = append(names, "`"+cmd.Name+"`")
+			}
+			pages = append(pages, &discordgo.MessageEmbed{
+				Title:       fmt.Sprintf("Custom commands (%d/%d)", len(cmds), maxCustomCommands),
+				Description: strings.Join(names, ", "),
+				Color:       utils.RandomColor(),
+			})
+		}
+
+		paginator := &commands.Paginator{Pages: pages}
+		return paginator.Response(ctx)
+	},
+}
+
+var customShowCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"show", "raw"},
+		Description: "Show the response of a custom command",
+		Args: []commands.Arg{
+			{Name: "name", Type: commands.ArgWord, Description: "Name of the command"},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		name := strings.ToLower(ctx.ArgString("name"))
+		cmd, err := ctx.Services.DB.GetCustomCommand(ctx.Context, ctx.Message.GuildID, name)
+		if err != nil {
+			return nil, err
+		}
+		if cmd == nil {
+			return customNotFound(name), nil
+		}
+
+		return &commands.CommandResponse{
+			Content: fmt.Sprintf("`%s` replies with:\n```\n%s\n```", name, strings.ReplaceAll(cmd.Response, "```", "`\u200b``")),
+		}, nil
+	},
+}
+
+// saveCustomCommand validates and stores the response of a custom command
+func saveCustomCommand(ctx *commands.CommandContext, name, verb string) (*commands.CommandResponse, error) {
+	_, response := commands.CutWord(ctx.RawArgs())
+	if _, err := commands.ParseTemplate(response); err != nil {
+		return &commands.CommandResponse{Content: err.Error()}, nil
+	}
+
+	err := ctx.Services.DB.SetCustomCommand(ctx.Context, database.CustomCommand{
+		GuildID:  ctx.Message.GuildID,
+		Name:     name,
+		Response: response,
+		AuthorID: ctx.Message.Author.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &commands.CommandResponse{
+		Content: fmt.Sprintf("%s `%s`. Try it with `%s %s`!", verb, name, ctx.GuildConfig.Prefix, name),
+	}, nil
+}
+
+func customNotFound(name string) *commands.CommandResponse {
+	return &commands.CommandResponse{Content: fmt.Sprintf("There's no custom command called `%s`.", name)}
+}
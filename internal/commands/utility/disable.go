@@ -18,138 +18,157 @@ package utility
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/dankmemer/bot/internal/bot"
 	"github.com/dankmemer/bot/internal/commands"
-	"github.com/dankmemer/bot/internal/utils"
 )
 
 func init() {
-	bot.Register(&commands.BaseCommand{
-		Properties: commands.CommandProps{
-			Triggers:    []string{"disable"},
-			Description: "Use this command to disable commands you do not wish for your server to use",
-			Category:    "Utility Commands",
-		},
-		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			// Check permissions
-			botInterface, ok := ctx.Bot.(interface {
-				GetConfig() *utils.Config
-			})
-
-			hasPermission := false
-
-			// Check if user has manage guild permission
-			perms, err := ctx.Session.State.UserChannelPermissions(ctx.Message.Author.ID, ctx.Message.ChannelID)
-			if err == nil && perms&discordgo.PermissionManageServer != 0 {
-				hasPermission = true
-			}
+	bot.Register(disableCommand)
+}
 
-			// Check if user is a dev
-			if ok {
-				config := botInterface.GetConfig()
-				for _, dev := range config.Devs {
-					if dev == ctx.Message.Author.ID {
-						hasPermission = true
-						break
-					}
-				}
-			}
+var disableCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:        []string{"disable"},
+		Description:     "Use this command to disable commands or categories you do not wish for your server to use",
+		Category:        "Utility Commands",
+		UserPermissions: discordgo.PermissionManageServer,
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		if len(ctx.Args) == 0 {
+			return &commands.CommandResponse{
+				Content: fmt.Sprintf("Specify a command or category to disable, or multiple.\n\nExample: `%[1]s %[2]s meme trigger shitsound` or `%[1]s %[2]s voice`",
+					ctx.GuildConfig.Prefix, ctx.Command),
+				Reply: true,
+			}, nil
+		}
 
-			if !hasPermission {
-				return &commands.CommandResponse{
-					Content: "You are not authorized to use this command. You must have `Manage Server` to disable commands.",
-				}, nil
+		targets := parseDisableTargets(ctx, ctx.Args)
+		if targets.empty() {
+			return &commands.CommandResponse{
+				Content: "No valid commands or categories specified.",
+				Reply:   true,
+			}, nil
+		}
+
+		// Don't lock the server out of enable
+		for _, category := range targets.categories {
+			for _, cmd := range ctx.Services.Registry.GetByCategoryKey(category) {
+				if cmd == enableCommand {
+					return &commands.CommandResponse{
+						Content: fmt.Sprintf("You can't disable the `%s` category, you'd have no way to enable anything again.", category),
+						Reply:   true,
+					}, nil
+				}
 			}
+		}
 
-			if len(ctx.Args) == 0 {
-				return &commands.CommandResponse{
-					Content: fmt.Sprintf("Specify a command to disable, or multiple.\n\nExample: `%s disable meme trigger shitsound` or `%s disable meme`",
-						ctx.GuildConfig.Prefix, ctx.GuildConfig.Prefix),
-					Reply: true,
-				}, nil
+		// Check which are already disabled
+		var alreadyDisabled disableTargets
+		for _, cmdName := range targets.commands {
+			if slices.Contains(ctx.GuildConfig.DisabledCommands, cmdName) {
+				alreadyDisabled.commands = append(alreadyDisabled.commands, cmdName)
 			}
-
-			// Get the command registry
-			cmdBot, ok := ctx.Bot.(interface {
-				GetCommands() *commands.Registry
-			})
-			if !ok {
-				return &commands.CommandResponse{Content: "Error accessing commands"}, nil
+		}
+		for _, category := range targets.categories {
+			if slices.Contains(ctx.GuildConfig.DisabledCategories, category) {
+				alreadyDisabled.categories = append(alreadyDisabled.categories, category)
 			}
+		}
 
-			registry := cmdBot.GetCommands()
-
-			// Remove duplicates and normalize command names
-			seen := make(map[string]bool)
-			var normalizedArgs []string
+		if !alreadyDisabled.empty() {
+			return &commands.CommandResponse{
+				Content: fmt.Sprintf("These are already disabled:\n\n%s\n\nHow tf do you plan to disable already disabled commands??",
+					alreadyDisabled.format(ctx)),
+			}, nil
+		}
 
-			for _, arg := range ctx.Args {
-				arg = strings.ToLower(arg)
+		// Add to disabled commands and categories
+		if len(targets.commands) > 0 {
+			if err := ctx.Services.DB.DisableCommands(ctx.Context, ctx.Message.GuildID, targets.commands); err != nil {
+				return &commands.CommandResponse{Content: "Failed to disable commands"}, nil
+			}
+		}
+		if len(targets.categories) > 0 {
+			if err := ctx.Services.DB.DisableCategories(ctx.Context, ctx.Message.GuildID, targets.categories); err != nil {
+				return &commands.CommandResponse{Content: "Failed to disable categories"}, nil
+			}
+		}
 
-				// Find the canonical command name
-				cmd := registry.Find(arg)
-				var cmdName string
-				if cmd != nil {
-					cmdName = cmd.Props().Triggers[0]
-				} else if arg == "nsfw" {
-					cmdName = "nsfw" // Special case for NSFW category
-				} else {
-					continue // Invalid command
-				}
+		return &commands.CommandResponse{
+			Content: fmt.Sprintf("The following have been disabled successfully:\n\n%s", targets.format(ctx)),
+		}, nil
+	},
+}
 
-				if !seen[cmdName] {
-					seen[cmdName] = true
-					normalizedArgs = append(normalizedArgs, cmdName)
-				}
-			}
+// disableTargets are the commands and categories disable and enable act on
+type disableTargets struct {
+	commands   []string // Primary triggers and custom command names
+	categories []string // Normalized category names
+}
 
-			if len(normalizedArgs) == 0 {
-				return &commands.CommandResponse{
-					Content: "No valid commands specified.",
-					Reply:   true,
-				}, nil
+// parseDisableTargets resolves arguments to commands, then to categories.
+// Category names may span several arguments, e.g. "image manipulation".
+// Arguments that are neither are skipped.
+func parseDisableTargets(ctx *commands.CommandContext, args []string) disableTargets {
+	var targets disableTargets
+	for i := 0; i < len(args); i++ {
+		if cmdName := commandName(ctx, strings.ToLower(args[i])); cmdName != "" {
+			if !slices.Contains(targets.commands, cmdName) {
+				targets.commands = append(targets.commands, cmdName)
 			}
-
-			// Check which are already disabled
-			var alreadyDisabled []string
-			for _, cmdName := range normalizedArgs {
-				for _, disabled := range ctx.GuildConfig.DisabledCommands {
-					if disabled == cmdName {
-						alreadyDisabled = append(alreadyDisabled, cmdName)
-						break
-					}
-				}
+			continue
+		}
+
+		// Take the most words that name a category
+		for j := len(args); j > i; j-- {
+			category := ctx.Services.Registry.FindCategory(strings.Join(args[i:j], " "))
+			if category == "" {
+				continue
 			}
-
-			if len(alreadyDisabled) > 0 {
-				return &commands.CommandResponse{
-					Content: fmt.Sprintf("These commands are already disabled:\n\n%s\n\nHow tf do you plan to disable already disabled commands??",
-						formatCommandList(alreadyDisabled)),
-				}, nil
+			if !slices.Contains(targets.categories, category) {
+				targets.categories = append(targets.categories, category)
 			}
+			i = j - 1
+			break
+		}
+	}
+	return targets
+}
 
-			// Add to disabled commands
-			dbBot, ok := ctx.Bot.(interface {
-				GetDB() interface {
-					DisableCommands(guildID string, commands []string) error
-				}
-			})
+func (t disableTargets) empty() bool {
+	return len(t.commands) == 0 && len(t.categories) == 0
+}
 
-			if ok {
-				if err := dbBot.GetDB().DisableCommands(ctx.Message.GuildID, normalizedArgs); err != nil {
-					return &commands.CommandResponse{Content: "Failed to disable commands"}, nil
-				}
-			}
+// format lists the categories, with how many commands they have, then the
+// commands
+func (t disableTargets) format(ctx *commands.CommandContext) string {
+	var formatted []string
+	for _, category := range t.categories {
+		n := len(ctx.Services.Registry.GetByCategoryKey(category))
+		formatted = append(formatted, fmt.Sprintf("category `%s` (%d commands)", category, n))
+	}
+	if len(t.commands) > 0 {
+		formatted = append(formatted, formatCommandList(t.commands))
+	}
+	return strings.Join(formatted, ", ")
+}
 
-			return &commands.CommandResponse{
-				Content: fmt.Sprintf("The following commands have been disabled successfully:\n\n%s",
-					formatCommandList(normalizedArgs)),
-			}, nil
-		},
-	})
+// commandName returns the name disable and enable store for an argument: the
+// primary trigger of a command or the name of a custom command. Returns "" if
+// there is no such command.
+func commandName(ctx *commands.CommandContext, arg string) string {
+	if cmd := ctx.Services.Registry.Find(arg); cmd != nil {
+		return cmd.Props().Triggers[0]
+	}
+	custom, err := ctx.Services.DB.GetCustomCommand(ctx.Context, ctx.Message.GuildID, arg)
+	if err == nil && custom != nil {
+		return custom.Name
+	}
+	return ""
 }
 
 func formatCommandList(cmds []string) string {
@@ -0,0 +1,131 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utility_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+)
+
+// newCategoryHarness registers a "fun" category spread over two category
+// names, and lets the author manage the server
+func newCategoryHarness(t *testing.T) *commandtest.Harness {
+	t.Helper()
+	h := newHarness(t)
+	for _, props := range []commands.CommandProps{
+		{Triggers: []string{"joke"}, Description: "Tell a joke", Category: "Fun"},
+		{Triggers: []string{"roast"}, Description: "Roast someone", Category: "Fun Commands"},
+		{Triggers: []string{"deepfry"}, Description: "Deepfry an image", Category: "Image Manipulation"},
+	} {
+		if err := h.Registry.Register(&commands.BaseCommand{Properties: props}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h.SetPermissions(commandtest.AuthorID, discordgo.PermissionManageServer)
+	return h
+}
+
+func mustExec(t *testing.T, h *commandtest.Harness, content string) *commands.CommandResponse {
+	t.Helper()
+	resp, err := h.Exec(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestDisableCategory(t *testing.T) {
+	h := newCategoryHarness(t)
+
+	resp := mustExec(t, h, "disable fun")
+	if !strings.Contains(resp.Content, "category `fun` (2 commands)") {
+		t.Errorf("got %q, want both fun commands disabled", resp.Content)
+	}
+	if got := h.Store.Guild(commandtest.GuildID).DisabledCategories; !reflect.DeepEqual(got, []string{"fun"}) {
+		t.Errorf("disabled categories = %v, want [fun]", got)
+	}
+
+	resp = mustExec(t, h, "disable Fun Commands")
+	if !strings.Contains(resp.Content, "already disabled") {
+		t.Errorf("got %q, want the category reported as already disabled", resp.Content)
+	}
+
+	// Commands in the category point to it in help, and can't be enabled alone
+	resp = mustExec(t, h, "help roast")
+	if !strings.Contains(fieldValues(resp.Embed), "pls enable fun") {
+		t.Errorf("help fields %q, want a hint to enable the category", fieldValues(resp.Embed))
+	}
+	resp = mustExec(t, h, "enable roast")
+	if !strings.Contains(resp.Content, "through their category") {
+		t.Errorf("got %q, want the category named", resp.Content)
+	}
+
+	resp = mustExec(t, h, "enable fun")
+	if !strings.Contains(resp.Content, "enabled successfully") {
+		t.Errorf("got %q, want the category enabled", resp.Content)
+	}
+	if got := h.Store.Guild(commandtest.GuildID).DisabledCategories; len(got) != 0 {
+		t.Errorf("disabled categories = %v, want none", got)
+	}
+}
+
+func TestDisableTargets(t *testing.T) {
+	h := newCategoryHarness(t)
+
+	mustExec(t, h, "disable image manipulation joke nope")
+	cfg := h.Store.Guild(commandtest.GuildID)
+	if !reflect.DeepEqual(cfg.DisabledCategories, []string{"image manipulation"}) {
+		t.Errorf("disabled categories = %v, want [image manipulation]", cfg.DisabledCategories)
+	}
+	if !reflect.DeepEqual(cfg.DisabledCommands, []string{"joke"}) {
+		t.Errorf("disabled commands = %v, want [joke]", cfg.DisabledCommands)
+	}
+
+	// The help overview lists what's disabled
+	resp := mustExec(t, h, "help")
+	overview := fieldValues(resp.Embed)
+	if !strings.Contains(overview, "**image manipulation**") || !strings.Contains(overview, "joke") {
+		t.Errorf("help overview %q, want the disabled category and command", overview)
+	}
+}
+
+func TestDisableKeepsEnable(t *testing.T) {
+	h := newCategoryHarness(t)
+
+	resp := mustExec(t, h, "disable utility")
+	if !strings.Contains(resp.Content, "no way to enable") {
+		t.Errorf("got %q, want the utility category refused", resp.Content)
+	}
+	if got := h.Store.Guild(commandtest.GuildID).DisabledCategories; len(got) != 0 {
+		t.Errorf("disabled categories = %v, want none", got)
+	}
+}
+
+// fieldValues joins the names and values of an embed's fields
+func fieldValues(embed *discordgo.MessageEmbed) string {
+	var b strings.Builder
+	for _, field := range embed.Fields {
+		b.WriteString(field.Name + "\n" + field.Value + "\n")
+	}
+	return b.String()
+}
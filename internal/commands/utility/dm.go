@@ -17,8 +17,6 @@
 package utility
 
 import (
-	"strings"
-
 	"github.com/bwmarrin/discordgo"
 	"github.com/dankmemer/bot/internal/bot"
 	"github.com/dankmemer/bot/internal/commands"
@@ -30,17 +28,16 @@ func init() {
 		Properties: commands.CommandProps{
 			Triggers:    []string{"dm", "slideintothedms"},
 			Description: "melmsie stinks",
-			Usage:       "{command} <id> <shit>",
 			OwnerOnly:   true,
 			Category:    "Utility Commands",
+			Args: []commands.Arg{
+				{Name: "id", Type: commands.ArgUser},
+				{Name: "shit", Type: commands.ArgText},
+			},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			if len(ctx.Args) < 2 {
-				return &commands.CommandResponse{Content: "Usage: dm <user_id> <message>"}, nil
-			}
-
-			userID := ctx.Args[0]
-			message := strings.Join(ctx.Args[1:], " ")
+			userID := ctx.ArgUser("id").ID
+			message := ctx.ArgString("shit")
 
 			// Create DM channel
 			channel, err := ctx.Session.UserChannelCreate(userID)
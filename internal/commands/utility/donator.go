@@ -0,0 +1,188 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utility
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dankmemer/bot/internal/bot"
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/database"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+func init() {
+	bot.Register(&commands.Group{
+		Properties: commands.CommandProps{
+			Triggers:    []string{"donator", "donators", "donor"},
+			Description: "Manage donator tiers",
+			OwnerOnly:   true,
+			Category:    "Utility Commands",
+		},
+		Subcommands: []commands.Command{donatorSetCommand, donatorRemoveCommand, donatorShowCommand, donatorListCommand},
+	})
+
+	bot.Register(&commands.BaseCommand{
+		Properties: commands.CommandProps{
+			Triggers:    []string{"premium"},
+			Description: "Turn premium on or off for a server",
+			OwnerOnly:   true,
+			Category:    "Utility Commands",
+			Args: []commands.Arg{
+				{Name: "guild", Type: commands.ArgWord, Description: "The server's ID"},
+				{Name: "state", Type: commands.ArgChoice, Description: "Whether the server is premium", Choices: []string{"on", "off"}},
+			},
+		},
+		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+			guildID := ctx.ArgString("guild")
+			premium := ctx.ArgString("state") == "on"
+			if err := ctx.Services.DB.UpdateGuildPremium(ctx.Context, guildID, premium); err != nil {
+				return nil, err
+			}
+			if premium {
+				return &commands.CommandResponse{Content: ctx.T("donator.premium.on", guildID)}, nil
+			}
+			return &commands.CommandResponse{Content: ctx.T("donator.premium.off", guildID)}, nil
+		},
+	})
+}
+
+var donatorSetCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"set", "give"},
+		Description: "Give a user a donator tier, optionally for a number of days",
+		Args: []commands.Arg{
+			{Name: "user", Type: commands.ArgUser, Description: "The donator"},
+			{Name: "tier", Type: commands.ArgInteger, Description: "The tier's level", Min: 1, Max: 100},
+			{Name: "days", Type: commands.ArgInteger, Description: "How long the tier lasts, forever by default", Optional: true, Min: 1, Max: 3650},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		user := ctx.ArgUser("user")
+		level := int(ctx.ArgInt("tier", 1))
+		if level > len(ctx.Services.Config.DonatorTiers) {
+			return &commands.CommandResponse{Content: ctx.Plural("donator.tiers", int64(len(ctx.Services.Config.DonatorTiers)))}, nil
+		}
+
+		var expiresAt time.Time
+		if days := ctx.ArgInt("days", 0); days > 0 {
+			expiresAt = time.Now().AddDate(0, 0, int(days))
+		}
+		if err := ctx.Services.DB.SetDonator(ctx.Context, user.ID, level, expiresAt); err != nil {
+			return nil, err
+		}
+
+		d := database.Donator{ID: user.ID, Level: level, ExpiresAt: expiresAt}
+		return &commands.CommandResponse{
+			Content: ctx.T("donator.set", user.Username, tierName(ctx, level), formatExpiry(ctx, d)),
+		}, nil
+	},
+}
+
+var donatorRemoveCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"remove", "delete"},
+		Description: "Take a user's donator tier away",
+		Args: []commands.Arg{
+			{Name: "user", Type: commands.ArgUser, Description: "The donator"},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		user := ctx.ArgUser("user")
+		if err := ctx.Services.DB.RemoveDonator(ctx.Context, user.ID); err != nil {
+			return nil, err
+		}
+		return &commands.CommandResponse{Content: ctx.T("donator.removed", user.Username)}, nil
+	},
+}
+
+var donatorShowCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"show", "info"},
+		Description: "Show a user's donator tier",
+		Args: []commands.Arg{
+			{Name: "user", Type: commands.ArgUser, Description: "The user"},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		user := ctx.ArgUser("user")
+		d, err := ctx.Services.DB.GetDonator(ctx.Context, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil {
+			return &commands.CommandResponse{Content: ctx.T("donator.never", user.Username)}, nil
+		}
+		return &commands.CommandResponse{Content: fmt.Sprintf("%s: %s", user.Username, formatDonator(ctx, *d))}, nil
+	},
+}
+
+var donatorListCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"list"},
+		Description: "List every donator",
+		Permissions: []int64{discordgo.PermissionEmbedLinks},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		donators, err := ctx.Services.DB.GetDonators(ctx.Context)
+		if err != nil {
+			return nil, err
+		}
+		if len(donators) == 0 {
+			return &commands.CommandResponse{Content: ctx.T("donator.none")}, nil
+		}
+
+		lines := make([]string, len(donators))
+		for i, d := range donators {
+			lines[i] = fmt.Sprintf("<@%s> %s", d.ID, formatDonator(ctx, d))
+		}
+		return &commands.CommandResponse{Embed: &discordgo.MessageEmbed{
+			Title:       ctx.T("donator.list.title", len(donators)),
+			Description: utils.TruncateString(strings.Join(lines, "\n"), 4096),
+			Color:       utils.RandomColor(),
+		}}, nil
+	},
+}
+
+// tierName returns the name of a donator level, or its number if it has none
+func tierName(ctx *commands.CommandContext, level int) string {
+	if tier := ctx.Services.Config.DonatorTier(level); tier != nil && tier.Name != "" {
+		return tier.Name
+	}
+	return ctx.T("donator.tier", level)
+}
+
+func formatDonator(ctx *commands.CommandContext, d database.Donator) string {
+	status := fmt.Sprintf("**%s** (%d) %s", tierName(ctx, d.Level), d.Level, formatExpiry(ctx, d))
+	if !d.Active() {
+		status = "~~" + status + "~~"
+	}
+	return status
+}
+
+func formatExpiry(ctx *commands.CommandContext, d database.Donator) string {
+	if d.ExpiresAt.IsZero() {
+		return ctx.T("donator.forever")
+	}
+	if !d.Active() {
+		return ctx.T("donator.expired", d.ExpiresAt.Unix())
+	}
+	return ctx.T("donator.until", d.ExpiresAt.Unix())
+}
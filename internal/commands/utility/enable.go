@@ -18,138 +18,88 @@ package utility
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/dankmemer/bot/internal/bot"
 	"github.com/dankmemer/bot/internal/commands"
-	"github.com/dankmemer/bot/internal/utils"
 )
 
 func init() {
-	bot.Register(&commands.BaseCommand{
-		Properties: commands.CommandProps{
-			Triggers:    []string{"enable"},
-			Description: "Use this command to enable disabled commands.",
-			Category:    "Utility Commands",
-		},
-		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			// Check permissions
-			botInterface, ok := ctx.Bot.(interface {
-				GetConfig() *utils.Config
-			})
-
-			hasPermission := false
-
-			// Check if user has manage guild permission
-			perms, err := ctx.Session.State.UserChannelPermissions(ctx.Message.Author.ID, ctx.Message.ChannelID)
-			if err == nil && perms&discordgo.PermissionManageServer != 0 {
-				hasPermission = true
-			}
-
-			// Check if user is a dev
-			if ok {
-				config := botInterface.GetConfig()
-				for _, dev := range config.Devs {
-					if dev == ctx.Message.Author.ID {
-						hasPermission = true
-						break
-					}
-				}
-			}
-
-			if !hasPermission {
-				return &commands.CommandResponse{
-					Content: "You are not authorized to use this command. You must have `Manage Server` to enable commands.",
-				}, nil
-			}
+	bot.Register(enableCommand)
+}
 
-			if len(ctx.Args) == 0 {
-				return &commands.CommandResponse{
-					Content: fmt.Sprintf("Specify a command to enable, or multiple.\n\nExample: `%s enable meme trigger shitsound` or `%s enable meme`",
-						ctx.GuildConfig.Prefix, ctx.GuildConfig.Prefix),
-				}, nil
-			}
+var enableCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:        []string{"enable"},
+		Description:     "Use this command to enable disabled commands or categories.",
+		Category:        "Utility Commands",
+		UserPermissions: discordgo.PermissionManageServer,
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		if len(ctx.Args) == 0 {
+			return &commands.CommandResponse{
+				Content: fmt.Sprintf("Specify a command or category to enable, or multiple.\n\nExample: `%[1]s %[2]s meme trigger shitsound` or `%[1]s %[2]s voice`",
+					ctx.GuildConfig.Prefix, ctx.Command),
+			}, nil
+		}
 
-			// Get the command registry
-			cmdBot, ok := ctx.Bot.(interface {
-				GetCommands() *commands.Registry
-			})
-			if !ok {
-				return &commands.CommandResponse{Content: "Error accessing commands"}, nil
+		targets := parseDisableTargets(ctx, ctx.Args)
+		if targets.empty() {
+			return &commands.CommandResponse{
+				Content: "No valid commands or categories specified.",
+			}, nil
+		}
+
+		// Check which are not disabled
+		var notDisabled disableTargets
+		var viaCategory []string
+		for _, cmdName := range targets.commands {
+			if slices.Contains(ctx.GuildConfig.DisabledCommands, cmdName) {
+				continue
 			}
-
-			registry := cmdBot.GetCommands()
-
-			// Remove duplicates and normalize command names
-			seen := make(map[string]bool)
-			var normalizedArgs []string
-
-			for _, arg := range ctx.Args {
-				arg = strings.ToLower(arg)
-
-				// Find the canonical command name
-				cmd := registry.Find(arg)
-				var cmdName string
-				if cmd != nil {
-					cmdName = cmd.Props().Triggers[0]
-				} else if arg == "nsfw" {
-					cmdName = "nsfw"
-				} else {
-					continue
-				}
-
-				if !seen[cmdName] {
-					seen[cmdName] = true
-					normalizedArgs = append(normalizedArgs, cmdName)
+			notDisabled.commands = append(notDisabled.commands, cmdName)
+			if cmd := ctx.Services.Registry.Find(cmdName); cmd != nil {
+				category := commands.CategoryKey(cmd.Props().Category)
+				if slices.Contains(ctx.GuildConfig.DisabledCategories, category) && !slices.Contains(viaCategory, category) {
+					viaCategory = append(viaCategory, category)
 				}
 			}
-
-			if len(normalizedArgs) == 0 {
-				return &commands.CommandResponse{
-					Content: "No valid commands specified.",
-				}, nil
+		}
+		for _, category := range targets.categories {
+			if !slices.Contains(ctx.GuildConfig.DisabledCategories, category) {
+				notDisabled.categories = append(notDisabled.categories, category)
 			}
+		}
 
-			// Check which are not disabled
-			var notDisabled []string
-			for _, cmdName := range normalizedArgs {
-				isDisabled := false
-				for _, disabled := range ctx.GuildConfig.DisabledCommands {
-					if disabled == cmdName {
-						isDisabled = true
-						break
-					}
-				}
-				if !isDisabled {
-					notDisabled = append(notDisabled, cmdName)
-				}
-			}
+		if len(viaCategory) > 0 {
+			return &commands.CommandResponse{
+				Content: fmt.Sprintf("Those commands are disabled through their category. Enable it with `%s %s %s`.",
+					ctx.GuildConfig.Prefix, ctx.Command, strings.Join(viaCategory, " ")),
+			}, nil
+		}
+		if !notDisabled.empty() {
+			return &commands.CommandResponse{
+				Content: fmt.Sprintf("These aren't disabled:\n\n%s\n\nHow tf do you plan to enable already enabled commands??",
+					notDisabled.format(ctx)),
+			}, nil
+		}
 
-			if len(notDisabled) > 0 {
-				return &commands.CommandResponse{
-					Content: fmt.Sprintf("These commands aren't disabled:\n\n%s\n\nHow tf do you plan to enable already enabled commands??",
-						formatCommandList(notDisabled)),
-				}, nil
+		// Enable commands and categories
+		if len(targets.commands) > 0 {
+			if err := ctx.Services.DB.EnableCommands(ctx.Context, ctx.Message.GuildID, targets.commands); err != nil {
+				return &commands.CommandResponse{Content: "Failed to enable commands"}, nil
 			}
-
-			// Enable commands
-			dbBot, ok := ctx.Bot.(interface {
-				GetDB() interface {
-					EnableCommands(guildID string, commands []string) error
-				}
-			})
-
-			if ok {
-				if err := dbBot.GetDB().EnableCommands(ctx.Message.GuildID, normalizedArgs); err != nil {
-					return &commands.CommandResponse{Content: "Failed to enable commands"}, nil
-				}
+		}
+		if len(targets.categories) > 0 {
+			if err := ctx.Services.DB.EnableCategories(ctx.Context, ctx.Message.GuildID, targets.categories); err != nil {
+				return &commands.CommandResponse{Content: "Failed to enable categories"}, nil
 			}
+		}
 
-			return &commands.CommandResponse{
-				Content: fmt.Sprintf("The following commands have been enabled successfully:\n\n%s",
-					formatCommandList(normalizedArgs)),
-			}, nil
-		},
-	})
+		return &commands.CommandResponse{
+			Content: fmt.Sprintf("The following have been enabled successfully:\n\n%s", targets.format(ctx)),
+		}, nil
+	},
 }
@@ -18,12 +18,14 @@ package utility
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/dankmemer/bot/internal/bot"
 	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/database"
 	"github.com/dankmemer/bot/internal/utils"
 )
 
@@ -33,95 +35,187 @@ func init() {
 			Triggers:    []string{"help", "cmds", "commands"},
 			Description: "See a list of commands available.",
 			Category:    "Utility",
+			DMAllowed:   true,
 			Permissions: []int64{discordgo.PermissionEmbedLinks},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			b, ok := ctx.Bot.(helpBot)
-			if !ok {
-				return nil, fmt.Errorf("cannot access command registry")
-			}
-
-			// If no args, show all commands by category
+			// If no args, page through all commands by category
 			if len(ctx.Args) == 0 {
-				allCmds := b.GetCommands().GetAll()
-
-				// Group by category
-				categories := make(map[string][]string)
-				for _, cmd := range allCmds {
-					props := cmd.Props()
-					if props.OwnerOnly {
-						continue
-					}
-
-					category := props.Category
-					if category == "" {
-						category = "Other"
-					}
-
-					categories[category] = append(categories[category], props.Triggers[0])
-				}
-
-				// Sort categories
-				catNames := make([]string, 0, len(categories))
-				for name := range categories {
-					catNames = append(catNames, name)
-				}
-				sort.Strings(catNames)
-
-				// Build fields
-				fields := make([]*discordgo.MessageEmbedField, 0, len(catNames))
-				for _, catName := range catNames {
-					cmds := categories[catName]
-					sort.Strings(cmds)
-					fields = append(fields, &discordgo.MessageEmbedField{
-						Name:  catName,
-						Value: strings.Join(cmds, ", "),
-					})
-				}
-
-				embed := &discordgo.MessageEmbed{
-					Title:       "Available Commands",
-					Description: "Auto posting memes, shorter cooldowns, custom commands and more coming on the premium bot later this week. Use pls patreon to see how to get it!",
-					Fields:      fields,
-					Footer: &discordgo.MessageEmbedFooter{
-						Text: "Hello darkness my old friend...",
-					},
-					Color: utils.RandomColor(),
-				}
+				pages, labels := helpPages(ctx)
+				paginator := &commands.Paginator{Pages: pages, Labels: labels}
+				return paginator.Response(ctx)
+			}
 
-				return &commands.CommandResponse{Embed: embed}, nil
+			// Show specific command info, walking into subcommands
+			args := ctx.Args
+			if target, ok := ctx.GuildConfig.Aliases[strings.ToLower(args[0])]; ok {
+				words, _ := commands.Tokenize(target)
+				args = append(words, args[1:]...)
 			}
 
-			// Show specific command info
-			cmdName := strings.ToLower(ctx.Args[0])
-			cmd := b.GetCommands().Find(cmdName)
+			cmd := ctx.Services.Registry.Find(strings.ToLower(args[0]))
 			if cmd == nil {
-				return &commands.CommandResponse{Content: "Command not found."}, nil
+				return &commands.CommandResponse{Content: ctx.T("help.not_found")}, nil
+			}
+			top := cmd
+
+			path := cmd.Props().Triggers[0]
+			for _, arg := range args[1:] {
+				group, ok := cmd.(*commands.Group)
+				if !ok {
+					break
+				}
+				sub := group.Find(arg)
+				if sub == nil || sub.Props().OwnerOnly {
+					return &commands.CommandResponse{Content: ctx.T("help.not_found")}, nil
+				}
+				cmd = sub
+				path += " " + sub.Props().Triggers[0]
 			}
 
 			props := cmd.Props()
 			prefix := ctx.GuildConfig.Prefix
 
-			usage := props.Usage
-			if usage == "" {
-				usage = "{command}"
-			}
-			usage = strings.ReplaceAll(usage, "{command}", prefix+" "+props.Triggers[0])
+			locale := ctx.Locale()
+			usage := strings.ReplaceAll(commands.LocalizedUsage(props, path, locale), "{command}", prefix+" "+path)
 
 			embed := &discordgo.MessageEmbed{
 				Fields: []*discordgo.MessageEmbedField{
-					{Name: "Description:", Value: props.Description},
-					{Name: "Usage:", Value: "```" + usage + "```"},
-					{Name: "Triggers:", Value: strings.Join(props.Triggers, ", ")},
+					{Name: ctx.T("help.description"), Value: commands.LocalizedDescription(props, path, locale)},
+					{Name: ctx.T("help.usage"), Value: "```" + usage + "```"},
+					{Name: ctx.T("help.triggers"), Value: strings.Join(props.Triggers, ", ")},
 				},
 				Color: utils.RandomColor(),
 			}
 
+			if aliases := commands.AliasesOf(ctx.Services.Registry, ctx.GuildConfig.Aliases, path); len(aliases) > 0 {
+				embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+					Name:  ctx.T("help.aliases"),
+					Value: strings.Join(aliases, ", "),
+				})
+			}
+
+			// Subcommands can't be disabled on their own, only their command
+			if commands.IsDisabled(ctx.GuildConfig, top.Props()) {
+				target := top.Props().Triggers[0]
+				if !slices.Contains(ctx.GuildConfig.DisabledCommands, target) {
+					target = commands.CategoryKey(top.Props().Category)
+				}
+				embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+					Name:  ctx.T("help.disabled"),
+					Value: ctx.T("help.enable_hint", prefix, target),
+				})
+			}
+
+			if group, ok := cmd.(*commands.Group); ok {
+				embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+					Name:  ctx.T("help.subcommands"),
+					Value: "```" + commands.SubcommandTree(group, prefix, path, locale) + "```",
+				})
+			}
+
 			return &commands.CommandResponse{Embed: embed}, nil
 		},
 	})
 }
 
-type helpBot interface {
-	GetCommands() *commands.Registry
+// helpCommandsPerPage is the most commands listed on one page of help
+const helpCommandsPerPage = 15
+
+// helpPages renders an overview page followed by the commands of each
+// category, split over several pages when a category is large. DMs only list
+// the commands usable there, and guilds don't list the commands they disabled.
+func helpPages(ctx *commands.CommandContext) ([]*discordgo.MessageEmbed, []string) {
+	prefix := ctx.GuildConfig.Prefix
+	inDM := ctx.Message.GuildID == ""
+
+	// Group by category
+	categories := make(map[string][]commands.Command)
+	for _, cmd := range ctx.Services.Registry.GetAll() {
+		props := cmd.Props()
+		if props.OwnerOnly || (inDM && !props.DMAllowed) || commands.IsDisabled(ctx.GuildConfig, props) {
+			continue
+		}
+
+		category := props.Category
+		if category == "" {
+			category = "Other"
+		}
+
+		categories[category] = append(categories[category], cmd)
+	}
+
+	// Sort categories
+	catNames := make([]string, 0, len(categories))
+	for name := range categories {
+		catNames = append(catNames, name)
+	}
+	sort.Strings(catNames)
+
+	overview := &discordgo.MessageEmbed{
+		Title:       ctx.T("help.title"),
+		Description: ctx.T("help.intro", prefix),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: ctx.T("help.footer"),
+		},
+		Color: utils.RandomColor(),
+	}
+	pages := []*discordgo.MessageEmbed{overview}
+	labels := []string{ctx.T("help.overview")}
+
+	for _, catName := range catNames {
+		cmds := categories[catName]
+		sort.Slice(cmds, func(i, j int) bool {
+			return cmds[i].Props().Triggers[0] < cmds[j].Props().Triggers[0]
+		})
+
+		overview.Fields = append(overview.Fields, &discordgo.MessageEmbedField{
+			Name:   catName,
+			Value:  ctx.Plural("help.count", int64(len(cmds))),
+			Inline: true,
+		})
+
+		chunks := (len(cmds) + helpCommandsPerPage - 1) / helpCommandsPerPage
+		for chunk := 0; chunk < chunks; chunk++ {
+			end := min(len(cmds), (chunk+1)*helpCommandsPerPage)
+
+			lines := make([]string, 0, helpCommandsPerPage)
+			for _, cmd := range cmds[chunk*helpCommandsPerPage : end] {
+				props := cmd.Props()
+				lines = append(lines, fmt.Sprintf("`%s` - %s", props.Triggers[0], commands.LocalizedDescription(props, props.Triggers[0], ctx.Locale())))
+			}
+
+			title := catName
+			if chunks > 1 {
+				title = fmt.Sprintf("%s (%d/%d)", catName, chunk+1, chunks)
+			}
+			pages = append(pages, &discordgo.MessageEmbed{
+				Title:       title,
+				Description: strings.Join(lines, "\n"),
+				Color:       utils.RandomColor(),
+			})
+			labels = append(labels, title)
+		}
+	}
+
+	if disabled := formatDisabled(ctx.GuildConfig); disabled != "" {
+		overview.Fields = append(overview.Fields, &discordgo.MessageEmbedField{
+			Name:  ctx.T("help.disabled"),
+			Value: disabled,
+		})
+	}
+
+	return pages, labels
+}
+
+// formatDisabled lists a guild's disabled categories and commands
+func formatDisabled(cfg *database.GuildConfig) string {
+	var parts []string
+	for _, category := range cfg.DisabledCategories {
+		parts = append(parts, fmt.Sprintf("**%s**", category))
+	}
+	if len(cfg.DisabledCommands) > 0 {
+		parts = append(parts, formatCommandList(cfg.DisabledCommands))
+	}
+	return utils.TruncateString(strings.Join(parts, ", "), 1024)
 }
@@ -0,0 +1,75 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utility_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+)
+
+func TestHelpCommand(t *testing.T) {
+	h := newHarness(t)
+
+	resp, err := h.Exec("help prefix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commandtest.AssertGolden(t, "help_prefix", commandtest.Snapshot(resp))
+}
+
+func TestHelpUnknownCommand(t *testing.T) {
+	h := newHarness(t)
+
+	resp, err := h.Exec("help nope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Content, "not found") {
+		t.Fatalf("got %q, want a not found reply", resp.Content)
+	}
+}
+
+func TestHelpInDMs(t *testing.T) {
+	h := newHarness(t)
+	h.DM = true
+
+	resp, err := h.Exec("help")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The overview is followed by a page per category
+	var pages strings.Builder
+	for range resp.Embed.Fields {
+		if resp, err = h.Click(resp, "next"); err != nil {
+			t.Fatal(err)
+		}
+		pages.WriteString(resp.Embed.Description)
+		for _, field := range resp.Embed.Fields {
+			pages.WriteString(field.Name + field.Value)
+		}
+	}
+
+	if !strings.Contains(pages.String(), "ping") {
+		t.Errorf("got %q, want ping listed", pages.String())
+	}
+	if strings.Contains(pages.String(), "prefix") {
+		t.Errorf("got %q, want prefix left out in DMs", pages.String())
+	}
+}
@@ -29,6 +29,7 @@ func init() {
 			Triggers:    []string{"invite", "support", "server"},
 			Description: "Get an invite for the bot or to the support server.",
 			Category:    "Utility Commands",
+			DMAllowed:   true,
 			Permissions: []int64{discordgo.PermissionEmbedLinks},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
@@ -36,17 +37,12 @@ func init() {
 			inviteURL := "https://goo.gl/BPWvB9"
 			supportURL := "https://discord.gg/ebUqc7F"
 
-			botInterface, ok := ctx.Bot.(interface {
-				GetConfig() *utils.Config
-			})
-			if ok {
-				config := botInterface.GetConfig()
-				if config.URLs.Invite != "" {
-					inviteURL = config.URLs.Invite
-				}
-				if config.URLs.Support != "" {
-					supportURL = config.URLs.Support
-				}
+			config := ctx.Services.Config
+			if config.URLs.Invite != "" {
+				inviteURL = config.URLs.Invite
+			}
+			if config.URLs.Support != "" {
+				supportURL = config.URLs.Support
 			}
 
 			embed := &discordgo.MessageEmbed{
@@ -0,0 +1,83 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utility
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/i18n"
+)
+
+// languageCommand shows or changes the language the bot replies in
+var languageCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:        []string{"language", "lang", "locale"},
+		Description:     "Change the language Dank Memer speaks in this server",
+		Cooldown:        5000,
+		UserPermissions: discordgo.PermissionManageServer,
+		Args: []commands.Arg{
+			{
+				Name:        "language",
+				Type:        commands.ArgWord,
+				Description: "Language code, e.g. en or es",
+				Optional:    true,
+			},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		current := ctx.Locale()
+		if !ctx.HasArg("language") {
+			return &commands.CommandResponse{
+				Content: ctx.T("language.current", i18n.T(current, "language.name"), current, languageList()),
+			}, nil
+		}
+
+		locale := i18n.Normalize(ctx.ArgString("language"))
+		if !i18n.Supported(locale) {
+			return &commands.CommandResponse{
+				Content: ctx.T("language.unknown", ctx.ArgString("language"), languageList()),
+			}, nil
+		}
+		if locale == current {
+			return &commands.CommandResponse{
+				Content: ctx.T("language.same", i18n.T(locale, "language.name")),
+			}, nil
+		}
+
+		if err := ctx.Services.DB.UpdateGuildLocale(ctx.Context, ctx.Message.GuildID, locale); err != nil {
+			return nil, err
+		}
+
+		// Confirm in the new language
+		return &commands.CommandResponse{
+			Content: i18n.T(locale, "language.changed", i18n.T(locale, "language.name")),
+		}, nil
+	},
+}
+
+// languageList lists the available locales with their names
+func languageList() string {
+	locales := i18n.Locales()
+	names := make([]string, 0, len(locales))
+	for _, locale := range locales {
+		names = append(names, fmt.Sprintf("`%s` (%s)", locale, i18n.T(locale, "language.name")))
+	}
+	return strings.Join(names, ", ")
+}
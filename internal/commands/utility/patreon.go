@@ -29,19 +29,14 @@ func init() {
 			Triggers:    []string{"patreon", "donate", "gibmonies", "pay", "donut", "plsdonut"},
 			Description: "See how you can donate to the bot and gain access to donor features!",
 			Category:    "Utility Commands",
+			DMAllowed:   true,
 			Permissions: []int64{discordgo.PermissionEmbedLinks},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
 			patreonURL := "https://www.patreon.com/dankmemerbot"
 
-			botInterface, ok := ctx.Bot.(interface {
-				GetConfig() *utils.Config
-			})
-			if ok {
-				config := botInterface.GetConfig()
-				if config.URLs.Patreon != "" {
-					patreonURL = config.URLs.Patreon
-				}
+			if url := ctx.Services.Config.URLs.Patreon; url != "" {
+				patreonURL = url
 			}
 
 			embed := &discordgo.MessageEmbed{
@@ -31,6 +31,7 @@ func init() {
 			Triggers:    []string{"ping"},
 			Description: "test cmd plz ignore",
 			Category:    "Utility",
+			DMAllowed:   true,
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
 			embed := &discordgo.MessageEmbed{
@@ -17,8 +17,9 @@
 package utility
 
 import (
-	"fmt"
+	"slices"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/dankmemer/bot/internal/bot"
@@ -26,81 +27,271 @@ import (
 	"github.com/dankmemer/bot/internal/utils"
 )
 
+const (
+	maxPrefixLength         = 32 // Longest prefix, in bytes
+	maxPrefixes             = 10 // Most prefixes a guild can have
+	minPersonalPrefixLength = 2  // Shortest personal prefix, in characters
+)
+
 func init() {
-	bot.Register(&commands.BaseCommand{
-		Properties: commands.CommandProps{
-			Triggers:    []string{"prefix"},
-			Description: "Change Dank Memer's prefix!",
-			Usage:       "{command} <prefix of your choice>",
-			Category:    "Utility",
-			Cooldown:    5000,
-			Permissions: []int64{discordgo.PermissionEmbedLinks},
-		},
-		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
-			b, ok := ctx.Bot.(prefixBot)
-			if !ok {
-				return nil, fmt.Errorf("cannot access bot")
-			}
+	bot.Register(prefixCommand)
+}
 
-			// Check permissions
-			perms, err := ctx.Session.State.UserChannelPermissions(ctx.Message.Author.ID, ctx.Message.ChannelID)
-			if err == nil {
-				hasManageGuild := perms&discordgo.PermissionManageServer != 0
-				isDev := utils.Contains(b.GetConfig().Devs, ctx.Message.Author.ID)
-
-				if !hasManageGuild && !isDev {
-					return &commands.CommandResponse{
-						Content: "You are not authorized to use this command. You must have `Manage Server` to change the prefix.",
-					}, nil
-				}
-			}
+// prefixArg is the prefix argument of the prefix subcommands. Its missing
+// message is "commands.prefix.<subcommand>.args.prefix.missing".
+var prefixArg = []commands.Arg{
+	{
+		Name:        "prefix",
+		Type:        commands.ArgText,
+		Description: "Prefix of your choice",
+	},
+}
 
-			currentPrefix := ctx.GuildConfig.Prefix
+var prefixSetCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:        []string{"set"},
+		Description:     "Change Dank Memer's main prefix!",
+		Cooldown:        5000,
+		UserPermissions: discordgo.PermissionManageServer,
+		Args:            prefixArg,
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		newPrefix, problem := checkPrefix(ctx, ctx.ArgString("prefix"))
+		if problem != "" {
+			return &commands.CommandResponse{Content: problem}, nil
+		}
 
-			// No args - show current prefix
-			if len(ctx.Args) == 0 {
-				return &commands.CommandResponse{
-					Content: fmt.Sprintf("What do you want your new prefix to be?\n\nExample: `%s prefix pepe`", currentPrefix),
-				}, nil
+		prefixes := ctx.GuildConfig.Prefixes
+		if newPrefix == ctx.GuildConfig.Prefix {
+			return &commands.CommandResponse{Content: ctx.T("prefix.same", newPrefix)}, nil
+		}
+
+		// Replace the main prefix, dropping the new one from the others
+		updated := []string{newPrefix}
+		for _, p := range prefixes[1:] {
+			if p != newPrefix {
+				updated = append(updated, p)
 			}
+		}
+		return updatePrefixes(ctx, updated, ctx.T("prefix.changed", newPrefix))
+	},
+}
 
-			newPrefix := strings.ToLower(strings.Join(ctx.Args, " "))
+var prefixAddCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:        []string{"add"},
+		Description:     "Add another prefix Dank Memer listens to",
+		Cooldown:        5000,
+		UserPermissions: discordgo.PermissionManageServer,
+		Args:            prefixArg,
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		newPrefix, problem := checkPrefix(ctx, ctx.ArgString("prefix"))
+		if problem != "" {
+			return &commands.CommandResponse{Content: problem}, nil
+		}
 
-			// Check length
-			if len(newPrefix) > 32 {
-				return &commands.CommandResponse{
-					Content: fmt.Sprintf("Your prefix can't be over 32 characters long. You're %d characters over the limit.", len(newPrefix)-32),
-				}, nil
-			}
+		prefixes := ctx.GuildConfig.Prefixes
+		if slices.Contains(prefixes, newPrefix) {
+			return &commands.CommandResponse{Content: ctx.T("prefix.exists", newPrefix)}, nil
+		}
+		if len(prefixes) >= maxPrefixes {
+			return &commands.CommandResponse{
+				Content: ctx.T("prefix.limit", maxPrefixes, ctx.GuildConfig.Prefix),
+			}, nil
+		}
+
+		updated := append(slices.Clone(prefixes), newPrefix)
+		return updatePrefixes(ctx, updated, ctx.T("prefix.added", newPrefix, formatPrefixes(ctx, updated)))
+	},
+}
+
+var prefixRemoveCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:        []string{"remove", "delete"},
+		Description:     "Stop Dank Memer from listening to a prefix",
+		Cooldown:        5000,
+		UserPermissions: discordgo.PermissionManageServer,
+		Args:            prefixArg,
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		prefix := cleanPrefix(ctx.ArgString("prefix"))
+
+		prefixes := ctx.GuildConfig.Prefixes
+		i := slices.Index(prefixes, prefix)
+		if i < 0 {
+			return &commands.CommandResponse{
+				Content: ctx.T("prefix.not_found", prefix, formatPrefixes(ctx, prefixes)),
+			}, nil
+		}
+		if len(prefixes) == 1 {
+			return &commands.CommandResponse{Content: ctx.T("prefix.only", ctx.GuildConfig.Prefix)}, nil
+		}
+
+		// Removing the main prefix promotes the next one
+		updated := slices.Delete(slices.Clone(prefixes), i, i+1)
+		return updatePrefixes(ctx, updated, ctx.T("prefix.removed", prefix, formatPrefixes(ctx, updated)))
+	},
+}
+
+var prefixResetCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:        []string{"reset"},
+		Description:     "Reset Dank Memer's prefixes to the default",
+		Cooldown:        5000,
+		UserPermissions: discordgo.PermissionManageServer,
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		defaultPrefix := ctx.Services.Config.DefaultPrefix
+		if slices.Equal(ctx.GuildConfig.Prefixes, []string{defaultPrefix}) {
+			return &commands.CommandResponse{Content: ctx.T("prefix.already_default", defaultPrefix)}, nil
+		}
+		return updatePrefixes(ctx, []string{defaultPrefix}, ctx.T("prefix.reset", defaultPrefix))
+	},
+}
 
-			// Check if same
-			if newPrefix == currentPrefix {
-				return &commands.CommandResponse{
-					Content: fmt.Sprintf("`%s` is already your current prefix.", currentPrefix),
-				}, nil
+var prefixListCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"list", "show"},
+		Description: "Show the prefixes you can use here",
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		prefix := ctx.GuildConfig.Prefix
+		content := ctx.T("prefix.list", prefix, prefix)
+		if others := ctx.GuildConfig.Prefixes[1:]; len(others) > 0 {
+			content += "\n" + ctx.T("prefix.list_others", formatPrefixes(ctx, others))
+		}
+
+		personal, err := ctx.Services.DB.GetUserPrefix(ctx.Context, ctx.Message.Author.ID)
+		if err != nil {
+			return nil, err
+		}
+		if personal != "" {
+			content += "\n" + ctx.T("prefix.personal.current", personal)
+		}
+
+		return &commands.CommandResponse{Content: content}, nil
+	},
+}
+
+var prefixMeCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"me", "personal"},
+		Description: "Set a personal prefix that works for you in every server, or turn it off",
+		Cooldown:    5000,
+		Args: []commands.Arg{
+			{
+				Name:        "prefix",
+				Type:        commands.ArgText,
+				Description: "Your prefix, or off",
+				Optional:    true,
+			},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		userID := ctx.Message.Author.ID
+		current, err := ctx.Services.DB.GetUserPrefix(ctx.Context, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ctx.HasArg("prefix") {
+			if current == "" {
+				return &commands.CommandResponse{Content: ctx.T("prefix.personal.none_hint", ctx.GuildConfig.Prefix)}, nil
 			}
+			return &commands.CommandResponse{Content: ctx.T("prefix.personal.current", current)}, nil
+		}
 
-			// Update prefix
-			err = b.GetDB().UpdateGuildPrefix(ctx.Message.GuildID, newPrefix)
-			if err != nil {
+		if strings.EqualFold(ctx.ArgString("prefix"), "off") {
+			if current == "" {
+				return &commands.CommandResponse{Content: ctx.T("prefix.personal.none")}, nil
+			}
+			if err := ctx.Services.DB.SetUserPrefix(ctx.Context, userID, ""); err != nil {
 				return nil, err
 			}
+			return &commands.CommandResponse{Content: ctx.T("prefix.personal.removed")}, nil
+		}
 
-			embed := &discordgo.MessageEmbed{
-				Description: fmt.Sprintf("Prefix successfully changed to `%s`.", newPrefix),
-				Color:       utils.RandomColor(),
-			}
+		newPrefix, problem := checkPrefix(ctx, ctx.ArgString("prefix"))
+		if problem != "" {
+			return &commands.CommandResponse{Content: problem}, nil
+		}
+		// Personal prefixes work everywhere, so a single character would
+		// catch everyday messages like "." or "a"
+		if utf8.RuneCountInString(newPrefix) < minPersonalPrefixLength {
+			return &commands.CommandResponse{Content: ctx.T("prefix.personal.too_short", minPersonalPrefixLength)}, nil
+		}
+		if newPrefix == current {
+			return &commands.CommandResponse{Content: ctx.T("prefix.personal.same", newPrefix)}, nil
+		}
 
-			return &commands.CommandResponse{Embed: embed}, nil
-		},
-	})
+		if err := ctx.Services.DB.SetUserPrefix(ctx.Context, userID, newPrefix); err != nil {
+			return nil, err
+		}
+		return &commands.CommandResponse{Content: ctx.T("prefix.personal.changed", newPrefix)}, nil
+	},
 }
 
-type prefixBot interface {
-	GetDB() prefixDB
-	GetConfig() *utils.Config
+// prefixCommand keeps "pls prefix <prefix>" working by defaulting to set
+var prefixCommand = &commands.Group{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"prefix", "prefixes"},
+		Description: "Change Dank Memer's prefixes!",
+		Category:    "Utility",
+		Cooldown:    5000,
+		Permissions: []int64{discordgo.PermissionEmbedLinks},
+	},
+	Subcommands: []commands.Command{
+		prefixSetCommand, prefixAddCommand, prefixRemoveCommand, prefixResetCommand, prefixListCommand, prefixMeCommand,
+	},
+	Default: prefixSetCommand,
 }
 
-type prefixDB interface {
-	UpdateGuildPrefix(guildID, prefix string) error
+// checkPrefix cleans up a prefix, returning why it can't be used if it can't
+func checkPrefix(ctx *commands.CommandContext, prefix string) (string, string) {
+	prefix = cleanPrefix(prefix)
+	if prefix == "" {
+		return "", ctx.T("prefix.empty")
+	}
+	if len(prefix) > maxPrefixLength {
+		return "", ctx.T("prefix.too_long", maxPrefixLength, len(prefix)-maxPrefixLength)
+	}
+	return prefix, ""
+}
+
+// cleanPrefix lowercases and trims a prefix, dropping the quotes around it
+// if it was quoted
+func cleanPrefix(prefix string) string {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if unquoted, ok := strings.CutPrefix(prefix, `"`); ok {
+		if unquoted, ok = strings.CutSuffix(unquoted, `"`); ok {
+			prefix = strings.TrimSpace(unquoted)
+		}
+	}
+	return prefix
+}
+
+// formatPrefixes lists prefixes like "`pls`, `!` and `?`"
+func formatPrefixes(ctx *commands.CommandContext, prefixes []string) string {
+	quoted := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		quoted[i] = "`" + p + "`"
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return ctx.T("prefix.join", strings.Join(quoted[:len(quoted)-1], ", "), quoted[len(quoted)-1])
+}
+
+func updatePrefixes(ctx *commands.CommandContext, prefixes []string, message string) (*commands.CommandResponse, error) {
+	if err := ctx.Services.DB.UpdateGuildPrefixes(ctx.Context, ctx.Message.GuildID, prefixes); err != nil {
+		return nil, err
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Description: message,
+		Color:       utils.RandomColor(),
+	}
+
+	return &commands.CommandResponse{Embed: embed}, nil
 }
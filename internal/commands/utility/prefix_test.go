@@ -0,0 +1,163 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utility_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+)
+
+func TestPrefixRejectsEmpty(t *testing.T) {
+	for _, content := range []string{`prefix set ""`, `prefix set "   "`, `prefix add ""`, `prefix ""`} {
+		h := newHarness(t)
+		h.SetPermissions(commandtest.AuthorID, discordgo.PermissionManageGuild)
+
+		resp, err := h.Exec(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(resp.Content, "can't be empty") {
+			t.Errorf("%s: got %q, want the prefix refused", content, resp.Content)
+		}
+		if got := h.Store.Guild(commandtest.GuildID).Prefixes; !reflect.DeepEqual(got, []string{commandtest.Prefix}) {
+			t.Errorf("%s: prefixes changed to %q", content, got)
+		}
+	}
+}
+
+func TestPrefixMeRejectsEmpty(t *testing.T) {
+	h := newHarness(t)
+
+	resp, err := h.Exec(`prefix me ""`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Content, "can't be empty") {
+		t.Errorf("got %q, want the prefix refused", resp.Content)
+	}
+	if prefix, _ := h.Store.GetUserPrefix(context.Background(), commandtest.AuthorID); prefix != "" {
+		t.Errorf("personal prefix set to %q", prefix)
+	}
+}
+
+func TestPrefixMeMinimumLength(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{`prefix me .`, ""},
+		{`prefix me a`, ""},
+		{`prefix me é`, ""},
+		{`prefix me !!`, "!!"},
+		{`prefix me "hey bot"`, "hey bot"},
+	}
+
+	for _, tt := range tests {
+		h := newHarness(t)
+
+		resp, err := h.Exec(tt.content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prefix, _ := h.Store.GetUserPrefix(context.Background(), commandtest.AuthorID)
+		if prefix != tt.want {
+			t.Errorf("%s: personal prefix is %q, want %q (reply %q)", tt.content, prefix, tt.want, resp.Content)
+		}
+	}
+}
+
+func TestPrefixSet(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+		reply   string
+	}{
+		{`prefix set !`, []string{"!"}, "changed to `!`"},
+		{`prefix set "Hey Bot"`, []string{"hey bot"}, "changed to `hey bot`"},
+		{`prefix add ?`, []string{commandtest.Prefix, "?"}, "Added `?`"},
+		{`prefix set ` + strings.Repeat("a", 40), []string{commandtest.Prefix}, "8 characters over"},
+	}
+
+	for _, tt := range tests {
+		h := newHarness(t)
+		h.SetPermissions(commandtest.AuthorID, discordgo.PermissionManageGuild)
+
+		resp, err := h.Exec(tt.content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := commandtest.Snapshot(resp); !strings.Contains(got, tt.reply) {
+			t.Errorf("%s: got %q, want %q", tt.content, got, tt.reply)
+		}
+		if got := h.Store.Guild(commandtest.GuildID).Prefixes; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: prefixes %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestPrefixSubcommandCooldown(t *testing.T) {
+	h := newHarness(t)
+	h.SetPermissions(commandtest.AuthorID, discordgo.PermissionManageGuild)
+
+	if _, err := h.Exec("prefix add ?"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := h.Exec("prefix add !")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Content, "you have to wait") {
+		t.Errorf("got %q, want the cooldown message", resp.Content)
+	}
+	if got := h.Store.Guild(commandtest.GuildID).Prefixes; !reflect.DeepEqual(got, []string{commandtest.Prefix, "?"}) {
+		t.Errorf("prefixes %q, want the second add refused", got)
+	}
+
+	// Other subcommands have their own cooldown
+	if resp, err := h.Exec("prefix list"); err != nil || strings.Contains(resp.Content, "you have to wait") {
+		t.Errorf("prefix list: got %v, %v", resp, err)
+	}
+}
+
+func TestPrefixRepliesLocalized(t *testing.T) {
+	h := newHarness(t)
+	h.SetPermissions(commandtest.AuthorID, discordgo.PermissionManageGuild)
+	if err := h.Store.UpdateGuildLocale(context.Background(), commandtest.GuildID, "de"); err != nil {
+		t.Fatal(err)
+	}
+
+	for content, want := range map[string]string{
+		"prefix add":    "Welches Präfix möchtest du hinzufügen?",
+		"prefix me .":   "mindestens 2 Zeichen",
+		"prefix list":   "Mein Präfix hier ist `" + commandtest.Prefix + "`",
+		`prefix set ""`: "darf nicht leer sein",
+	} {
+		resp, err := h.Exec(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(resp.Content, want) {
+			t.Errorf("%s: got %q, want %q", content, resp.Content, want)
+		}
+	}
+}
@@ -0,0 +1,459 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utility
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dankmemer/bot/internal/bot"
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/database"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+func init() {
+	bot.Register(&commands.Group{
+		Properties: commands.CommandProps{
+			Triggers:        []string{"rules", "rule"},
+			Description:     "Allow or deny commands in channels, or for roles and users",
+			Category:        "Utility",
+			UserPermissions: discordgo.PermissionManageServer,
+		},
+		Subcommands: []commands.Command{
+			ruleCommand(database.RuleAllow, "allow", "Allow a command or category in a channel, or for a role or user"),
+			ruleCommand(database.RuleDeny, "deny", "Deny a command or category in a channel, or for a role or user"),
+			rulesOnlyCommand, rulesRemoveCommand, rulesClearCommand, rulesListCommand, rulesCheckCommand,
+		},
+		Default: rulesListCommand,
+	})
+}
+
+// ruleArgs are the arguments of the commands that add rules. Their missing
+// messages are "commands.rules.<subcommand>.args.<name>.missing".
+var ruleArgs = []commands.Arg{
+	{
+		Name:        "target",
+		Type:        commands.ArgWord,
+		Description: "A command or category",
+	},
+	{
+		Name:        "scope",
+		Type:        commands.ArgText,
+		Description: "A channel, role or user",
+	},
+}
+
+func ruleCommand(action database.RuleAction, trigger, description string) commands.Command {
+	return &commands.BaseCommand{
+		Properties: commands.CommandProps{
+			Triggers:    []string{trigger},
+			Description: description,
+			Usage:       "{command} <command|category> <#channel|@role|@user|everyone>",
+			Cooldown:    3000,
+			Args:        ruleArgs,
+		},
+		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+			rule, resp, err := parseRule(ctx, action)
+			if rule == nil {
+				return resp, err
+			}
+			if resp, err := saveRules(ctx, *rule); resp != nil || err != nil {
+				return resp, err
+			}
+			return &commands.CommandResponse{
+				Content: ctx.T("rules.added."+string(rule.Action), formatRuleTarget(ctx, *rule), formatRuleScope(ctx, *rule)),
+			}, nil
+		},
+	}
+}
+
+var rulesOnlyCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"only"},
+		Description: "Only allow a command or category in a channel, or for a role or user",
+		Usage:       "{command} <command|category> <#channel|@role|@user>",
+		Cooldown:    3000,
+		Args:        ruleArgs,
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		rule, resp, err := parseRule(ctx, database.RuleAllow)
+		if rule == nil {
+			return resp, err
+		}
+		if rule.Scope == database.RuleRole && rule.ScopeID == ctx.Message.GuildID {
+			return &commands.CommandResponse{Content: ctx.T("rules.only_everyone")}, nil
+		}
+
+		everyone := *rule
+		everyone.Scope, everyone.ScopeID, everyone.Action = database.RuleRole, ctx.Message.GuildID, database.RuleDeny
+		if resp, err := saveRules(ctx, everyone, *rule); resp != nil || err != nil {
+			return resp, err
+		}
+		return &commands.CommandResponse{
+			Content: ctx.T("rules.only", formatRuleTarget(ctx, *rule), formatRuleScope(ctx, *rule)),
+		}, nil
+	},
+}
+
+var rulesRemoveCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"remove", "delete"},
+		Description: "Remove a rule by its number",
+		Cooldown:    3000,
+		Args: []commands.Arg{
+			{Name: "id", Type: commands.ArgInteger, Description: "The rule's number from the rules list"},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		id := ctx.ArgInt("id", 0)
+		ok, err := ctx.Services.DB.DeleteCommandRule(ctx.Context, ctx.Message.GuildID, id)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return &commands.CommandResponse{Content: ctx.T("rules.not_found", id, ctx.GuildConfig.Prefix)}, nil
+		}
+		return &commands.CommandResponse{Content: ctx.T("rules.removed", id)}, nil
+	},
+}
+
+var rulesClearCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"clear", "reset"},
+		Description: "Remove all of this server's rules",
+		Cooldown:    10000,
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		n, err := ctx.Services.DB.ClearCommandRules(ctx.Context, ctx.Message.GuildID)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return &commands.CommandResponse{Content: ctx.T("rules.none")}, nil
+		}
+		return &commands.CommandResponse{Content: ctx.Plural("rules.cleared", n)}, nil
+	},
+}
+
+var rulesListCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"list", "show"},
+		Description: "List this server's rules",
+		Permissions: []int64{discordgo.PermissionEmbedLinks},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		rules, err := ctx.Services.DB.GetCommandRules(ctx.Context, ctx.Message.GuildID)
+		if err != nil {
+			return nil, err
+		}
+		if len(rules) == 0 {
+			return &commands.CommandResponse{Content: ctx.T("rules.none_hint", ctx.GuildConfig.Prefix)}, nil
+		}
+
+		lines := make([]string, len(rules))
+		for i, rule := range rules {
+			lines[i] = fmt.Sprintf("`#%d` %s %s %s", rule.ID, ctx.T("rules.action."+string(rule.Action)), formatRuleTarget(ctx, rule), formatRuleScope(ctx, rule))
+		}
+
+		return &commands.CommandResponse{Embed: &discordgo.MessageEmbed{
+			Title:       ctx.T("rules.list.title", len(rules), commands.MaxCommandRules),
+			Description: strings.Join(lines, "\n"),
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: ctx.T("rules.list.footer"),
+			},
+			Color: utils.RandomColor(),
+		}}, nil
+	},
+}
+
+var rulesCheckCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:    []string{"check", "here"},
+		Description: "Show which rules apply in a channel",
+		Permissions: []int64{discordgo.PermissionEmbedLinks},
+		Args: []commands.Arg{
+			{Name: "channel", Type: commands.ArgChannel, Description: "The channel, this one by default", Optional: true},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		channelID := ctx.Message.ChannelID
+		if channel := ctx.ArgChannel("channel"); channel != nil {
+			channelID = channel.ID
+		}
+
+		rules, err := ctx.Services.DB.GetCommandRules(ctx.Context, ctx.Message.GuildID)
+		if err != nil {
+			return nil, err
+		}
+		lines := effectiveRules(ctx, rules, channelID)
+		if len(lines) == 0 {
+			lines = []string{ctx.T("rules.check.none")}
+		}
+
+		return &commands.CommandResponse{Embed: &discordgo.MessageEmbed{
+			Title:       ctx.T("rules.check.title"),
+			Description: ctx.T("rules.check.description", channelID, strings.Join(lines, "\n")),
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: ctx.T("rules.check.footer"),
+			},
+			Color: utils.RandomColor(),
+		}}, nil
+	},
+}
+
+// effectiveRules describes, for each command and category with rules,
+// whether members without special roles can use it in the channel and which
+// roles and users are exceptions
+func effectiveRules(ctx *commands.CommandContext, rules []database.CommandRule, channelID string) []string {
+	type target struct{ command, category string }
+	var targets []target
+	seen := make(map[target]bool)
+	for _, rule := range rules {
+		t := target{rule.Command, rule.Category}
+		if !seen[t] {
+			seen[t] = true
+			targets = append(targets, t)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		if (targets[i].category != "") != (targets[j].category != "") {
+			return targets[i].category != ""
+		}
+		return targets[i].command+targets[i].category < targets[j].command+targets[j].category
+	})
+
+	everyone := commands.RuleSubject{GuildID: ctx.Message.GuildID, ChannelID: channelID}
+	var lines []string
+	for _, t := range targets {
+		props := commands.CommandProps{Triggers: []string{t.command}, Category: t.category}
+		if t.command != "" {
+			props.Category = commands.CustomCategory
+			if cmd := ctx.Services.Registry.Find(t.command); cmd != nil {
+				props.Category = cmd.Props().Category
+			}
+		}
+
+		allowed, decided := commands.EvaluateRules(rules, props, everyone)
+		status := ctx.T("rules.check.allowed")
+		if !allowed {
+			status = ctx.T("rules.check.denied")
+		}
+		line := fmt.Sprintf("%s %s", status, formatRuleTarget(ctx, database.CommandRule{Command: t.command, Category: t.category}))
+		if decided != nil {
+			line += fmt.Sprintf(" (`#%d`)", decided.ID)
+		}
+
+		// Role and user rules beat channel rules, so any with the other
+		// action are exceptions
+		var exceptions []string
+		for _, rule := range rules {
+			if rule.Scope == database.RuleChannel || rule.ScopeID == ctx.Message.GuildID {
+				continue
+			}
+			if (rule.Action == database.RuleAllow) == allowed {
+				continue
+			}
+			if rule.Command != t.command && !(rule.Category != "" && rule.Category == commands.CategoryKey(props.Category)) {
+				continue
+			}
+			exceptions = append(exceptions, fmt.Sprintf("%s `#%d`", formatRuleScope(ctx, rule), rule.ID))
+		}
+		if len(exceptions) > 0 {
+			key := "rules.check.but_denied"
+			if !allowed {
+				key = "rules.check.but_allowed"
+			}
+			line += ctx.T(key, strings.Join(exceptions, ", "))
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseRule builds a rule from the target and scope arguments, or returns
+// the reply explaining why it can't
+func parseRule(ctx *commands.CommandContext, action database.RuleAction) (*database.CommandRule, *commands.CommandResponse, error) {
+	rule := database.CommandRule{
+		GuildID:  ctx.Message.GuildID,
+		Action:   action,
+		AuthorID: ctx.Message.Author.ID,
+	}
+
+	name := strings.ToLower(ctx.ArgString("target"))
+	command, err := ruleCommandName(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	rule.Command = command
+	if command == "" {
+		rule.Category = ctx.Services.Registry.FindCategory(name)
+		if rule.Category == "" {
+			return nil, &commands.CommandResponse{Content: ctx.T("rules.unknown_target", name)}, nil
+		}
+	}
+
+	rule.Scope, rule.ScopeID = resolveRuleScope(ctx, ctx.ArgString("scope"))
+	if rule.Scope == "" {
+		return nil, &commands.CommandResponse{Content: ctx.T("rules.unknown_scope")}, nil
+	}
+	return &rule, nil, nil
+}
+
+// saveRules stores rules, unless they would take the guild over its limit
+func saveRules(ctx *commands.CommandContext, rules ...database.CommandRule) (*commands.CommandResponse, error) {
+	existing, err := ctx.Services.DB.GetCommandRules(ctx.Context, ctx.Message.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	added := 0
+	for _, rule := range rules {
+		found := false
+		for _, e := range existing {
+			if e.Command == rule.Command && e.Category == rule.Category && e.Scope == rule.Scope && e.ScopeID == rule.ScopeID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			added++
+		}
+	}
+	if len(existing)+added > commands.MaxCommandRules {
+		return &commands.CommandResponse{
+			Content: ctx.T("rules.limit", commands.MaxCommandRules, ctx.GuildConfig.Prefix),
+		}, nil
+	}
+
+	for _, rule := range rules {
+		if err := ctx.Services.DB.SetCommandRule(ctx.Context, rule); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// ruleCommandName returns the primary trigger of a command or the name of a
+// custom command, or "" if name is neither
+func ruleCommandName(ctx *commands.CommandContext, name string) (string, error) {
+	if cmd := ctx.Services.Registry.Find(name); cmd != nil && !cmd.Props().OwnerOnly {
+		return cmd.Props().Triggers[0], nil
+	}
+	custom, err := ctx.Services.DB.GetCustomCommand(ctx.Context, ctx.Message.GuildID, name)
+	if err != nil || custom == nil {
+		return "", err
+	}
+	return custom.Name, nil
+}
+
+var (
+	ruleMentionRegex = regexp.MustCompile(`^<(#|@&|@!?)(\d+)>$`)
+	snowflakeRegex   = regexp.MustCompile(`^\d+$`)
+)
+
+// resolveRuleScope finds the channel, role or user a rule is for from a
+// mention, an ID, "everyone" or a role or channel name. Returns an empty
+// scope if there's no match in the guild.
+func resolveRuleScope(ctx *commands.CommandContext, arg string) (database.RuleScope, string) {
+	arg = strings.TrimSpace(arg)
+	guildID := ctx.Message.GuildID
+	guild, _ := ctx.Session.State.Guild(guildID)
+
+	if match := ruleMentionRegex.FindStringSubmatch(arg); match != nil {
+		switch match[1] {
+		case "#":
+			if guildChannel(ctx, match[2]) {
+				return database.RuleChannel, match[2]
+			}
+			return "", ""
+		case "@&":
+			return database.RuleRole, match[2]
+		default:
+			return database.RuleUser, match[2]
+		}
+	}
+
+	name := strings.ToLower(strings.TrimPrefix(arg, "@"))
+	if name == "everyone" {
+		return database.RuleRole, guildID
+	}
+	if snowflakeRegex.MatchString(arg) {
+		if guildChannel(ctx, arg) {
+			return database.RuleChannel, arg
+		}
+		if guild != nil {
+			for _, role := range guild.Roles {
+				if role.ID == arg {
+					return database.RuleRole, arg
+				}
+			}
+		}
+		return database.RuleUser, arg
+	}
+
+	if guild != nil {
+		for _, role := range guild.Roles {
+			if strings.ToLower(role.Name) == name {
+				return database.RuleRole, role.ID
+			}
+		}
+		name = strings.TrimPrefix(name, "#")
+		for _, channel := range guild.Channels {
+			if strings.ToLower(channel.Name) == name {
+				return database.RuleChannel, channel.ID
+			}
+		}
+	}
+	return "", ""
+}
+
+// guildChannel reports whether a channel belongs to the current guild
+func guildChannel(ctx *commands.CommandContext, channelID string) bool {
+	channel, err := ctx.Session.State.Channel(channelID)
+	if err != nil {
+		channel, err = ctx.Session.Channel(channelID)
+		if err != nil {
+			return false
+		}
+	}
+	return channel.GuildID == ctx.Message.GuildID
+}
+
+func formatRuleTarget(ctx *commands.CommandContext, rule database.CommandRule) string {
+	if rule.Category != "" {
+		return ctx.T("rules.target.category", rule.Category)
+	}
+	return fmt.Sprintf("`%s`", rule.Command)
+}
+
+func formatRuleScope(ctx *commands.CommandContext, rule database.CommandRule) string {
+	switch rule.Scope {
+	case database.RuleChannel:
+		return ctx.T("rules.scope.channel", rule.ScopeID)
+	case database.RuleRole:
+		if rule.ScopeID == rule.GuildID {
+			return ctx.T("rules.scope.everyone")
+		}
+		return ctx.T("rules.scope.role", rule.ScopeID)
+	default:
+		return ctx.T("rules.scope.user", rule.ScopeID)
+	}
+}
@@ -0,0 +1,49 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utility_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+)
+
+func TestRulesRepliesLocalized(t *testing.T) {
+	h := newHarness(t)
+	h.SetPermissions(commandtest.AuthorID, discordgo.PermissionManageGuild)
+	if err := h.Store.UpdateGuildLocale(context.Background(), commandtest.GuildID, "es"); err != nil {
+		t.Fatal(err)
+	}
+
+	for content, want := range map[string]string{
+		"rules clear":           "Este servidor no tiene reglas.",
+		"rules deny":            "¿Para qué comando o categoría es la regla?",
+		"rules deny nothing @x": "`nothing` no es un comando ni una categoría.",
+	} {
+		resp, err := h.Exec(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(resp.Content, want) {
+			t.Errorf("%s: got %q, want %q", content, resp.Content, want)
+		}
+	}
+}
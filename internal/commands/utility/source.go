@@ -29,6 +29,7 @@ func init() {
 			Triggers:    []string{"source", "sourcecode", "github", "code"},
 			Description: "Get the source code for this bot (AGPL-3.0 licensed)",
 			Category:    "Utility Commands",
+			DMAllowed:   true,
 			Permissions: []int64{discordgo.PermissionEmbedLinks},
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
@@ -42,17 +42,8 @@ func init() {
 			// Get stats from database
 			var guilds, users, channels int
 
-			dbBot, ok := ctx.Bot.(interface {
-				GetDB() interface {
-					GetStats() (int, int, int, error)
-				}
-			})
-
-			if ok {
-				g, u, c, err := dbBot.GetDB().GetStats()
-				if err == nil {
-					guilds, users, channels = g, u, c
-				}
+			if stats, err := ctx.Services.DB.GetStats(ctx.Context); err == nil {
+				guilds, users, channels = stats.Guilds, stats.Users, stats.Channels
 			}
 
 			// Fallback to session state if available
@@ -65,13 +56,7 @@ func init() {
 			}
 
 			// Get command count
-			cmdCount := 0
-			cmdBot, ok := ctx.Bot.(interface {
-				GetCommands() *commands.Registry
-			})
-			if ok {
-				cmdCount = len(cmdBot.GetCommands().GetAll())
-			}
+			cmdCount := ctx.Services.Registry.Count()
 
 			// Calculate uptime
 			uptime := time.Since(startTime)
@@ -0,0 +1,59 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utility
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dankmemer/bot/internal/commands"
+)
+
+// suggestionsCommand toggles "did you mean" replies to unknown commands.
+// Servers sharing a prefix with other bots may want them off.
+var suggestionsCommand = &commands.BaseCommand{
+	Properties: commands.CommandProps{
+		Triggers:        []string{"suggestions"},
+		Description:     "Toggle command suggestions when someone makes a typo",
+		Cooldown:        5000,
+		UserPermissions: discordgo.PermissionManageServer,
+		Args: []commands.Arg{
+			{
+				Name:        "state",
+				Type:        commands.ArgChoice,
+				Description: "Whether suggestions are on",
+				Choices:     []string{"on", "off"},
+			},
+		},
+	},
+	Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+		enabled := ctx.ArgString("state") == "on"
+		if enabled == ctx.GuildConfig.Suggestions {
+			return &commands.CommandResponse{
+				Content: fmt.Sprintf("Command suggestions are already %s.", ctx.ArgString("state")),
+			}, nil
+		}
+
+		if err := ctx.Services.DB.UpdateGuildSuggestions(ctx.Context, ctx.Message.GuildID, enabled); err != nil {
+			return nil, err
+		}
+
+		return &commands.CommandResponse{
+			Content: fmt.Sprintf("Command suggestions are now %s.", ctx.ArgString("state")),
+		}, nil
+	},
+}
@@ -27,6 +27,7 @@ func init() {
 			Triggers:    []string{"website", "site"},
 			Description: "Come check out our website!",
 			Category:    "Utility Commands",
+			DMAllowed:   true,
 		},
 		Handler: func(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
 			return &commands.CommandResponse{
@@ -69,14 +69,8 @@ func (c *VoiceCommand) Run(ctx *CommandContext) (*CommandResponse, error) {
 		return &CommandResponse{Content: "join a voice channel fam"}, nil
 	}
 
-	// Get the bot interface
-	bot, ok := ctx.Bot.(voiceBot)
-	if !ok {
-		return nil, fmt.Errorf("bot does not support voice")
-	}
-
 	// Check if already playing in this guild
-	if bot.IsVoicePlaying(ctx.Message.GuildID) {
+	if ctx.Services.Voice.IsPlaying(ctx.Message.GuildID) {
 		msg := c.ExistingConn
 		if msg == "" {
 			msg = "I'm already playing something. Please wait until the current sound is done."
@@ -117,7 +111,7 @@ func (c *VoiceCommand) Run(ctx *CommandContext) (*CommandResponse, error) {
 	}
 
 	// Play audio
-	err = bot.PlayAudio(ctx.Message.GuildID, voiceState.ChannelID, audioPath)
+	err = ctx.Services.Voice.PlayAudio(ctx.Message.GuildID, voiceState.ChannelID, audioPath)
 	if err != nil {
 		return &CommandResponse{Content: fmt.Sprintf("Error playing audio: %s", err.Error())}, nil
 	}
@@ -150,10 +144,3 @@ func (c *VoiceCommand) getUserVoiceState(ctx *CommandContext) (*discordgo.VoiceS
 
 	return nil, nil
 }
-
-// Interface to access bot's voice functionality
-type voiceBot interface {
-	IsVoicePlaying(guildID string) bool
-	PlayAudio(guildID, channelID, audioPath string) error
-	StopAudio(guildID string) error
-}
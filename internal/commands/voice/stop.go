@@ -36,25 +36,13 @@ func init() {
 				return &commands.CommandResponse{Content: "join a voice channel fam"}, nil
 			}
 
-			// Get the bot interface
-			botInterface, ok := ctx.Bot.(interface {
-				IsVoicePlaying(guildID string) bool
-				StopAudio(guildID string) error
-			})
-			if !ok {
-				return &commands.CommandResponse{Content: "Voice not supported"}, nil
-			}
-
 			// Check if playing
-			if !botInterface.IsVoicePlaying(ctx.Message.GuildID) {
+			if !ctx.Services.Voice.IsPlaying(ctx.Message.GuildID) {
 				return &commands.CommandResponse{Content: "I'm not playing anything right now!"}, nil
 			}
 
 			// Stop playback
-			err := botInterface.StopAudio(ctx.Message.GuildID)
-			if err != nil {
-				return &commands.CommandResponse{Content: "Error stopping playback"}, nil
-			}
+			ctx.Services.Voice.Stop(ctx.Message.GuildID)
 
 			// Add reaction
 			ctx.Session.MessageReactionAdd(ctx.Message.ChannelID, ctx.Message.ID, "❌")
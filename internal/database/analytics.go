@@ -0,0 +1,157 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Outcomes of a command invocation
+const (
+	OutcomeOK      = "ok"
+	OutcomeError   = "error"
+	OutcomeTimeout = "timeout"
+	OutcomePanic   = "panic"
+)
+
+// CommandUsage is one recorded command invocation
+type CommandUsage struct {
+	Command   string
+	GuildID   string
+	UserHash  string // Salted hash of the user ID, never the ID itself
+	Duration  int64  // Milliseconds
+	Outcome   string
+	CreatedAt time.Time
+}
+
+// UsageOrder is how GetCommandUsageStats sorts commands
+type UsageOrder int
+
+const (
+	UsageByUses     UsageOrder = iota // Most used first
+	UsageByDuration                   // Slowest on average first
+	UsageByFailures                   // Most failed first, leaving out commands that never failed
+)
+
+// CommandUsageStat summarizes the invocations of one command
+type CommandUsageStat struct {
+	Command     string
+	Uses        int64
+	Failures    int64
+	AvgDuration int64 // Milliseconds
+	MaxDuration int64 // Milliseconds
+}
+
+// CommandUsageTotals summarizes all invocations in a window
+type CommandUsageTotals struct {
+	Uses     int64
+	Failures int64
+	Users    int64
+	Guilds   int64
+}
+
+// InsertCommandUsage records a batch of invocations in one statement
+func (db *Database) InsertCommandUsage(ctx context.Context, usage []CommandUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(usage))
+	args := make([]interface{}, 0, len(usage)*6)
+	for _, u := range usage {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?)")
+		args = append(args, u.Command, u.GuildID, u.UserHash, u.Duration, u.Outcome, u.CreatedAt)
+	}
+
+	_, err := db.pool.ExecContext(ctx, `
+		INSERT INTO command_usage (command, guild_id, user_hash, duration_ms, outcome, created_at)
+		VALUES `+strings.Join(placeholders, ", "), args...)
+	return err
+}
+
+// DeleteCommandUsageBefore deletes invocations older than a time
+func (db *Database) DeleteCommandUsageBefore(ctx context.Context, before time.Time) error {
+	_, err := db.pool.ExecContext(ctx, `
+		DELETE FROM command_usage WHERE created_at < ?`, before)
+	return err
+}
+
+// GetCommandUsageStats returns the top commands since a time. An empty guildID
+// covers every guild.
+func (db *Database) GetCommandUsageStats(ctx context.Context, guildID string, since time.Time, order UsageOrder, limit int) ([]CommandUsageStat, error) {
+	where := "created_at >= ?"
+	args := []interface{}{since}
+	if guildID != "" {
+		where += " AND guild_id = ?"
+		args = append(args, guildID)
+	}
+
+	having := ""
+	orderBy := "uses DESC"
+	switch order {
+	case UsageByDuration:
+		orderBy = "avg_duration DESC"
+	case UsageByFailures:
+		having = "HAVING failures > 0"
+		orderBy = "failures DESC, uses ASC"
+	}
+	args = append(args, limit)
+
+	rows, err := db.pool.QueryContext(ctx, `
+		SELECT command, COUNT(*) AS uses, SUM(outcome <> 'ok') AS failures,
+			CAST(AVG(duration_ms) AS UNSIGNED) AS avg_duration, MAX(duration_ms)
+		FROM command_usage WHERE `+where+`
+		GROUP BY command `+having+`
+		ORDER BY `+orderBy+`, command LIMIT ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []CommandUsageStat
+	for rows.Next() {
+		var s CommandUsageStat
+		if err := rows.Scan(&s.Command, &s.Uses, &s.Failures, &s.AvgDuration, &s.MaxDuration); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// GetCommandUsageTotals sums up the invocations since a time. An empty guildID
+// covers every guild.
+func (db *Database) GetCommandUsageTotals(ctx context.Context, guildID string, since time.Time) (*CommandUsageTotals, error) {
+	where := "created_at >= ?"
+	args := []interface{}{since}
+	if guildID != "" {
+		where += " AND guild_id = ?"
+		args = append(args, guildID)
+	}
+
+	var t CommandUsageTotals
+	err := db.pool.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(outcome <> 'ok'), 0), COUNT(DISTINCT user_hash), COUNT(DISTINCT guild_id)
+		FROM command_usage WHERE `+where, args...).
+		Scan(&t.Uses, &t.Failures, &t.Users, &t.Guilds)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -17,6 +17,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 )
 
@@ -33,9 +34,9 @@ type BlockedEntry struct {
 	Reason string
 }
 
-func (db *Database) IsBlocked(id string) (bool, error) {
+func (db *Database) IsBlocked(ctx context.Context, id string) (bool, error) {
 	var exists int
-	err := db.pool.QueryRow(`
+	err := db.pool.QueryRowContext(ctx, `
 		SELECT 1 FROM blocked WHERE id = ?`, id).Scan(&exists)
 	if err == sql.ErrNoRows {
 		return false, nil
@@ -46,9 +47,9 @@ func (db *Database) IsBlocked(id string) (bool, error) {
 	return true, nil
 }
 
-func (db *Database) IsUserOrGuildBlocked(userID, guildID string) (bool, error) {
+func (db *Database) IsUserOrGuildBlocked(ctx context.Context, userID, guildID string) (bool, error) {
 	var exists int
-	err := db.pool.QueryRow(`
+	err := db.pool.QueryRowContext(ctx, `
 		SELECT 1 FROM blocked WHERE id IN (?, ?)`, userID, guildID).Scan(&exists)
 	if err == sql.ErrNoRows {
 		return false, nil
@@ -59,21 +60,21 @@ func (db *Database) IsUserOrGuildBlocked(userID, guildID string) (bool, error) {
 	return true, nil
 }
 
-func (db *Database) Block(id string, blockType BlockType, reason string) error {
-	_, err := db.pool.Exec(`
+func (db *Database) Block(ctx context.Context, id string, blockType BlockType, reason string) error {
+	_, err := db.pool.ExecContext(ctx, `
 		INSERT INTO blocked (id, type, reason) VALUES (?, ?, ?)
 		ON DUPLICATE KEY UPDATE reason = ?`, id, blockType, reason, reason)
 	return err
 }
 
-func (db *Database) Unblock(id string) error {
-	_, err := db.pool.Exec(`DELETE FROM blocked WHERE id = ?`, id)
+func (db *Database) Unblock(ctx context.Context, id string) error {
+	_, err := db.pool.ExecContext(ctx, `DELETE FROM blocked WHERE id = ?`, id)
 	return err
 }
 
-func (db *Database) GetBlocked(id string) (*BlockedEntry, error) {
+func (db *Database) GetBlocked(ctx context.Context, id string) (*BlockedEntry, error) {
 	var entry BlockedEntry
-	err := db.pool.QueryRow(`
+	err := db.pool.QueryRowContext(ctx, `
 		SELECT id, type, reason FROM blocked WHERE id = ?`, id).
 		Scan(&entry.ID, &entry.Type, &entry.Reason)
 	if err == sql.ErrNoRows {
@@ -0,0 +1,77 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCache caches lookups that happen for every message or command,
+// including misses, for a while. Writes through Database update or forget
+// the cached value.
+type ttlCache[V any] struct {
+	mu      sync.RWMutex
+	entries map[string]ttlEntry[V]
+	ttl     time.Duration
+	max     int // Entries kept before expired ones are swept
+}
+
+type ttlEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+func newTTLCache[V any](ttl time.Duration, max int) *ttlCache[V] {
+	return &ttlCache[V]{entries: make(map[string]ttlEntry[V]), ttl: ttl, max: max}
+}
+
+func (c *ttlCache[V]) get(key string) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (c *ttlCache[V]) set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= c.max {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		// Everything is fresh, start over rather than grow
+		if len(c.entries) >= c.max {
+			c.entries = make(map[string]ttlEntry[V])
+		}
+	}
+	c.entries[key] = ttlEntry[V]{value: value, expires: now.Add(c.ttl)}
+}
+
+func (c *ttlCache[V]) forget(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
@@ -17,13 +17,14 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"time"
 )
 
-func (db *Database) GetCooldown(command, userID string) (int64, error) {
+func (db *Database) GetCooldown(ctx context.Context, command, userID string) (int64, error) {
 	var expiresAt int64
-	err := db.pool.QueryRow(`
+	err := db.pool.QueryRowContext(ctx, `
 		SELECT expires_at FROM cooldowns
 		WHERE user_id = ? AND command = ?`, userID, command).
 		Scan(&expiresAt)
@@ -34,9 +35,9 @@ func (db *Database) GetCooldown(command, userID string) (int64, error) {
 	return expiresAt, err
 }
 
-func (db *Database) SetCooldown(command, userID string, durationMs int64) error {
+func (db *Database) SetCooldown(ctx context.Context, command, userID string, durationMs int64) error {
 	expiresAt := time.Now().UnixMilli() + durationMs
-	_, err := db.pool.Exec(`
+	_, err := db.pool.ExecContext(ctx, `
 		INSERT INTO cooldowns (user_id, command, expires_at)
 		VALUES (?, ?, ?)
 		ON DUPLICATE KEY UPDATE expires_at = ?`,
@@ -44,19 +45,44 @@ func (db *Database) SetCooldown(command, userID string, durationMs int64) error
 	return err
 }
 
-func (db *Database) ClearCooldown(command, userID string) error {
-	_, err := db.pool.Exec(`
+// ClaimCooldown starts a cooldown unless it is already running, in which
+// case it returns the time left in ms. Concurrent claims can't both succeed.
+func (db *Database) ClaimCooldown(ctx context.Context, command, userID string, durationMs int64) (int64, error) {
+	now := time.Now().UnixMilli()
+	// A row that is still running is left alone and counts as unaffected
+	res, err := db.pool.ExecContext(ctx, `
+		INSERT INTO cooldowns (user_id, command, expires_at)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE expires_at = IF(expires_at > ?, expires_at, VALUES(expires_at))`,
+		userID, command, now+durationMs, now)
+	if err != nil {
+		return 0, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return 0, err
+	}
+
+	remaining, err := db.IsOnCooldown(ctx, command, userID)
+	if err != nil {
+		return 0, err
+	}
+	// It ran out in between, which still means someone else had it
+	return max(remaining, 1), nil
+}
+
+func (db *Database) ClearCooldown(ctx context.Context, command, userID string) error {
+	_, err := db.pool.ExecContext(ctx, `
 		DELETE FROM cooldowns WHERE user_id = ? AND command = ?`, userID, command)
 	return err
 }
 
-func (db *Database) ClearAllCooldowns(userID string) error {
-	_, err := db.pool.Exec(`DELETE FROM cooldowns WHERE user_id = ?`, userID)
+func (db *Database) ClearAllCooldowns(ctx context.Context, userID string) error {
+	_, err := db.pool.ExecContext(ctx, `DELETE FROM cooldowns WHERE user_id = ?`, userID)
 	return err
 }
 
-func (db *Database) CleanupExpiredCooldowns() (int64, error) {
-	result, err := db.pool.Exec(`
+func (db *Database) CleanupExpiredCooldowns(ctx context.Context) (int64, error) {
+	result, err := db.pool.ExecContext(ctx, `
 		DELETE FROM cooldowns WHERE expires_at < ?`, time.Now().UnixMilli())
 	if err != nil {
 		return 0, err
@@ -65,8 +91,8 @@ func (db *Database) CleanupExpiredCooldowns() (int64, error) {
 }
 
 // IsOnCooldown returns remaining time in ms if on cooldown, 0 if not
-func (db *Database) IsOnCooldown(command, userID string) (int64, error) {
-	expiresAt, err := db.GetCooldown(command, userID)
+func (db *Database) IsOnCooldown(ctx context.Context, command, userID string) (int64, error) {
+	expiresAt, err := db.GetCooldown(ctx, command, userID)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,136 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type CustomCommand struct {
+	GuildID   string
+	Name      string
+	Response  string // Response template
+	AuthorID  string // User who last changed the command
+	UpdatedAt time.Time
+}
+
+const (
+	customCommandsTTL     = 5 * time.Minute // How long a guild's custom command names are cached
+	maxCachedCustomGuilds = 50000           // Entries kept before expired ones are swept
+)
+
+// GetCustomCommand returns a guild's custom command, or nil if there is none.
+// Names the guild doesn't have are answered from the cache.
+func (db *Database) GetCustomCommand(ctx context.Context, guildID, name string) (*CustomCommand, error) {
+	names, err := db.customCommandNames(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := names[strings.ToLower(name)]; !ok {
+		return nil, nil
+	}
+
+	var c CustomCommand
+	err = db.pool.QueryRowContext(ctx, `
+		SELECT guild_id, name, response, author_id, updated_at
+		FROM custom_commands WHERE guild_id = ? AND name = ?`, guildID, name).
+		Scan(&c.GuildID, &c.Name, &c.Response, &c.AuthorID, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (db *Database) GetCustomCommands(ctx context.Context, guildID string) ([]CustomCommand, error) {
+	rows, err := db.pool.QueryContext(ctx, `
+		SELECT guild_id, name, response, author_id, updated_at
+		FROM custom_commands WHERE guild_id = ? ORDER BY name`, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cmds []CustomCommand
+	for rows.Next() {
+		var c CustomCommand
+		if err := rows.Scan(&c.GuildID, &c.Name, &c.Response, &c.AuthorID, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, c)
+	}
+	return cmds, rows.Err()
+}
+
+// customCommandNames returns the lowercased names of a guild's custom
+// commands. The map is shared and must not be modified.
+func (db *Database) customCommandNames(ctx context.Context, guildID string) (map[string]struct{}, error) {
+	if names, ok := db.customNames.get(guildID); ok {
+		return names, nil
+	}
+
+	rows, err := db.pool.QueryContext(ctx, `
+		SELECT name FROM custom_commands WHERE guild_id = ?`, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make(map[string]struct{})
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names[strings.ToLower(name)] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	db.customNames.set(guildID, names)
+	return names, nil
+}
+
+func (db *Database) SetCustomCommand(ctx context.Context, cmd CustomCommand) error {
+	_, err := db.pool.ExecContext(ctx, `
+		INSERT INTO custom_commands (guild_id, name, response, author_id) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE response = VALUES(response), author_id = VALUES(author_id)`,
+		cmd.GuildID, cmd.Name, cmd.Response, cmd.AuthorID)
+	if err != nil {
+		return err
+	}
+	db.customNames.forget(cmd.GuildID)
+	return nil
+}
+
+// DeleteCustomCommand deletes a custom command, reporting whether it existed
+func (db *Database) DeleteCustomCommand(ctx context.Context, guildID, name string) (bool, error) {
+	res, err := db.pool.ExecContext(ctx, `
+		DELETE FROM custom_commands WHERE guild_id = ? AND name = ?`, guildID, name)
+	if err != nil {
+		return false, err
+	}
+	db.customNames.forget(guildID)
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -26,7 +26,11 @@ import (
 )
 
 type Database struct {
-	pool *sql.DB
+	pool        *sql.DB
+	prefixes    *ttlCache[string]              // Personal prefixes by user, looked up for every message
+	rules       *ttlCache[[]CommandRule]       // Command rules by guild, looked up for every command
+	donators    *ttlCache[*Donator]            // Donators by user, nil for non-donators
+	customNames *ttlCache[map[string]struct{}] // Custom command names by guild, looked up for every unknown trigger
 }
 
 func New(cfg utils.DatabaseConfig) (*Database, error) {
@@ -46,7 +50,13 @@ func New(cfg utils.DatabaseConfig) (*Database, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &Database{pool: pool}, nil
+	return &Database{
+		pool:        pool,
+		prefixes:    newTTLCache[string](userPrefixTTL, maxCachedPrefixes),
+		rules:       newTTLCache[[]CommandRule](commandRulesTTL, maxCachedRuleGuilds),
+		donators:    newTTLCache[*Donator](donatorTTL, maxCachedDonators),
+		customNames: newTTLCache[map[string]struct{}](customCommandsTTL, maxCachedCustomGuilds),
+	}, nil
 }
 
 func (db *Database) Close() error {
@@ -17,60 +17,114 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"time"
 )
 
 type Donator struct {
-	ID    string
-	Level int
+	ID        string
+	Level     int       // Donator tier, starting at 1
+	ExpiresAt time.Time // When the tier runs out, zero for never
 }
 
-func (db *Database) IsDonator(userID string) (bool, error) {
-	var exists int
-	err := db.pool.QueryRow(`
-		SELECT 1 FROM donators WHERE id = ?`, userID).Scan(&exists)
-	if err == sql.ErrNoRows {
-		return false, nil
-	}
-	if err != nil {
-		return false, err
-	}
-	return true, nil
+// Active reports whether the donator's tier hasn't run out
+func (d *Donator) Active() bool {
+	return d.ExpiresAt.IsZero() || time.Now().Before(d.ExpiresAt)
+}
+
+const (
+	donatorTTL        = 10 * time.Minute // How long a user's donator status is cached
+	maxCachedDonators = 100000           // Entries kept before expired ones are swept
+)
+
+func (db *Database) IsDonator(ctx context.Context, userID string) (bool, error) {
+	level, err := db.GetDonatorLevel(ctx, userID)
+	return level > 0, err
 }
 
-func (db *Database) GetDonator(userID string) (*Donator, error) {
+// GetDonator returns a user's donator row, including a tier that ran out, or
+// nil if they never donated
+func (db *Database) GetDonator(ctx context.Context, userID string) (*Donator, error) {
+	if d, ok := db.donators.get(userID); ok {
+		return d, nil
+	}
+
 	var d Donator
-	err := db.pool.QueryRow(`
-		SELECT id, level FROM donators WHERE id = ?`, userID).
-		Scan(&d.ID, &d.Level)
+	var expires sql.NullTime
+	err := db.pool.QueryRowContext(ctx, `
+		SELECT id, level, expires_at FROM donators WHERE id = ?`, userID).
+		Scan(&d.ID, &d.Level, &expires)
 	if err == sql.ErrNoRows {
+		db.donators.set(userID, nil)
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	d.ExpiresAt = expires.Time
+
+	db.donators.set(userID, &d)
 	return &d, nil
 }
 
-func (db *Database) GetDonatorLevel(userID string) (int, error) {
-	d, err := db.GetDonator(userID)
+// GetDonatorLevel returns a user's active donator tier, or 0 if they have none
+func (db *Database) GetDonatorLevel(ctx context.Context, userID string) (int, error) {
+	d, err := db.GetDonator(ctx, userID)
 	if err != nil {
 		return 0, err
 	}
-	if d == nil {
+	if d == nil || !d.Active() {
 		return 0, nil
 	}
 	return d.Level, nil
 }
 
-func (db *Database) SetDonator(userID string, level int) error {
-	_, err := db.pool.Exec(`
-		INSERT INTO donators (id, level) VALUES (?, ?)
-		ON DUPLICATE KEY UPDATE level = ?`, userID, level, level)
-	return err
+// GetDonators returns every donator, including those whose tier ran out,
+// highest tier first
+func (db *Database) GetDonators(ctx context.Context) ([]Donator, error) {
+	rows, err := db.pool.QueryContext(ctx, `
+		SELECT id, level, expires_at FROM donators ORDER BY level DESC, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var donators []Donator
+	for rows.Next() {
+		var d Donator
+		var expires sql.NullTime
+		if err := rows.Scan(&d.ID, &d.Level, &expires); err != nil {
+			return nil, err
+		}
+		d.ExpiresAt = expires.Time
+		donators = append(donators, d)
+	}
+	return donators, rows.Err()
 }
 
-func (db *Database) RemoveDonator(userID string) error {
-	_, err := db.pool.Exec(`DELETE FROM donators WHERE id = ?`, userID)
-	return err
+// SetDonator gives a user a donator tier until expiresAt, or forever if it's
+// zero
+func (db *Database) SetDonator(ctx context.Context, userID string, level int, expiresAt time.Time) error {
+	var expires sql.NullTime
+	if !expiresAt.IsZero() {
+		expires = sql.NullTime{Time: expiresAt, Valid: true}
+	}
+	_, err := db.pool.ExecContext(ctx, `
+		INSERT INTO donators (id, level, expires_at) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE level = VALUES(level), expires_at = VALUES(expires_at)`, userID, level, expires)
+	if err != nil {
+		return err
+	}
+	db.donators.forget(userID)
+	return nil
+}
+
+func (db *Database) RemoveDonator(ctx context.Context, userID string) error {
+	_, err := db.pool.ExecContext(ctx, `DELETE FROM donators WHERE id = ?`, userID)
+	if err != nil {
+		return err
+	}
+	db.donators.forget(userID)
+	return nil
 }
@@ -17,25 +17,34 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"slices"
 )
 
 type GuildConfig struct {
-	ID               string
-	Prefix           string
-	DisabledCommands []string
-	Premium          bool
+	ID                 string
+	Prefix             string   // Main prefix, shown in help
+	Prefixes           []string // Every prefix, starting with Prefix
+	DisabledCommands   []string
+	DisabledCategories []string // Normalized category names, e.g. "voice"
+	Premium            bool
+	Suggestions        bool              // Whether to suggest commands for unknown triggers
+	Aliases            map[string]string // Alias → command it runs, e.g. "m" → "meme"
+	Locale             string            // Language of bot responses, e.g. "en" or "pt-br"
 }
 
-func (db *Database) GetGuild(guildID string) (*GuildConfig, error) {
+func (db *Database) GetGuild(ctx context.Context, guildID string) (*GuildConfig, error) {
 	var cfg GuildConfig
-	var disabledJSON []byte
+	var disabledJSON, categoriesJSON, aliasesJSON, prefixesJSON []byte
+	var locale sql.NullString
 
-	err := db.pool.QueryRow(`
-		SELECT id, prefix, disabled_commands, premium
+	err := db.pool.QueryRowContext(ctx, `
+		SELECT id, prefix, extra_prefixes, disabled_commands, disabled_categories, premium, suggestions, aliases, locale
 		FROM guilds WHERE id = ?`, guildID).
-		Scan(&cfg.ID, &cfg.Prefix, &disabledJSON, &cfg.Premium)
+		Scan(&cfg.ID, &cfg.Prefix, &prefixesJSON, &disabledJSON, &categoriesJSON, &cfg.Premium, &cfg.Suggestions, &aliasesJSON, &locale)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -44,67 +53,136 @@ func (db *Database) GetGuild(guildID string) (*GuildConfig, error) {
 		return nil, err
 	}
 
+	cfg.Prefixes = []string{cfg.Prefix}
+	if len(prefixesJSON) > 0 {
+		var extra []string
+		json.Unmarshal(prefixesJSON, &extra)
+		cfg.Prefixes = append(cfg.Prefixes, extra...)
+	}
 	if len(disabledJSON) > 0 {
 		json.Unmarshal(disabledJSON, &cfg.DisabledCommands)
 	}
 	if cfg.DisabledCommands == nil {
 		cfg.DisabledCommands = []string{}
 	}
+	if len(categoriesJSON) > 0 {
+		json.Unmarshal(categoriesJSON, &cfg.DisabledCategories)
+	}
+	if cfg.DisabledCategories == nil {
+		cfg.DisabledCategories = []string{}
+	}
+	if len(aliasesJSON) > 0 {
+		json.Unmarshal(aliasesJSON, &cfg.Aliases)
+	}
+	if cfg.Aliases == nil {
+		cfg.Aliases = map[string]string{}
+	}
+	cfg.Locale = locale.String
+	if cfg.Locale == "" {
+		cfg.Locale = "en"
+	}
 
 	return &cfg, nil
 }
 
-func (db *Database) CreateGuild(guildID, prefix string) (*GuildConfig, error) {
-	_, err := db.pool.Exec(`
+func (db *Database) CreateGuild(ctx context.Context, guildID, prefix string) (*GuildConfig, error) {
+	_, err := db.pool.ExecContext(ctx, `
 		INSERT INTO guilds (id, prefix, disabled_commands)
 		VALUES (?, ?, '[]')
 		ON DUPLICATE KEY UPDATE id=id`, guildID, prefix)
 	if err != nil {
 		return nil, err
 	}
-	return db.GetGuild(guildID)
+	return db.GetGuild(ctx, guildID)
 }
 
-func (db *Database) UpdateGuildPrefix(guildID, prefix string) error {
-	_, err := db.pool.Exec(`
+func (db *Database) UpdateGuildPrefix(ctx context.Context, guildID, prefix string) error {
+	_, err := db.pool.ExecContext(ctx, `
 		UPDATE guilds SET prefix = ? WHERE id = ?`, prefix, guildID)
 	return err
 }
 
-func (db *Database) UpdateGuildDisabledCommands(guildID string, disabled []string) error {
+// UpdateGuildPrefixes replaces every prefix of a guild. The first one becomes
+// the main prefix.
+func (db *Database) UpdateGuildPrefixes(ctx context.Context, guildID string, prefixes []string) error {
+	if len(prefixes) == 0 {
+		return errors.New("a guild needs at least one prefix")
+	}
+	extraJSON, err := json.Marshal(prefixes[1:])
+	if err != nil {
+		return err
+	}
+	_, err = db.pool.ExecContext(ctx, `
+		UPDATE guilds SET prefix = ?, extra_prefixes = ? WHERE id = ?`, prefixes[0], extraJSON, guildID)
+	return err
+}
+
+func (db *Database) UpdateGuildDisabledCommands(ctx context.Context, guildID string, disabled []string) error {
 	disabledJSON, err := json.Marshal(disabled)
 	if err != nil {
 		return err
 	}
-	_, err = db.pool.Exec(`
+	_, err = db.pool.ExecContext(ctx, `
 		UPDATE guilds SET disabled_commands = ? WHERE id = ?`, disabledJSON, guildID)
 	return err
 }
 
-func (db *Database) UpdateGuildPremium(guildID string, premium bool) error {
-	_, err := db.pool.Exec(`
+func (db *Database) UpdateGuildDisabledCategories(ctx context.Context, guildID string, disabled []string) error {
+	disabledJSON, err := json.Marshal(disabled)
+	if err != nil {
+		return err
+	}
+	_, err = db.pool.ExecContext(ctx, `
+		UPDATE guilds SET disabled_categories = ? WHERE id = ?`, disabledJSON, guildID)
+	return err
+}
+
+func (db *Database) UpdateGuildPremium(ctx context.Context, guildID string, premium bool) error {
+	_, err := db.pool.ExecContext(ctx, `
 		UPDATE guilds SET premium = ? WHERE id = ?`, premium, guildID)
 	return err
 }
 
-func (db *Database) DeleteGuild(guildID string) error {
-	_, err := db.pool.Exec(`DELETE FROM guilds WHERE id = ?`, guildID)
+func (db *Database) UpdateGuildSuggestions(ctx context.Context, guildID string, enabled bool) error {
+	_, err := db.pool.ExecContext(ctx, `
+		UPDATE guilds SET suggestions = ? WHERE id = ?`, enabled, guildID)
+	return err
+}
+
+func (db *Database) UpdateGuildAliases(ctx context.Context, guildID string, aliases map[string]string) error {
+	aliasesJSON, err := json.Marshal(aliases)
+	if err != nil {
+		return err
+	}
+	_, err = db.pool.ExecContext(ctx, `
+		UPDATE guilds SET aliases = ? WHERE id = ?`, aliasesJSON, guildID)
+	return err
+}
+
+func (db *Database) UpdateGuildLocale(ctx context.Context, guildID, locale string) error {
+	_, err := db.pool.ExecContext(ctx, `
+		UPDATE guilds SET locale = ? WHERE id = ?`, locale, guildID)
 	return err
 }
 
-func (db *Database) GetOrCreateGuild(guildID, defaultPrefix string) (*GuildConfig, error) {
-	cfg, err := db.GetGuild(guildID)
+func (db *Database) DeleteGuild(ctx context.Context, guildID string) error {
+	_, err := db.pool.ExecContext(ctx, `DELETE FROM guilds WHERE id = ?`, guildID)
+	return err
+}
+
+func (db *Database) GetOrCreateGuild(ctx context.Context, guildID, defaultPrefix string) (*GuildConfig, error) {
+	cfg, err := db.GetGuild(ctx, guildID)
 	if err != nil {
 		return nil, err
 	}
 	if cfg != nil {
 		return cfg, nil
 	}
-	return db.CreateGuild(guildID, defaultPrefix)
+	return db.CreateGuild(ctx, guildID, defaultPrefix)
 }
 
-func (db *Database) DisableCommands(guildID string, commands []string) error {
-	cfg, err := db.GetGuild(guildID)
+func (db *Database) DisableCommands(ctx context.Context, guildID string, commands []string) error {
+	cfg, err := db.GetGuild(ctx, guildID)
 	if err != nil {
 		return err
 	}
@@ -126,11 +204,11 @@ func (db *Database) DisableCommands(guildID string, commands []string) error {
 		}
 	}
 
-	return db.UpdateGuildDisabledCommands(guildID, cfg.DisabledCommands)
+	return db.UpdateGuildDisabledCommands(ctx, guildID, cfg.DisabledCommands)
 }
 
-func (db *Database) EnableCommands(guildID string, commands []string) error {
-	cfg, err := db.GetGuild(guildID)
+func (db *Database) EnableCommands(ctx context.Context, guildID string, commands []string) error {
+	cfg, err := db.GetGuild(ctx, guildID)
 	if err != nil {
 		return err
 	}
@@ -153,5 +231,40 @@ func (db *Database) EnableCommands(guildID string, commands []string) error {
 		}
 	}
 
-	return db.UpdateGuildDisabledCommands(guildID, newDisabled)
+	return db.UpdateGuildDisabledCommands(ctx, guildID, newDisabled)
+}
+
+// DisableCategories adds normalized categories to a guild's disabled ones
+func (db *Database) DisableCategories(ctx context.Context, guildID string, categories []string) error {
+	cfg, err := db.GetGuild(ctx, guildID)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		return nil
+	}
+
+	disabled := cfg.DisabledCategories
+	for _, category := range categories {
+		if !slices.Contains(disabled, category) {
+			disabled = append(disabled, category)
+		}
+	}
+	return db.UpdateGuildDisabledCategories(ctx, guildID, disabled)
+}
+
+// EnableCategories removes categories from a guild's disabled ones
+func (db *Database) EnableCategories(ctx context.Context, guildID string, categories []string) error {
+	cfg, err := db.GetGuild(ctx, guildID)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		return nil
+	}
+
+	disabled := slices.DeleteFunc(cfg.DisabledCategories, func(category string) bool {
+		return slices.Contains(categories, category)
+	})
+	return db.UpdateGuildDisabledCategories(ctx, guildID, disabled)
 }
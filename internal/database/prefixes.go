@@ -0,0 +1,63 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	userPrefixTTL     = 10 * time.Minute // How long a personal prefix is cached
+	maxCachedPrefixes = 100000           // Entries kept before expired ones are swept
+)
+
+// GetUserPrefix returns a user's personal prefix, or "" if they have none
+func (db *Database) GetUserPrefix(ctx context.Context, userID string) (string, error) {
+	if prefix, ok := db.prefixes.get(userID); ok {
+		return prefix, nil
+	}
+
+	var prefix string
+	err := db.pool.QueryRowContext(ctx, `
+		SELECT prefix FROM user_prefixes WHERE user_id = ?`, userID).Scan(&prefix)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+
+	db.prefixes.set(userID, prefix)
+	return prefix, nil
+}
+
+// SetUserPrefix sets a user's personal prefix, removing it if prefix is ""
+func (db *Database) SetUserPrefix(ctx context.Context, userID, prefix string) error {
+	var err error
+	if prefix == "" {
+		_, err = db.pool.ExecContext(ctx, `DELETE FROM user_prefixes WHERE user_id = ?`, userID)
+	} else {
+		_, err = db.pool.ExecContext(ctx, `
+			INSERT INTO user_prefixes (user_id, prefix) VALUES (?, ?)
+			ON DUPLICATE KEY UPDATE prefix = VALUES(prefix)`, userID, prefix)
+	}
+	if err != nil {
+		return err
+	}
+
+	db.prefixes.set(userID, prefix)
+	return nil
+}
@@ -0,0 +1,127 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// RuleScope is what a command rule applies to
+type RuleScope string
+
+const (
+	RuleChannel RuleScope = "channel"
+	RuleRole    RuleScope = "role"
+	RuleUser    RuleScope = "user"
+)
+
+// RuleAction is whether a command rule allows or denies the command
+type RuleAction string
+
+const (
+	RuleAllow RuleAction = "allow"
+	RuleDeny  RuleAction = "deny"
+)
+
+// CommandRule allows or denies a command, or a whole category, in a channel
+// or for a role or user
+type CommandRule struct {
+	ID        int64
+	GuildID   string
+	Command   string // Primary trigger, empty for category rules
+	Category  string // Normalized category, empty for command rules
+	Scope     RuleScope
+	ScopeID   string // Channel, role or user ID
+	Action    RuleAction
+	AuthorID  string // User who last changed the rule
+	UpdatedAt time.Time
+}
+
+const (
+	commandRulesTTL     = 5 * time.Minute // How long a guild's rules are cached
+	maxCachedRuleGuilds = 50000           // Entries kept before expired ones are swept
+)
+
+// GetCommandRules returns a guild's command rules, oldest first. The slice is
+// shared and must not be modified.
+func (db *Database) GetCommandRules(ctx context.Context, guildID string) ([]CommandRule, error) {
+	if rules, ok := db.rules.get(guildID); ok {
+		return rules, nil
+	}
+
+	rows, err := db.pool.QueryContext(ctx, `
+		SELECT id, guild_id, command, category, scope, scope_id, action, author_id, updated_at
+		FROM command_rules WHERE guild_id = ? ORDER BY id`, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []CommandRule
+	for rows.Next() {
+		var r CommandRule
+		if err := rows.Scan(&r.ID, &r.GuildID, &r.Command, &r.Category, &r.Scope, &r.ScopeID, &r.Action, &r.AuthorID, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	db.rules.set(guildID, rules)
+	return rules, nil
+}
+
+// SetCommandRule adds a rule, replacing the action of an existing rule for
+// the same command or category and scope
+func (db *Database) SetCommandRule(ctx context.Context, rule CommandRule) error {
+	_, err := db.pool.ExecContext(ctx, `
+		INSERT INTO command_rules (guild_id, command, category, scope, scope_id, action, author_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE action = VALUES(action), author_id = VALUES(author_id)`,
+		rule.GuildID, rule.Command, rule.Category, rule.Scope, rule.ScopeID, rule.Action, rule.AuthorID)
+	if err != nil {
+		return err
+	}
+	db.rules.forget(rule.GuildID)
+	return nil
+}
+
+// DeleteCommandRule deletes a rule by ID, reporting whether it existed
+func (db *Database) DeleteCommandRule(ctx context.Context, guildID string, id int64) (bool, error) {
+	res, err := db.pool.ExecContext(ctx, `
+		DELETE FROM command_rules WHERE guild_id = ? AND id = ?`, guildID, id)
+	if err != nil {
+		return false, err
+	}
+	db.rules.forget(guildID)
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ClearCommandRules deletes all of a guild's rules, returning how many there were
+func (db *Database) ClearCommandRules(ctx context.Context, guildID string) (int64, error) {
+	res, err := db.pool.ExecContext(ctx, `
+		DELETE FROM command_rules WHERE guild_id = ?`, guildID)
+	if err != nil {
+		return 0, err
+	}
+	db.rules.forget(guildID)
+	return res.RowsAffected()
+}
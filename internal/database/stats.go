@@ -16,6 +16,8 @@
 
 package database
 
+import "context"
+
 type BotStats struct {
 	Guilds   int
 	Users    int
@@ -23,9 +25,9 @@ type BotStats struct {
 	Shards   int
 }
 
-func (db *Database) GetStats() (*BotStats, error) {
+func (db *Database) GetStats(ctx context.Context) (*BotStats, error) {
 	var stats BotStats
-	err := db.pool.QueryRow(`
+	err := db.pool.QueryRowContext(ctx, `
 		SELECT guilds, users, channels, shards FROM stats WHERE id = 1`).
 		Scan(&stats.Guilds, &stats.Users, &stats.Channels, &stats.Shards)
 	if err != nil {
@@ -34,8 +36,8 @@ func (db *Database) GetStats() (*BotStats, error) {
 	return &stats, nil
 }
 
-func (db *Database) UpdateStats(stats *BotStats) error {
-	_, err := db.pool.Exec(`
+func (db *Database) UpdateStats(ctx context.Context, stats *BotStats) error {
+	_, err := db.pool.ExecContext(ctx, `
 		INSERT INTO stats (id, guilds, users, channels, shards) VALUES (1, ?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE guilds = ?, users = ?, channels = ?, shards = ?`,
 		stats.Guilds, stats.Users, stats.Channels, stats.Shards,
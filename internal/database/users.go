@@ -17,15 +17,16 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 )
 
-func (db *Database) GetCoins(userID string) (int64, error) {
+func (db *Database) GetCoins(ctx context.Context, userID string) (int64, error) {
 	var coins int64
-	err := db.pool.QueryRow(`SELECT coins FROM users WHERE id = ?`, userID).Scan(&coins)
+	err := db.pool.QueryRowContext(ctx, `SELECT coins FROM users WHERE id = ?`, userID).Scan(&coins)
 	if err == sql.ErrNoRows {
 		// Create user with 0 coins
-		_, err = db.pool.Exec(`INSERT INTO users (id, coins) VALUES (?, 0)`, userID)
+		_, err = db.pool.ExecContext(ctx, `INSERT INTO users (id, coins) VALUES (?, 0)`, userID)
 		if err != nil {
 			return 0, err
 		}
@@ -37,21 +38,21 @@ func (db *Database) GetCoins(userID string) (int64, error) {
 	return coins, nil
 }
 
-func (db *Database) AddCoins(userID string, amount int64) error {
-	_, err := db.pool.Exec(`
+func (db *Database) AddCoins(ctx context.Context, userID string, amount int64) error {
+	_, err := db.pool.ExecContext(ctx, `
 		INSERT INTO users (id, coins) VALUES (?, ?)
 		ON DUPLICATE KEY UPDATE coins = coins + ?`, userID, amount, amount)
 	return err
 }
 
-func (db *Database) RemoveCoins(userID string, amount int64) error {
-	_, err := db.pool.Exec(`
+func (db *Database) RemoveCoins(ctx context.Context, userID string, amount int64) error {
+	_, err := db.pool.ExecContext(ctx, `
 		UPDATE users SET coins = GREATEST(0, CAST(coins AS SIGNED) - ?) WHERE id = ?`, amount, userID)
 	return err
 }
 
-func (db *Database) SetCoins(userID string, amount int64) error {
-	_, err := db.pool.Exec(`
+func (db *Database) SetCoins(ctx context.Context, userID string, amount int64) error {
+	_, err := db.pool.ExecContext(ctx, `
 		INSERT INTO users (id, coins) VALUES (?, ?)
 		ON DUPLICATE KEY UPDATE coins = ?`, userID, amount, amount)
 	return err
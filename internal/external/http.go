@@ -17,6 +17,7 @@
 package external
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -24,13 +25,14 @@ import (
 	"time"
 )
 
+// Requests are bounded by their context, the client timeout is a last resort
 var httpClient = &http.Client{
 	Timeout: 30 * time.Second,
 }
 
 // Get performs a GET request and returns the response body
-func Get(url string, headers map[string]string) ([]byte, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func Get(ctx context.Context, url string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -53,8 +55,8 @@ func Get(url string, headers map[string]string) ([]byte, error) {
 }
 
 // GetJSON performs a GET request and unmarshals JSON response
-func GetJSON(url string, headers map[string]string, result interface{}) error {
-	body, err := Get(url, headers)
+func GetJSON(ctx context.Context, url string, headers map[string]string, result interface{}) error {
+	body, err := Get(ctx, url, headers)
 	if err != nil {
 		return err
 	}
@@ -62,8 +64,8 @@ func GetJSON(url string, headers map[string]string, result interface{}) error {
 }
 
 // GetWithAuth performs an authenticated GET request
-func GetWithAuth(url, token string) ([]byte, error) {
-	return Get(url, map[string]string{
+func GetWithAuth(ctx context.Context, url, token string) ([]byte, error) {
+	return Get(ctx, url, map[string]string{
 		"Authorization": token,
 	})
 }
@@ -17,6 +17,7 @@
 package external
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
@@ -38,7 +39,7 @@ func NewImageGenClient(baseURL, apiKey string) *ImageGenClient {
 // Generate makes a request to the image generation API
 // endpoint should be like "/magik" or "/ban"
 // data is the URL or text to process
-func (c *ImageGenClient) Generate(endpoint, data string) ([]byte, error) {
+func (c *ImageGenClient) Generate(ctx context.Context, endpoint, data string) ([]byte, error) {
 	// Build URL with query parameter
 	u := fmt.Sprintf("%s%s?avatar1=%s", c.baseURL, endpoint, url.QueryEscape(data))
 
@@ -47,11 +48,11 @@ func (c *ImageGenClient) Generate(endpoint, data string) ([]byte, error) {
 		headers["Authorization"] = c.apiKey
 	}
 
-	return Get(u, headers)
+	return Get(ctx, u, headers)
 }
 
 // GenerateWithText makes a request with text data
-func (c *ImageGenClient) GenerateWithText(endpoint, text string) ([]byte, error) {
+func (c *ImageGenClient) GenerateWithText(ctx context.Context, endpoint, text string) ([]byte, error) {
 	u := fmt.Sprintf("%s%s?text=%s", c.baseURL, endpoint, url.QueryEscape(text))
 
 	headers := map[string]string{}
@@ -59,11 +60,11 @@ func (c *ImageGenClient) GenerateWithText(endpoint, text string) ([]byte, error)
 		headers["Authorization"] = c.apiKey
 	}
 
-	return Get(u, headers)
+	return Get(ctx, u, headers)
 }
 
 // GenerateDouble makes a request with two avatar URLs
-func (c *ImageGenClient) GenerateDouble(endpoint, avatar1, avatar2 string) ([]byte, error) {
+func (c *ImageGenClient) GenerateDouble(ctx context.Context, endpoint, avatar1, avatar2 string) ([]byte, error) {
 	u := fmt.Sprintf("%s%s?avatar1=%s&avatar2=%s",
 		c.baseURL, endpoint,
 		url.QueryEscape(avatar1),
@@ -74,11 +75,11 @@ func (c *ImageGenClient) GenerateDouble(endpoint, avatar1, avatar2 string) ([]by
 		headers["Authorization"] = c.apiKey
 	}
 
-	return Get(u, headers)
+	return Get(ctx, u, headers)
 }
 
 // GenerateCustom makes a request with custom query parameters
-func (c *ImageGenClient) GenerateCustom(endpoint string, params map[string]string) ([]byte, error) {
+func (c *ImageGenClient) GenerateCustom(ctx context.Context, endpoint string, params map[string]string) ([]byte, error) {
 	u := fmt.Sprintf("%s%s?", c.baseURL, endpoint)
 
 	query := url.Values{}
@@ -92,5 +93,5 @@ func (c *ImageGenClient) GenerateCustom(endpoint string, params map[string]strin
 		headers["Authorization"] = c.apiKey
 	}
 
-	return Get(u, headers)
+	return Get(ctx, u, headers)
 }
@@ -17,6 +17,7 @@
 package external
 
 import (
+	"context"
 	"fmt"
 	"strings"
 )
@@ -60,7 +61,7 @@ type RedditListing struct {
 
 // FetchPosts fetches posts from a Reddit endpoint
 // endpoint should be like "/r/memes/top.json" or "/u/kerdaloo/m/dankmemer/top/.json"
-func (c *RedditClient) FetchPosts(endpoint string) ([]RedditPost, error) {
+func (c *RedditClient) FetchPosts(ctx context.Context, endpoint string) ([]RedditPost, error) {
 	url := c.baseURL + endpoint
 
 	// Add default params if not present
@@ -69,7 +70,7 @@ func (c *RedditClient) FetchPosts(endpoint string) ([]RedditPost, error) {
 	}
 
 	var listing RedditListing
-	err := GetJSON(url, map[string]string{
+	err := GetJSON(ctx, url, map[string]string{
 		"User-Agent": "DankMemer/1.0",
 	}, &listing)
 	if err != nil {
@@ -85,8 +86,8 @@ func (c *RedditClient) FetchPosts(endpoint string) ([]RedditPost, error) {
 }
 
 // FetchImagePosts fetches only image posts from a Reddit endpoint
-func (c *RedditClient) FetchImagePosts(endpoint string) ([]RedditPost, error) {
-	posts, err := c.FetchPosts(endpoint)
+func (c *RedditClient) FetchImagePosts(ctx context.Context, endpoint string) ([]RedditPost, error) {
+	posts, err := c.FetchPosts(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
@@ -102,8 +103,8 @@ func (c *RedditClient) FetchImagePosts(endpoint string) ([]RedditPost, error) {
 }
 
 // FetchTextPosts fetches only text/self posts from a Reddit endpoint
-func (c *RedditClient) FetchTextPosts(endpoint string) ([]RedditPost, error) {
-	posts, err := c.FetchPosts(endpoint)
+func (c *RedditClient) FetchTextPosts(ctx context.Context, endpoint string) ([]RedditPost, error) {
+	posts, err := c.FetchPosts(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
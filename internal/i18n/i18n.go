@@ -0,0 +1,200 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package i18n holds the bot's message catalogs. Every catalog is a JSON file
+// in locales/ mapping keys to messages in fmt syntax; plural messages map
+// plural categories ("one", "few", "many", "other") to messages instead.
+// Keys missing from a locale fall back to its base language, then English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Default is the locale every key must exist in
+const Default = "en"
+
+//go:embed locales/*.json
+var files embed.FS
+
+// message is a catalog entry, either plain text or plural forms
+type message struct {
+	text  string
+	forms map[string]string
+}
+
+func (m *message) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &m.text); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &m.forms)
+}
+
+var catalogs = load()
+
+// load reads the embedded catalogs. They're part of the binary, so a broken
+// one is a programming error.
+func load() map[string]map[string]message {
+	entries, err := files.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	loaded := make(map[string]map[string]message, len(entries))
+	for _, entry := range entries {
+		data, err := files.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var catalog map[string]message
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", entry.Name(), err))
+		}
+		loaded[strings.ToLower(strings.TrimSuffix(entry.Name(), ".json"))] = catalog
+	}
+	return loaded
+}
+
+// Locales returns the available locales, sorted
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Supported reports whether there is a catalog for a locale
+func Supported(locale string) bool {
+	_, ok := catalogs[Normalize(locale)]
+	return ok
+}
+
+// Normalize lowercases a locale and uses dashes, e.g. "pt_BR" becomes "pt-br"
+func Normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
+
+// chain returns the locales to look a key up in, most specific first
+func chain(locale string) []string {
+	locale = Normalize(locale)
+	locales := []string{locale}
+	if base, _, ok := strings.Cut(locale, "-"); ok {
+		locales = append(locales, base)
+	}
+	return append(locales, Default)
+}
+
+// lookup finds a message and the locale it was found in
+func lookup(locale, key string) (message, string, bool) {
+	for _, l := range chain(locale) {
+		if msg, ok := catalogs[l][key]; ok {
+			return msg, l, true
+		}
+	}
+	return message{}, "", false
+}
+
+// Lookup returns the text of a message without formatting it
+func Lookup(locale, key string) (string, bool) {
+	msg, _, ok := lookup(locale, key)
+	if !ok || msg.forms != nil {
+		return "", false
+	}
+	return msg.text, true
+}
+
+// T returns a message formatted with args. Missing keys are returned as is,
+// so they're easy to spot.
+func T(locale, key string, args ...interface{}) string {
+	msg, _, ok := lookup(locale, key)
+	if !ok {
+		return key
+	}
+	text := msg.text
+	if msg.forms != nil {
+		text = msg.forms["other"]
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Plural returns the form of a plural message for n, formatted with n
+// followed by args
+func Plural(locale, key string, n int64, args ...interface{}) string {
+	msg, found, ok := lookup(locale, key)
+	if !ok {
+		return key
+	}
+	args = append([]interface{}{n}, args...)
+	if msg.forms == nil {
+		return fmt.Sprintf(msg.text, args...)
+	}
+
+	form, ok := msg.forms[pluralCategory(found, n)]
+	if !ok {
+		form = msg.forms["other"]
+	}
+	return fmt.Sprintf(form, args...)
+}
+
+// pluralCategory returns the CLDR plural category of n in a language, for the
+// languages we have catalogs for or are likely to
+func pluralCategory(locale string, n int64) string {
+	if n < 0 {
+		n = -n
+	}
+	lang, _, _ := strings.Cut(locale, "-")
+
+	switch lang {
+	case "ja", "ko", "zh", "th", "vi", "id":
+		return "other"
+	case "fr", "pt":
+		if n == 0 || n == 1 {
+			return "one"
+		}
+		return "other"
+	case "ru", "uk":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		}
+		return "many"
+	case "pl":
+		switch {
+		case n == 1:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		}
+		return "many"
+	}
+
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
@@ -0,0 +1,99 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		locale string
+		n      int64
+		want   string
+	}{
+		{"en", 0, "other"},
+		{"en", 1, "one"},
+		{"en", -1, "one"},
+		{"en", 2, "other"},
+		{"de", 1, "one"},
+		{"fr", 0, "one"},
+		{"fr", 2, "other"},
+		{"pt-BR", 1, "one"},
+		{"ja", 1, "other"},
+		{"ru", 1, "one"},
+		{"ru", 21, "one"},
+		{"ru", 11, "many"},
+		{"ru", 3, "few"},
+		{"ru", 13, "many"},
+		{"ru", 24, "few"},
+		{"ru", 5, "many"},
+		{"pl", 1, "one"},
+		{"pl", 21, "many"},
+		{"pl", 22, "few"},
+		{"pl", 12, "many"},
+	}
+
+	for _, tt := range tests {
+		if got := pluralCategory(tt.locale, tt.n); got != tt.want {
+			t.Errorf("pluralCategory(%q, %d) = %q, want %q", tt.locale, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		locale string
+		n      int64
+		want   string
+	}{
+		{"en", 1, "1 command"},
+		{"en", 5, "5 commands"},
+		{"en-GB", 1, "1 command"},
+		{"xx", 2, "2 commands"}, // Unknown locales fall back to English
+	}
+
+	for _, tt := range tests {
+		if got := Plural(tt.locale, "help.count", tt.n); got != tt.want {
+			t.Errorf("Plural(%q, %d) = %q, want %q", tt.locale, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T("en", "errors.generic", "boom"); got != "Something went wrong: `boom`" {
+		t.Errorf("got %q", got)
+	}
+	if got := T("en", "no.such.key"); got != "no.such.key" {
+		t.Errorf("missing key gave %q, want the key", got)
+	}
+	if got := T("en", "help.count"); got != "%d commands" {
+		t.Errorf("plural message gave %q, want its other form", got)
+	}
+}
+
+// Command texts are the exception, their English is in the command's props
+func TestCatalogsOnlyHaveEnglishKeys(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for key := range catalog {
+			if _, ok := catalogs[Default][key]; !ok && !strings.HasPrefix(key, "commands.") {
+				t.Errorf("%s has %s, which English doesn't", locale, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package plugin
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dankmemer/bot/internal/commands"
+)
+
+// DefaultCategory is the help category of plugin commands that don't set one
+const DefaultCategory = "Plugins"
+
+// argTypes maps the argument types of the protocol to schema types
+var argTypes = map[string]commands.ArgType{
+	"user":     commands.ArgUser,
+	"member":   commands.ArgMember,
+	"channel":  commands.ArgChannel,
+	"integer":  commands.ArgInteger,
+	"duration": commands.ArgDuration,
+	"choice":   commands.ArgChoice,
+	"text":     commands.ArgText,
+	"word":     commands.ArgWord,
+	"flag":     commands.ArgFlag,
+}
+
+// Command is a command provided by a plugin. It runs through the same
+// middleware as native commands, so cooldowns, permissions and disabling
+// work as usual.
+type Command struct {
+	Spec   CommandSpec
+	Plugin *Supervisor
+}
+
+// NewCommands creates the commands a started plugin declared
+func NewCommands(s *Supervisor) []commands.Command {
+	specs := s.Commands()
+	cmds := make([]commands.Command, 0, len(specs))
+	for _, spec := range specs {
+		cmds = append(cmds, &Command{Spec: spec, Plugin: s})
+	}
+	return cmds
+}
+
+func (c *Command) Props() commands.CommandProps {
+	spec := c.Spec
+
+	props := commands.CommandProps{
+		Triggers:        spec.Triggers,
+		Description:     spec.Description,
+		Usage:           spec.Usage,
+		Category:        spec.Category,
+		Cooldown:        spec.Cooldown,
+		CooldownMessage: spec.CooldownMessage,
+		Timeout:         spec.Timeout,
+		Typing:          spec.Typing,
+		Permissions:     spec.Permissions,
+		UserPermissions: spec.UserPermissions,
+		IsNSFW:          spec.NSFW,
+		OwnerOnly:       spec.OwnerOnly,
+	}
+	if props.Category == "" {
+		props.Category = DefaultCategory
+	}
+	if props.Cooldown == 0 {
+		props.Cooldown = 3000
+	}
+
+	for _, arg := range spec.Args {
+		props.Args = append(props.Args, commands.Arg{
+			Name:        arg.Name,
+			Type:        argTypes[arg.Type],
+			Description: arg.Description,
+			Optional:    arg.Optional,
+			Min:         arg.Min,
+			Max:         arg.Max,
+			Choices:     arg.Choices,
+			Missing:     arg.Missing,
+			Invalid:     arg.Invalid,
+		})
+	}
+	props.Usage = commands.Usage(props)
+
+	return props
+}
+
+func (c *Command) Run(ctx *commands.CommandContext) (*commands.CommandResponse, error) {
+	resp, err := c.Plugin.Invoke(ctx.Context, c.invocation(ctx))
+	if errors.Is(err, ErrUnavailable) {
+		return &commands.CommandResponse{Content: ctx.T("plugins.unavailable")}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, nil
+	}
+
+	out := &commands.CommandResponse{
+		Content: resp.Content,
+		Embed:   resp.Embed,
+		Reply:   resp.Reply,
+	}
+	for _, file := range resp.Files {
+		out.Files = append(out.Files, &discordgo.File{
+			Name:        file.Name,
+			ContentType: file.ContentType,
+			Reader:      bytes.NewReader(file.Data),
+		})
+	}
+	return out, nil
+}
+
+// invocation serializes the parts of the context plugins get to see
+func (c *Command) invocation(ctx *commands.CommandContext) *Invocation {
+	m := ctx.Message
+
+	inv := &Invocation{
+		Command:   c.Spec.Triggers[0],
+		Args:      ctx.Args,
+		CleanArgs: ctx.CleanArgs,
+		Flags:     ctx.Flags,
+		Content:   ctx.Content,
+		Locale:    ctx.Locale(),
+		GuildID:   m.GuildID,
+		ChannelID: m.ChannelID,
+		MessageID: m.ID,
+		Author:    newUser(m.Author),
+		Slash:     ctx.Interaction != nil,
+	}
+	if ctx.GuildConfig != nil {
+		inv.Prefix = ctx.GuildConfig.Prefix
+	}
+	for _, u := range m.Mentions {
+		inv.Mentions = append(inv.Mentions, newUser(u))
+	}
+
+	if len(c.Spec.Args) > 0 {
+		inv.Values = make(map[string]interface{}, len(c.Spec.Args))
+	}
+	for _, arg := range c.Spec.Args {
+		if !ctx.HasArg(arg.Name) {
+			continue
+		}
+		switch argTypes[arg.Type] {
+		case commands.ArgUser:
+			inv.Values[arg.Name] = newUser(ctx.ArgUser(arg.Name))
+		case commands.ArgMember:
+			inv.Values[arg.Name] = newUser(ctx.ArgMember(arg.Name).User)
+		case commands.ArgChannel:
+			inv.Values[arg.Name] = ctx.ArgChannel(arg.Name).ID
+		case commands.ArgInteger:
+			inv.Values[arg.Name] = ctx.ArgInt(arg.Name, 0)
+		case commands.ArgDuration:
+			inv.Values[arg.Name] = ctx.ArgDuration(arg.Name, 0).Milliseconds()
+		default:
+			inv.Values[arg.Name] = ctx.ArgString(arg.Name)
+		}
+	}
+
+	return inv
+}
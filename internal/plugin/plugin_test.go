@@ -0,0 +1,210 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dankmemer/bot/internal/commands"
+	"github.com/dankmemer/bot/internal/commands/commandtest"
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// helperEnv makes the test binary act as a plugin, see runHelper
+const helperEnv = "DANK_PLUGIN_TEST_HELPER"
+
+var helperSpecs = []CommandSpec{
+	{
+		Triggers:    []string{"poke", "prod"},
+		Description: "Poke someone",
+		Args: []ArgSpec{
+			{Name: "user", Type: "member"},
+			{Name: "times", Type: "integer", Min: 1, Max: 10},
+			{Name: "hard", Type: "flag"},
+		},
+	},
+	{Triggers: []string{"crash"}, Description: "Exit without replying"},
+}
+
+func TestMain(m *testing.M) {
+	if os.Getenv(helperEnv) == "1" {
+		runHelper()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// runHelper is a plugin that declares helperSpecs and answers invocations
+// with the invocation it received
+func runHelper() {
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64*1024), maxReplySize)
+	out := json.NewEncoder(os.Stdout)
+
+	for scanner.Scan() {
+		var req Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			os.Exit(2)
+		}
+		switch req.Type {
+		case RequestDescribe:
+			out.Encode(Reply{ID: req.ID, Commands: helperSpecs})
+		case RequestInvoke:
+			if req.Invocation.Command == "crash" {
+				os.Exit(1)
+			}
+			data, _ := json.Marshal(req.Invocation)
+			out.Encode(Reply{ID: req.ID, Response: &Response{Content: string(data)}})
+		}
+	}
+}
+
+// startHelper starts the test binary as a plugin
+func startHelper(t *testing.T) *Supervisor {
+	t.Helper()
+	s := NewSupervisor(utils.PluginConfig{
+		Name: "helper",
+		Path: os.Args[0],
+		Env:  []string{helperEnv + "=1"},
+	}, zerolog.Nop())
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Stop)
+	return s
+}
+
+func TestPropsDefaults(t *testing.T) {
+	cmd := &Command{Spec: helperSpecs[0]}
+	props := cmd.Props()
+
+	if props.Category != DefaultCategory {
+		t.Errorf("category = %q, want %q", props.Category, DefaultCategory)
+	}
+	if props.Cooldown != 3000 {
+		t.Errorf("cooldown = %d, want 3000", props.Cooldown)
+	}
+	if want := "{command} <@user> <times> [--hard]"; props.Usage != want {
+		t.Errorf("usage = %q, want %q", props.Usage, want)
+	}
+
+	types := []commands.ArgType{commands.ArgMember, commands.ArgInteger, commands.ArgFlag}
+	if len(props.Args) != len(types) {
+		t.Fatalf("got %d args, want %d", len(props.Args), len(types))
+	}
+	for i, arg := range props.Args {
+		if arg.Type != types[i] {
+			t.Errorf("arg %s has type %v, want %v", arg.Name, arg.Type, types[i])
+		}
+	}
+
+	cmd.Spec.Category = "Games"
+	cmd.Spec.Cooldown = 500
+	props = cmd.Props()
+	if props.Category != "Games" || props.Cooldown != 500 {
+		t.Errorf("got category %q and cooldown %d, want the declared ones", props.Category, props.Cooldown)
+	}
+}
+
+func TestValidateSpecs(t *testing.T) {
+	tests := []struct {
+		name  string
+		specs []CommandSpec
+		ok    bool
+	}{
+		{"valid", helperSpecs, true},
+		{"none", nil, false},
+		{"no triggers", []CommandSpec{{Description: "nameless"}}, false},
+		{"unknown arg type", []CommandSpec{{
+			Triggers: []string{"roll"},
+			Args:     []ArgSpec{{Name: "sides", Type: "dice"}},
+		}}, false},
+	}
+	for _, tt := range tests {
+		err := validateSpecs(tt.specs)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: got error %v, want ok = %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	s := startHelper(t)
+
+	cmds := NewCommands(s)
+	if len(cmds) != len(helperSpecs) {
+		t.Fatalf("got %d commands, want %d", len(cmds), len(helperSpecs))
+	}
+
+	h := commandtest.New()
+	target := h.AddUser("target")
+	resp, err := h.Run(cmds[0], fmt.Sprintf("prod <@%s> 3 --hard", target.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var inv Invocation
+	if err := json.Unmarshal([]byte(resp.Content), &inv); err != nil {
+		t.Fatalf("plugin reply %q: %v", resp.Content, err)
+	}
+	if inv.Command != "poke" {
+		t.Errorf("command = %q, want the primary trigger", inv.Command)
+	}
+	if _, ok := inv.Flags["hard"]; !ok {
+		t.Errorf("flags = %v, want hard", inv.Flags)
+	}
+	if inv.Author.ID != commandtest.AuthorID {
+		t.Errorf("author = %q, want %q", inv.Author.ID, commandtest.AuthorID)
+	}
+	if user, _ := inv.Values["user"].(map[string]interface{}); user["id"] != target.ID {
+		t.Errorf("user value = %v, want %s", inv.Values["user"], target.ID)
+	}
+	if inv.Values["times"] != float64(3) {
+		t.Errorf("times value = %v, want 3", inv.Values["times"])
+	}
+}
+
+func TestInvokeAfterExit(t *testing.T) {
+	s := startHelper(t)
+	cmds := NewCommands(s)
+
+	h := commandtest.New()
+	resp, err := h.Run(cmds[1], "crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Content, "unavailable") {
+		t.Errorf("got %q, want the plugin reported unavailable", resp.Content)
+	}
+
+	// The plugin is restarting now
+	resp, err = h.Run(cmds[0], fmt.Sprintf("poke <@%s> 1", commandtest.AuthorID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Content, "unavailable") {
+		t.Errorf("got %q, want the plugin reported unavailable", resp.Content)
+	}
+}
@@ -0,0 +1,219 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+// maxReplySize is the longest line a plugin may write, large enough for a
+// few base64 encoded attachments
+const maxReplySize = 32 << 20
+
+// errExited is returned for requests to a plugin that stopped, and is the
+// exit error of plugins that exited cleanly
+var errExited = errors.New("plugin exited")
+
+// process is a running plugin executable
+type process struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	logger zerolog.Logger
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[uint64]chan Reply
+	nextID  uint64
+
+	stderrDone chan struct{} // Closed once stderr is drained
+	exited     chan struct{} // Closed once the process exited
+	err        error         // Why it exited, set before exited is closed
+}
+
+// startProcess starts a plugin executable and reads its replies until it
+// exits
+func startProcess(cfg utils.PluginConfig, logger zerolog.Logger) (*process, error) {
+	cmd := exec.Command(cfg.Path, cfg.Args...)
+	cmd.Env = append(os.Environ(), cfg.Env...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &process{
+		cmd:        cmd,
+		stdin:      stdin,
+		logger:     logger,
+		pending:    make(map[uint64]chan Reply),
+		stderrDone: make(chan struct{}),
+		exited:     make(chan struct{}),
+	}
+
+	go p.logStderr(stderr)
+	go p.readReplies(stdout)
+
+	return p, nil
+}
+
+// readReplies hands replies to the requests waiting for them, then waits for
+// the process to exit
+func (p *process) readReplies(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxReplySize)
+
+	for scanner.Scan() {
+		var reply Reply
+		if err := json.Unmarshal(scanner.Bytes(), &reply); err != nil {
+			p.logger.Warn().Err(err).Msg("Plugin wrote invalid JSON")
+			continue
+		}
+
+		p.mu.Lock()
+		ch, ok := p.pending[reply.ID]
+		delete(p.pending, reply.ID)
+		p.mu.Unlock()
+
+		if !ok {
+			p.logger.Debug().Uint64("id", reply.ID).Msg("Plugin replied to an unknown request")
+			continue
+		}
+		ch <- reply
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		// Stop a plugin we can't read from anymore
+		p.cmd.Process.Kill()
+	}
+	// Wait closes the pipes, so let stderr finish first
+	<-p.stderrDone
+	if waitErr := p.cmd.Wait(); err == nil {
+		err = waitErr
+	}
+	if err == nil {
+		err = errExited
+	}
+
+	p.err = err
+	close(p.exited)
+}
+
+// logStderr logs everything the plugin writes to stderr
+func (p *process) logStderr(stderr io.Reader) {
+	defer close(p.stderrDone)
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		p.logger.Info().Msg(scanner.Text())
+	}
+	// Keep draining after an overlong line so the plugin doesn't block
+	io.Copy(io.Discard, stderr)
+}
+
+// send writes a request to the plugin
+func (p *process) send(req Request) error {
+	line, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	_, err = p.stdin.Write(line)
+	return err
+}
+
+// call sends a request and waits for its reply. The plugin is told to stop
+// working on it if ctx is cancelled first.
+func (p *process) call(ctx context.Context, req Request) (Reply, error) {
+	ch := make(chan Reply, 1)
+
+	p.mu.Lock()
+	p.nextID++
+	req.ID = p.nextID
+	p.pending[req.ID] = ch
+	p.mu.Unlock()
+
+	forget := func() {
+		p.mu.Lock()
+		delete(p.pending, req.ID)
+		p.mu.Unlock()
+	}
+
+	if err := p.send(req); err != nil {
+		forget()
+		select {
+		case <-p.exited:
+			return Reply{}, errExited
+		default:
+			return Reply{}, err
+		}
+	}
+
+	select {
+	case reply := <-ch:
+		if reply.Error != "" {
+			return reply, errors.New(reply.Error)
+		}
+		return reply, nil
+	case <-p.exited:
+		forget()
+		return Reply{}, errExited
+	case <-ctx.Done():
+		forget()
+		p.send(Request{ID: req.ID, Type: RequestCancel})
+		return Reply{}, ctx.Err()
+	}
+}
+
+// stop asks the plugin to exit by closing its stdin, and kills it if it
+// doesn't within the context
+func (p *process) stop(ctx context.Context) {
+	p.stdin.Close()
+
+	select {
+	case <-p.exited:
+	case <-ctx.Done():
+		p.cmd.Process.Kill()
+		<-p.exited
+	}
+}
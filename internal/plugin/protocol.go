@@ -0,0 +1,135 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package plugin runs commands provided by external executables. The bot
+// starts each plugin, asks it which commands it provides and forwards their
+// invocations to it as newline-delimited JSON over stdin and stdout.
+package plugin
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// ProtocolVersion is sent to plugins on start so they can refuse versions
+// they don't speak
+const ProtocolVersion = 1
+
+// Request types sent to plugins
+const (
+	RequestDescribe = "describe" // Reply with the commands the plugin provides
+	RequestInvoke   = "invoke"   // Run a command and reply with its response
+	RequestCancel   = "cancel"   // The invocation with this ID timed out, stop working on it
+)
+
+// Request is a line the bot writes to a plugin's stdin
+type Request struct {
+	ID         uint64      `json:"id"`
+	Type       string      `json:"type"`
+	Version    int         `json:"version,omitempty"`    // Set on describe
+	Invocation *Invocation `json:"invocation,omitempty"` // Set on invoke
+}
+
+// Reply is a line a plugin writes to stdout, answering the request with
+// the same ID
+type Reply struct {
+	ID       uint64        `json:"id"`
+	Commands []CommandSpec `json:"commands,omitempty"` // Answer to describe
+	Response *Response     `json:"response,omitempty"` // Answer to invoke, null for no reply
+	Error    string        `json:"error,omitempty"`    // The request failed
+}
+
+// CommandSpec declares a command provided by a plugin
+type CommandSpec struct {
+	Triggers        []string  `json:"triggers"`
+	Description     string    `json:"description"`
+	Usage           string    `json:"usage,omitempty"`
+	Category        string    `json:"category,omitempty"` // Default: "Plugins"
+	Cooldown        int64     `json:"cooldown,omitempty"` // Milliseconds
+	CooldownMessage string    `json:"cooldown_message,omitempty"`
+	Timeout         int64     `json:"timeout,omitempty"` // Milliseconds
+	Typing          bool      `json:"typing,omitempty"`
+	Permissions     []int64   `json:"permissions,omitempty"`
+	UserPermissions int64     `json:"user_permissions,omitempty"`
+	NSFW            bool      `json:"nsfw,omitempty"`
+	OwnerOnly       bool      `json:"owner_only,omitempty"`
+	Args            []ArgSpec `json:"args,omitempty"`
+}
+
+// ArgSpec declares a typed argument of a plugin command. Type is one of
+// user, member, channel, integer, duration, choice, text, word or flag.
+type ArgSpec struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Description string   `json:"description,omitempty"`
+	Optional    bool     `json:"optional,omitempty"`
+	Min         int64    `json:"min,omitempty"`
+	Max         int64    `json:"max,omitempty"`
+	Choices     []string `json:"choices,omitempty"`
+	Missing     string   `json:"missing,omitempty"`
+	Invalid     string   `json:"invalid,omitempty"`
+}
+
+// Invocation is the part of a command's context sent to plugins
+type Invocation struct {
+	Command   string                 `json:"command"` // Primary trigger of the command
+	Args      []string               `json:"args"`
+	CleanArgs []string               `json:"clean_args"`
+	Flags     map[string]string      `json:"flags,omitempty"`
+	Values    map[string]interface{} `json:"values,omitempty"` // Arguments parsed from the schema
+	Content   string                 `json:"content"`          // Message content after the prefix
+	Prefix    string                 `json:"prefix"`
+	Locale    string                 `json:"locale"`
+	GuildID   string                 `json:"guild_id"`
+	ChannelID string                 `json:"channel_id"`
+	MessageID string                 `json:"message_id"`
+	Author    User                   `json:"author"`
+	Mentions  []User                 `json:"mentions,omitempty"`
+	Slash     bool                   `json:"slash,omitempty"` // Invoked as a slash command
+}
+
+// User is a Discord user as seen by plugins
+type User struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name,omitempty"`
+	Avatar     string `json:"avatar,omitempty"` // Avatar URL
+	Bot        bool   `json:"bot,omitempty"`
+}
+
+// Response is a command response sent by a plugin
+type Response struct {
+	Content string                  `json:"content,omitempty"`
+	Embed   *discordgo.MessageEmbed `json:"embed,omitempty"`
+	Files   []File                  `json:"files,omitempty"`
+	Reply   bool                    `json:"reply,omitempty"`
+}
+
+// File is an attachment of a plugin response. Data is base64 encoded in JSON.
+type File struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type,omitempty"`
+	Data        []byte `json:"data"`
+}
+
+func newUser(u *discordgo.User) User {
+	return User{
+		ID:         u.ID,
+		Username:   u.Username,
+		GlobalName: u.GlobalName,
+		Avatar:     u.AvatarURL(""),
+		Bot:        u.Bot,
+	}
+}
@@ -0,0 +1,236 @@
+// Dank Memer - A Discord bot
+// Copyright (C) 2025 Dank Memer
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dankmemer/bot/internal/utils"
+)
+
+const (
+	describeTimeout = 10 * time.Second // How long a plugin may take to list its commands
+	stopTimeout     = 5 * time.Second  // How long a plugin may take to exit on shutdown
+	minBackoff      = time.Second      // Delay before the first restart
+	maxBackoff      = time.Minute      // Longest delay between restarts
+	stableAfter     = time.Minute      // Uptime after which the delay is reset
+)
+
+// ErrUnavailable is returned for invocations while a plugin is restarting
+var ErrUnavailable = errors.New("plugin is unavailable")
+
+// Supervisor runs a plugin, restarting it when it exits
+type Supervisor struct {
+	Name   string
+	cfg    utils.PluginConfig
+	logger zerolog.Logger
+
+	mu    sync.Mutex
+	proc  *process // nil while restarting
+	specs []CommandSpec
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSupervisor creates the supervisor of a plugin. It's started by Start.
+func NewSupervisor(cfg utils.PluginConfig, logger zerolog.Logger) *Supervisor {
+	name := cfg.Name
+	if name == "" {
+		name = cfg.Path
+	}
+	return &Supervisor{
+		Name:   name,
+		cfg:    cfg,
+		logger: logger.With().Str("plugin", name).Logger(),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start starts the plugin and asks it for its commands. It keeps the plugin
+// running until Stop.
+func (s *Supervisor) Start(ctx context.Context) error {
+	proc, specs, err := s.launch(ctx)
+	if err != nil {
+		close(s.done)
+		return err
+	}
+
+	s.mu.Lock()
+	s.proc = proc
+	s.specs = specs
+	s.mu.Unlock()
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	go s.supervise(ctx, proc)
+
+	s.logger.Info().Int("commands", len(specs)).Msg("Plugin started")
+	return nil
+}
+
+// Commands returns the commands the plugin declared when it started
+func (s *Supervisor) Commands() []CommandSpec {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.specs
+}
+
+// Invoke runs a command in the plugin
+func (s *Supervisor) Invoke(ctx context.Context, inv *Invocation) (*Response, error) {
+	s.mu.Lock()
+	proc := s.proc
+	s.mu.Unlock()
+	if proc == nil {
+		return nil, ErrUnavailable
+	}
+
+	reply, err := proc.call(ctx, Request{Type: RequestInvoke, Invocation: inv})
+	if errors.Is(err, errExited) {
+		return nil, ErrUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	return reply.Response, nil
+}
+
+// Stop stops the plugin and waits for it to exit
+func (s *Supervisor) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	<-s.done
+}
+
+// launch starts the plugin executable and asks it for its commands
+func (s *Supervisor) launch(ctx context.Context) (*process, []CommandSpec, error) {
+	proc, err := startProcess(s.cfg, s.logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	describeCtx, cancel := context.WithTimeout(ctx, describeTimeout)
+	defer cancel()
+
+	reply, err := proc.call(describeCtx, Request{Type: RequestDescribe, Version: ProtocolVersion})
+	if err == nil {
+		err = validateSpecs(reply.Commands)
+	}
+	if err != nil {
+		stopCtx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+		defer cancel()
+		proc.stop(stopCtx)
+		return nil, nil, fmt.Errorf("describe: %w", err)
+	}
+
+	return proc, reply.Commands, nil
+}
+
+// supervise restarts the plugin whenever it exits, backing off while it
+// keeps crashing
+func (s *Supervisor) supervise(ctx context.Context, proc *process) {
+	defer close(s.done)
+
+	backoff := minBackoff
+	started := time.Now()
+
+	for {
+		select {
+		case <-proc.exited:
+		case <-ctx.Done():
+			stopCtx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+			proc.stop(stopCtx)
+			cancel()
+			return
+		}
+
+		s.mu.Lock()
+		s.proc = nil
+		s.mu.Unlock()
+
+		if time.Since(started) > stableAfter {
+			backoff = minBackoff
+		}
+		s.logger.Warn().Err(proc.err).Dur("restart_in", backoff).Msg("Plugin exited")
+
+		for {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			backoff = min(backoff*2, maxBackoff)
+
+			next, specs, err := s.launch(ctx)
+			if err != nil {
+				s.logger.Error().Err(err).Dur("restart_in", backoff).Msg("Failed to restart plugin")
+				continue
+			}
+
+			if !sameTriggers(s.Commands(), specs) {
+				s.logger.Warn().Msg("Plugin changed its commands, restart the bot to pick them up")
+			}
+
+			s.mu.Lock()
+			s.proc = next
+			s.mu.Unlock()
+
+			proc = next
+			started = time.Now()
+			s.logger.Info().Msg("Plugin restarted")
+			break
+		}
+	}
+}
+
+// validateSpecs rejects command declarations the bot can't register
+func validateSpecs(specs []CommandSpec) error {
+	if len(specs) == 0 {
+		return errors.New("plugin declared no commands")
+	}
+	for _, spec := range specs {
+		if len(spec.Triggers) == 0 {
+			return errors.New("command has no triggers")
+		}
+		for _, arg := range spec.Args {
+			if _, ok := argTypes[arg.Type]; !ok {
+				return fmt.Errorf("argument %q of command %q has unknown type %q", arg.Name, spec.Triggers[0], arg.Type)
+			}
+		}
+	}
+	return nil
+}
+
+// sameTriggers reports whether two declarations have the same triggers
+func sameTriggers(a, b []CommandSpec) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !slices.Equal(a[i].Triggers, b[i].Triggers) {
+			return false
+		}
+	}
+	return true
+}
@@ -35,6 +35,11 @@ type Config struct {
 	Webhooks WebhooksConfig `mapstructure:"webhooks"`
 	Voice    VoiceConfig    `mapstructure:"voice"`
 	URLs     URLsConfig     `mapstructure:"urls"`
+	Plugins  []PluginConfig `mapstructure:"plugins"`
+
+	Analytics    AnalyticsConfig `mapstructure:"analytics"`
+	Executor     ExecutorConfig  `mapstructure:"executor"`
+	DonatorTiers []DonatorTier   `mapstructure:"donator_tiers"` // Perks of donator level 1, 2, ...
 }
 
 type DatabaseConfig struct {
@@ -52,9 +57,10 @@ type ShardingConfig struct {
 }
 
 type APIsConfig struct {
-	ImgenKey  string `mapstructure:"imgen_key"`
-	ImgenURL  string `mapstructure:"imgen_url"`
-	RedditURL string `mapstructure:"reddit_url"`
+	ImgenKey  string            `mapstructure:"imgen_key"`
+	ImgenURL  string            `mapstructure:"imgen_url"`
+	RedditURL string            `mapstructure:"reddit_url"`
+	Tokens    map[string]string `mapstructure:"tokens"` // Tokens for media APIs, by TokenKey
 }
 
 type WebhooksConfig struct {
@@ -77,6 +83,52 @@ type URLsConfig struct {
 	Patreon string `mapstructure:"patreon"`
 }
 
+type AnalyticsConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	Salt          string `mapstructure:"salt"`           // Secret mixed into user ID hashes
+	RetentionDays int    `mapstructure:"retention_days"` // How long invocations are kept
+}
+
+// ExecutorConfig bounds how many commands run and wait at once
+type ExecutorConfig struct {
+	Workers       int            `mapstructure:"workers"`         // Commands running at once
+	QueueSize     int            `mapstructure:"queue_size"`      // Commands waiting for a worker
+	PerGuild      int            `mapstructure:"per_guild"`       // Commands of one guild running at once
+	PerGuildQueue int            `mapstructure:"per_guild_queue"` // Commands of one guild waiting
+	Limits        map[string]int `mapstructure:"limits"`          // Overrides Concurrency by command or concurrency key
+}
+
+// DonatorTier are the perks of a donator level
+type DonatorTier struct {
+	Name               string  `mapstructure:"name"`
+	CooldownMultiplier float64 `mapstructure:"cooldown_multiplier"` // Applied to cooldowns, e.g. 0.5 halves them
+	DailyCoins         int64   `mapstructure:"daily_coins"`         // Paid by pls daily
+}
+
+// DefaultDonatorTiers are used when the config has none
+var DefaultDonatorTiers = []DonatorTier{
+	{Name: "Donator", CooldownMultiplier: 0.75, DailyCoins: 250},
+	{Name: "Super Donator", CooldownMultiplier: 0.5, DailyCoins: 500},
+	{Name: "Mega Donator", CooldownMultiplier: 0.25, DailyCoins: 1000},
+}
+
+// DonatorTier returns the perks of a donator level, or nil for level 0.
+// Levels above the last tier get its perks.
+func (c *Config) DonatorTier(level int) *DonatorTier {
+	if level <= 0 || len(c.DonatorTiers) == 0 {
+		return nil
+	}
+	return &c.DonatorTiers[min(level, len(c.DonatorTiers))-1]
+}
+
+// PluginConfig is an executable that provides commands, see internal/plugin
+type PluginConfig struct {
+	Name string   `mapstructure:"name"`
+	Path string   `mapstructure:"path"`
+	Args []string `mapstructure:"args"`
+	Env  []string `mapstructure:"env"` // Extra KEY=value environment variables
+}
+
 func LoadConfig(path string) (*Config, error) {
 	viper.SetConfigFile(path)
 	viper.SetConfigType("yaml")
@@ -104,6 +156,24 @@ func LoadConfig(path string) (*Config, error) {
 	if cfg.Sharding.ShardCount == 0 {
 		cfg.Sharding.ShardCount = 1
 	}
+	if cfg.Analytics.RetentionDays == 0 {
+		cfg.Analytics.RetentionDays = 90
+	}
+	if cfg.Executor.Workers == 0 {
+		cfg.Executor.Workers = 64
+	}
+	if cfg.Executor.QueueSize == 0 {
+		cfg.Executor.QueueSize = 1000
+	}
+	if cfg.Executor.PerGuild == 0 {
+		cfg.Executor.PerGuild = 8
+	}
+	if cfg.Executor.PerGuildQueue == 0 {
+		cfg.Executor.PerGuildQueue = 50
+	}
+	if len(cfg.DonatorTiers) == 0 {
+		cfg.DonatorTiers = DefaultDonatorTiers
+	}
 
 	return &cfg, nil
 }
@@ -20,70 +20,47 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/dankmemer/bot/internal/i18n"
 )
 
 // FormatDuration formats a duration in milliseconds to a human-readable string
 func FormatDuration(ms int64) string {
+	return FormatDurationIn(i18n.Default, ms)
+}
+
+// FormatDurationIn formats a duration in milliseconds to a human-readable
+// string in a locale, e.g. "1 day, 2 hours and 1 second"
+func FormatDurationIn(locale string, ms int64) string {
 	d := time.Duration(ms) * time.Millisecond
 
-	days := int(d.Hours() / 24)
-	hours := int(d.Hours()) % 24
-	minutes := int(d.Minutes()) % 60
-	seconds := int(d.Seconds()) % 60
+	days := int64(d.Hours() / 24)
+	hours := int64(d.Hours()) % 24
+	minutes := int64(d.Minutes()) % 60
+	seconds := int64(d.Seconds()) % 60
 
 	var parts []string
 
 	if days > 0 {
-		if days == 1 {
-			parts = append(parts, "1 day")
-		} else {
-			parts = append(parts, fmt.Sprintf("%d days", days))
-		}
+		parts = append(parts, i18n.Plural(locale, "duration.day", days))
 	}
-
 	if hours > 0 {
-		if hours == 1 {
-			parts = append(parts, "1 hour")
-		} else {
-			parts = append(parts, fmt.Sprintf("%d hours", hours))
-		}
+		parts = append(parts, i18n.Plural(locale, "duration.hour", hours))
 	}
-
 	if minutes > 0 {
-		if minutes == 1 {
-			parts = append(parts, "1 minute")
-		} else {
-			parts = append(parts, fmt.Sprintf("%d minutes", minutes))
-		}
+		parts = append(parts, i18n.Plural(locale, "duration.minute", minutes))
 	}
-
 	if seconds > 0 || len(parts) == 0 {
-		if seconds == 1 {
-			parts = append(parts, "1 second")
-		} else {
-			parts = append(parts, fmt.Sprintf("%d seconds", seconds))
-		}
+		parts = append(parts, i18n.Plural(locale, "duration.second", seconds))
 	}
 
 	if len(parts) == 1 {
 		return parts[0]
 	}
 
-	// Join all but last with ", " and last with " and "
-	var result strings.Builder
-	for i, part := range parts {
-		if i == 0 {
-			result.WriteString(part)
-		} else if i == len(parts)-1 {
-			result.WriteString(" and ")
-			result.WriteString(part)
-		} else {
-			result.WriteString(", ")
-			result.WriteString(part)
-		}
-	}
-
-	return result.String()
+	// Join all but last with the separator and the last with "and"
+	head := strings.Join(parts[:len(parts)-1], i18n.T(locale, "duration.separator"))
+	return i18n.T(locale, "duration.last", head, parts[len(parts)-1])
 }
 
 // FormatDurationShort formats a duration in milliseconds to a short string (e.g., "5m 30s")
@@ -0,0 +1,4 @@
+-- "Did you mean" suggestions for unknown commands, off until a guild turns
+-- them on so they don't clash with other bots sharing the prefix
+ALTER TABLE guilds
+    ADD COLUMN IF NOT EXISTS suggestions BOOLEAN NOT NULL DEFAULT FALSE COMMENT 'Suggest similar commands for unknown triggers';
@@ -0,0 +1,10 @@
+-- Per-guild custom text commands
+CREATE TABLE IF NOT EXISTS custom_commands (
+    guild_id VARCHAR(20) NOT NULL COMMENT 'Discord guild ID',
+    name VARCHAR(32) NOT NULL COMMENT 'Trigger, lowercase',
+    response TEXT NOT NULL COMMENT 'Response template',
+    author_id VARCHAR(20) NOT NULL COMMENT 'User who last changed the command',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (guild_id, name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,3 @@
+-- Per-guild command aliases
+ALTER TABLE guilds
+    ADD COLUMN IF NOT EXISTS aliases JSON DEFAULT '{}' COMMENT 'Object of alias to command';
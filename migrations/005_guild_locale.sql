@@ -0,0 +1,3 @@
+-- Per-guild language of bot responses
+ALTER TABLE guilds
+    ADD COLUMN IF NOT EXISTS locale VARCHAR(10) DEFAULT 'en' COMMENT 'Locale of bot responses';
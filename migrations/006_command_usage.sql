@@ -0,0 +1,13 @@
+-- Command usage analytics, written in batches by the bot
+CREATE TABLE IF NOT EXISTS command_usage (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    command VARCHAR(64) NOT NULL COMMENT 'Primary trigger',
+    guild_id VARCHAR(20) NOT NULL COMMENT 'Discord guild ID',
+    user_hash CHAR(32) NOT NULL COMMENT 'Salted hash of the user ID',
+    duration_ms INT UNSIGNED NOT NULL COMMENT 'Time the command took to run',
+    outcome VARCHAR(16) NOT NULL COMMENT 'ok, error, timeout or panic',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_created (created_at),
+    INDEX idx_guild_created (guild_id, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
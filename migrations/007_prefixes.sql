@@ -0,0 +1,10 @@
+-- Extra guild prefixes, and personal prefixes that work in every guild
+ALTER TABLE guilds
+    ADD COLUMN IF NOT EXISTS extra_prefixes JSON DEFAULT '[]' COMMENT 'Prefixes besides the main one';
+
+CREATE TABLE IF NOT EXISTS user_prefixes (
+    user_id VARCHAR(20) NOT NULL COMMENT 'Discord user ID',
+    prefix VARCHAR(32) NOT NULL COMMENT 'Lowercase prefix',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,14 @@
+-- Per-guild rules allowing or denying commands by channel, role or user
+CREATE TABLE IF NOT EXISTS command_rules (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    guild_id VARCHAR(20) NOT NULL COMMENT 'Discord guild ID',
+    command VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'Primary trigger, empty for category rules',
+    category VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'Normalized category, empty for command rules',
+    scope ENUM('channel', 'role', 'user') NOT NULL,
+    scope_id VARCHAR(20) NOT NULL COMMENT 'Channel, role or user ID',
+    action ENUM('allow', 'deny') NOT NULL,
+    author_id VARCHAR(20) NOT NULL COMMENT 'User who last changed the rule',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY idx_rule (guild_id, command, category, scope, scope_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,9 @@
+-- Disabled categories, so commands added to them later are disabled too
+ALTER TABLE guilds
+    ADD COLUMN IF NOT EXISTS disabled_categories JSON DEFAULT '[]' COMMENT 'Array of disabled categories, e.g. "voice"';
+
+-- "nsfw" used to be stored with the disabled commands
+UPDATE guilds
+SET disabled_categories = JSON_ARRAY('nsfw'),
+    disabled_commands = JSON_REMOVE(disabled_commands, JSON_UNQUOTE(JSON_SEARCH(disabled_commands, 'one', 'nsfw')))
+WHERE JSON_CONTAINS(disabled_commands, '"nsfw"');
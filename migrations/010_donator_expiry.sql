@@ -0,0 +1,4 @@
+-- Donator tiers that run out
+ALTER TABLE donators
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP NULL DEFAULT NULL COMMENT 'When the tier runs out, NULL for never',
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP;